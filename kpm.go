@@ -3,11 +3,15 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"runtime"
 
 	"github.com/urfave/cli/v2"
 	"kcl-lang.io/kpm/pkg/client"
 	"kcl-lang.io/kpm/pkg/cmd"
+	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/version"
 )
@@ -23,13 +27,20 @@ func main() {
 	app.Usage = "kpm is a kcl package manager"
 	app.Version = version.GetVersionInStr()
 	app.UsageText = "kpm  <command> [arguments]..."
+	app.Description = "The exit codes of kpm:\n" + kpmerrors.ExitCodesUsage()
 	app.Commands = []*cli.Command{
 		cmd.NewInitCmd(kpmcli),
 		cmd.NewGraphCmd(kpmcli),
+		cmd.NewWhyCmd(kpmcli),
 		cmd.NewAddCmd(kpmcli),
+		cmd.NewRemoveCmd(kpmcli),
 		cmd.NewPkgCmd(kpmcli),
 		cmd.NewMetadataCmd(kpmcli),
+		cmd.NewSchemaCmd(kpmcli),
+		cmd.NewExportSchemaCmd(kpmcli),
 		cmd.NewImportCmd(kpmcli),
+		cmd.NewVendorCmd(kpmcli),
+		cmd.NewCacheCmd(kpmcli),
 
 		// todo: The following commands are bound to the oci registry.
 		// Refactor them to compatible with the other registry.
@@ -39,21 +50,70 @@ func main() {
 		cmd.NewPushCmd(kpmcli),
 		cmd.NewPullCmd(kpmcli),
 		cmd.NewUpdateCmd(kpmcli),
+		cmd.NewOutdatedCmd(kpmcli),
 	}
 	app.Flags = []cli.Flag{
 		&cli.BoolFlag{
 			Name:  cmd.FLAG_QUIET,
 			Usage: "push in vendor mode",
 		},
+		&cli.StringFlag{
+			Name:  cmd.FLAG_LOG_FORMAT,
+			Value: cmd.LogFormatText,
+			Usage: "the format of the logs, 'text' or 'json' (one JSON object per event to stderr)",
+		},
+		&cli.IntFlag{
+			Name:  cmd.FLAG_JOBS,
+			Value: runtime.NumCPU(),
+			Usage: "the maximum number of dependencies downloaded concurrently",
+		},
+		&cli.BoolFlag{
+			Name:    cmd.FLAG_OFFLINE,
+			EnvVars: []string{env.KPM_OFFLINE},
+			Usage:   "resolve the dependencies from the cache only without accessing the network",
+		},
 	}
+	// The errors of parsing the flags exit with the code of the invalid arguments.
+	cmd.SetUsageErrorHandler(app)
 	app.Before = func(c *cli.Context) error {
+		// The events are the output of '--log-format json', which are all dropped by '--quiet'.
+		if c.Bool(cmd.FLAG_QUIET) && c.String(cmd.FLAG_LOG_FORMAT) == cmd.LogFormatJson {
+			return reporter.NewErrorEvent(
+				reporter.InvalidCmd,
+				fmt.Errorf("'--%s' and '--%s %s' cannot be used together", cmd.FLAG_QUIET, cmd.FLAG_LOG_FORMAT, cmd.LogFormatJson),
+			)
+		}
+		switch logFormat := c.String(cmd.FLAG_LOG_FORMAT); logFormat {
+		case cmd.LogFormatText:
+		case cmd.LogFormatJson:
+			kpmcli.SetLogWriter(reporter.NewJSONSink(os.Stderr))
+		default:
+			return reporter.NewErrorEvent(
+				reporter.InvalidCmd,
+				fmt.Errorf("invalid log format '%s', expected '%s' or '%s'", logFormat, cmd.LogFormatText, cmd.LogFormatJson),
+			)
+		}
 		if c.Bool(cmd.FLAG_QUIET) {
 			kpmcli.SetLogWriter(nil)
 		}
+		if jobs := c.Int(cmd.FLAG_JOBS); jobs < 1 {
+			return reporter.NewErrorEvent(
+				reporter.InvalidCmd,
+				fmt.Errorf("invalid '--%s' %d, expected a positive number", cmd.FLAG_JOBS, jobs),
+			)
+		}
+		kpmcli.SetJobs(c.Int(cmd.FLAG_JOBS))
+		kpmcli.SetOffline(c.Bool(cmd.FLAG_OFFLINE))
 		return nil
 	}
 	err = app.Run(os.Args)
 	if err != nil {
-		reporter.Fatal(err)
+		// The errors are reported as the events if the logs are in json.
+		if _, ok := kpmcli.GetLogWriter().(reporter.Sink); ok {
+			reporter.ReportErrorTo(err, kpmcli.GetLogWriter())
+		} else {
+			reporter.ReportErrorTo(err, os.Stderr)
+		}
+		os.Exit(kpmerrors.ExitCode(err))
 	}
 }
@@ -141,9 +141,20 @@ func (pkg *KclPackage) GetSchemaTypeMappingNamed(schemaName string) (map[string]
 }
 
 // GetFullSchemaTypeMappingWithFilters returns the full schema type filtered by the filter functions.
+// The dependencies of the package are resolved once for all the directories in the package.
 func (pkg *KclPackage) GetFullSchemaTypeMappingWithFilters(kpmcli *client.KpmClient, filterFuncs []KclTypeFilterFunc) (map[string]map[string]*KclType, error) {
+	depsMap, err := kpmcli.ResolveDepsIntoMap(pkg.pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := kcl.NewOption()
+	for depName, depPath := range depsMap {
+		opts.Merge(kcl.WithExternalPkgs(fmt.Sprintf(constants.EXTERNAL_PKGS_ARG_PATTERN, depName, depPath)))
+	}
+
 	schemaTypes := make(map[string]map[string]*KclType)
-	err := filepath.Walk(pkg.GetPkgHomePath(), func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(pkg.GetPkgHomePath(), func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -151,16 +162,6 @@ func (pkg *KclPackage) GetFullSchemaTypeMappingWithFilters(kpmcli *client.KpmCli
 		if info.IsDir() {
 			filteredTypeMap := make(map[string]*KclType)
 
-			depsMap, err := kpmcli.ResolveDepsIntoMap(pkg.pkg)
-			if err != nil {
-				return err
-			}
-
-			opts := kcl.NewOption()
-			for depName, depPath := range depsMap {
-				opts.Merge(kcl.WithExternalPkgs(fmt.Sprintf(constants.EXTERNAL_PKGS_ARG_PATTERN, depName, depPath)))
-			}
-
 			schemaTypeMap, err := kcl.GetFullSchemaTypeMapping([]string{path}, "", *opts)
 			if err != nil && err.Error() != errors.NoKclFiles.Error() {
 				return err
@@ -224,6 +224,15 @@ func run(kpmcli *client.KpmClient, opts *opt.CompileOptions) (*kcl.KCLResultList
 	}
 
 	if len(opts.Entries()) > 0 {
+		// The entries from '--input' replace the ones of the profile selected by '--profile',
+		// the other options of the profile are still applied.
+		if opts.Profile() != "" {
+			profileOpts, err := kclPkg.GetKclOptsByProfileWithoutEntries(opts.Profile())
+			if err != nil {
+				return nil, err
+			}
+			opts.Merge(*profileOpts)
+		}
 		// add entry from '--input'
 		for _, entry := range opts.Entries() {
 			if filepath.IsAbs(entry) {
@@ -233,8 +242,12 @@ func run(kpmcli *client.KpmClient, opts *opt.CompileOptions) (*kcl.KCLResultList
 			}
 		}
 		// add entry from 'kcl.mod'
-	} else if kclPkg.HasProfile() {
-		opts.Merge(*kclPkg.GetKclOpts())
+	} else if kclPkg.HasProfile() || opts.Profile() != "" {
+		profileOpts, err := kclPkg.GetKclOptsByProfile(opts.Profile())
+		if err != nil {
+			return nil, err
+		}
+		opts.Merge(*profileOpts)
 	} else if !opts.HasSettingsYaml() {
 		// no entry
 		opts.Merge(kcl.WithKFilenames(opts.PkgPath()))
@@ -0,0 +1,456 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+	"kcl-lang.io/kcl-go/pkg/tools/gen"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/downloader"
+)
+
+const (
+	// OPENAPI_V3_VERSION is the version of the exported OpenAPI documents.
+	OPENAPI_V3_VERSION = "3.1.0"
+	// JSON_SCHEMA_DIALECT is the dialect of the exported JSON Schemas, i.e. JSON Schema draft 2020-12.
+	JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+
+	// The formats of the exported schemas.
+	SchemaFormatOpenAPIV3  = "openapi3"
+	SchemaFormatJSONSchema = "jsonschema"
+
+	// OPENAPI_V3_FILE is the file of the exported OpenAPI document.
+	OPENAPI_V3_FILE = "openapi.json"
+	// JSON_SCHEMA_FILE_SUFFIX is the suffix of the files of the exported JSON Schemas, e.g. 'app.models.Service.schema.json'.
+	JSON_SCHEMA_FILE_SUFFIX = ".schema.json"
+
+	openAPIRefPrefix    = "#/components/schemas/"
+	jsonSchemaRefPrefix = "#/$defs/"
+	// The kcl package path of the schema types in the compiled package.
+	mainPkgPath = "__main__"
+)
+
+// JSONSchema is a JSON Schema of draft 2020-12, which is also the schema object of OpenAPI 3.1.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+	Const       json.RawMessage        `json:"const,omitempty"`
+	Enum        []json.RawMessage      `json:"enum,omitempty"`
+	Default     json.RawMessage        `json:"default,omitempty"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	// AdditionalProperties is a '*JSONSchema' or 'false' for the schema types without the index signature.
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// OpenAPIV3Spec is the OpenAPI 3.1 document of a kcl package, the schema types are in the components.
+type OpenAPIV3Spec struct {
+	OpenAPI           string                 `json:"openapi"`
+	Info              gen.SpecInfo           `json:"info"`
+	JSONSchemaDialect string                 `json:"jsonSchemaDialect"`
+	Paths             map[string]interface{} `json:"paths"`
+	Components        OpenAPIV3Components    `json:"components"`
+}
+
+// OpenAPIV3Components are the components of the OpenAPI 3.1 document.
+type OpenAPIV3Components struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// ExportOpenAPIV3Spec extracts the OpenAPI 3.1 representation of a kcl package
+// with external dependencies.
+func (pkg *KclPackage) ExportOpenAPIV3Spec() (*OpenAPIV3Spec, error) {
+	schemas, err := pkg.getPkgSchemas()
+	if err != nil {
+		return nil, err
+	}
+	return NewOpenAPIV3Spec(schemas), nil
+}
+
+// ExportJSONSchemas extracts the JSON Schema representation of a kcl package
+// with external dependencies, see 'NewJSONSchemas'.
+func (pkg *KclPackage) ExportJSONSchemas() (map[string]*JSONSchema, error) {
+	schemas, err := pkg.getPkgSchemas()
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONSchemas(schemas), nil
+}
+
+// getPkgSchemas returns the schema types of the package.
+func (pkg *KclPackage) getPkgSchemas() (*client.PkgSchemas, error) {
+	kpmcli, err := client.NewKpmClient()
+	if err != nil {
+		return nil, err
+	}
+	return kpmcli.Schema(client.WithSchemaSource(&downloader.Source{
+		Local: &downloader.Local{Path: pkg.GetPkgHomePath()},
+	}))
+}
+
+// NewOpenAPIV3Spec returns the OpenAPI 3.1 document of the schema types, e.g. returned by 'KpmClient.Schema'.
+// The schema types of the dependencies referenced by the package are also in the components,
+// and they are referenced by '$ref', e.g. '#/components/schemas/k8s.api.core.v1.Pod'.
+func NewOpenAPIV3Spec(schemas *client.PkgSchemas) *OpenAPIV3Spec {
+	exporter := newSchemaExporter(schemas, openAPIRefPrefix)
+	return &OpenAPIV3Spec{
+		OpenAPI: OPENAPI_V3_VERSION,
+		Info: gen.SpecInfo{
+			Title:   schemas.Name,
+			Version: schemas.Version,
+		},
+		JSONSchemaDialect: JSON_SCHEMA_DIALECT,
+		Paths:             map[string]interface{}{},
+		Components: OpenAPIV3Components{
+			Schemas: exporter.defs,
+		},
+	}
+}
+
+// NewJSONSchemas returns the JSON Schemas of the schema types, e.g. returned by 'KpmClient.Schema',
+// one document for each schema type of the package keyed by the id of the schema type, e.g. 'app.models.Service'.
+// The document refers to the schema type by '$ref' and the schema types referenced by it,
+// including the ones of the dependencies, are in '$defs'.
+func NewJSONSchemas(schemas *client.PkgSchemas) map[string]*JSONSchema {
+	exporter := newSchemaExporter(schemas, jsonSchemaRefPrefix)
+	docs := make(map[string]*JSONSchema, len(exporter.pkgIds))
+	for _, id := range exporter.pkgIds {
+		defs := map[string]*JSONSchema{}
+		exporter.collectDefs(id, defs)
+		docs[id] = &JSONSchema{
+			Schema: JSON_SCHEMA_DIALECT,
+			Ref:    jsonSchemaRefPrefix + id,
+			Title:  exporter.defs[id].Title,
+			Defs:   defs,
+		}
+	}
+	return docs
+}
+
+// NewSchemaFiles returns the files of the schema types exported in the format, the key is the file name:
+//
+//   - 'openapi3': the OpenAPI 3.1 document 'openapi.json'.
+//   - 'jsonschema': the JSON Schema '<id>.schema.json' of each schema type of the package, e.g. 'app.models.Service.schema.json'.
+func NewSchemaFiles(schemas *client.PkgSchemas, format string) (map[string][]byte, error) {
+	docs := map[string]interface{}{}
+	switch format {
+	case SchemaFormatOpenAPIV3:
+		docs[OPENAPI_V3_FILE] = NewOpenAPIV3Spec(schemas)
+	case SchemaFormatJSONSchema:
+		for id, doc := range NewJSONSchemas(schemas) {
+			docs[id+JSON_SCHEMA_FILE_SUFFIX] = doc
+		}
+	default:
+		return nil, fmt.Errorf(
+			"unsupported schema format '%s', supported formats are: %s",
+			format,
+			strings.Join([]string{SchemaFormatOpenAPIV3, SchemaFormatJSONSchema}, ", "),
+		)
+	}
+
+	files := make(map[string][]byte, len(docs))
+	for name, doc := range docs {
+		content, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[name] = append(content, '\n')
+	}
+	return files, nil
+}
+
+// schemaExporter converts the kcl types into the JSON Schemas.
+type schemaExporter struct {
+	module    string
+	refPrefix string
+	// localPkgs are the kcl package paths of the directories in the package, e.g. 'models' for the directory 'models'.
+	localPkgs map[string]bool
+	// pkgIds are the ids of the schema types of the package in order.
+	pkgIds []string
+	defs   map[string]*JSONSchema
+	// baseIds are the ids of the schema types referenced as the base schemas by 'allOf'.
+	baseIds map[string]bool
+}
+
+func newSchemaExporter(schemas *client.PkgSchemas, refPrefix string) *schemaExporter {
+	e := &schemaExporter{
+		module:    schemas.Name,
+		refPrefix: refPrefix,
+		localPkgs: map[string]bool{},
+		defs:      map[string]*JSONSchema{},
+		baseIds:   map[string]bool{},
+	}
+	for _, schema := range schemas.Schemas {
+		if schema.RelPath != "." {
+			e.localPkgs[strings.ReplaceAll(schema.RelPath, "/", ".")] = true
+		}
+	}
+
+	// The schema types of the package are converted first,
+	// the ones referenced only by them, e.g. the schema types of the dependencies, are converted on demand.
+	for _, schema := range schemas.Schemas {
+		id := e.schemaId(schema.KclType, e.dirPkgPath(schema.RelPath))
+		if _, ok := e.defs[id]; ok {
+			continue
+		}
+		e.pkgIds = append(e.pkgIds, id)
+		e.defs[id] = &JSONSchema{}
+	}
+	for _, schema := range schemas.Schemas {
+		dirPkg := e.dirPkgPath(schema.RelPath)
+		*e.defs[e.schemaId(schema.KclType, dirPkg)] = *e.schemaDef(schema.KclType, dirPkg)
+	}
+
+	// The base schemas are open, for 'additionalProperties: false' of a base schema refuses the attributes
+	// of the derived schemas by 'allOf', and the derived schemas are closed with the attributes of the base schemas.
+	for id := range e.baseIds {
+		if closed, ok := e.defs[id].AdditionalProperties.(bool); ok && !closed {
+			e.defs[id].AdditionalProperties = nil
+		}
+	}
+	return e
+}
+
+// dirPkgPath returns the package path of the directory in the package, e.g. 'app.models' for 'models' in the package 'app'.
+func (e *schemaExporter) dirPkgPath(relPath string) string {
+	if relPath == "" || relPath == "." {
+		return e.module
+	}
+	return e.module + "." + strings.ReplaceAll(relPath, "/", ".")
+}
+
+// schemaId returns the id of the schema type, i.e. its package path and its name, e.g. 'k8s.api.core.v1.Pod'.
+// The schema types of the package are identified by the package name, the ones of the dependencies by the kcl package paths.
+func (e *schemaExporter) schemaId(ty *gpyrpc.KclType, dirPkg string) string {
+	pkgPath := ty.PkgPath
+	switch {
+	case pkgPath == "" || pkgPath == mainPkgPath:
+		pkgPath = dirPkg
+	case e.localPkgs[pkgPath]:
+		pkgPath = e.module + "." + pkgPath
+	}
+	return pkgPath + "." + ty.SchemaName
+}
+
+// schemaDef converts the schema type into the JSON Schema of an object, the base schema is referenced by 'allOf'
+// and the attributes of the base schemas are also flattened into it.
+func (e *schemaExporter) schemaDef(ty *gpyrpc.KclType, dirPkg string) *JSONSchema {
+	def := &JSONSchema{
+		Type:        "object",
+		Title:       ty.SchemaName,
+		Description: strings.TrimSpace(ty.SchemaDoc),
+		Deprecated:  isDeprecated(ty.Decorators),
+		Properties:  map[string]*JSONSchema{},
+	}
+
+	// The attributes of the derived schemas override the ones of the base schemas.
+	var chain []*gpyrpc.KclType
+	for base := ty; base != nil; base = base.BaseSchema {
+		chain = append([]*gpyrpc.KclType{base}, chain...)
+	}
+	required := map[string]bool{}
+	for _, schema := range chain {
+		for name, attr := range schema.Properties {
+			// The private attributes are not in the outputs.
+			if strings.HasPrefix(name, "_") {
+				continue
+			}
+			def.Properties[name] = e.attrSchema(attr, dirPkg)
+		}
+		for _, name := range schema.Required {
+			if !strings.HasPrefix(name, "_") {
+				required[name] = true
+			}
+		}
+	}
+	for name := range required {
+		def.Required = append(def.Required, name)
+	}
+	sort.Strings(def.Required)
+
+	if ty.BaseSchema != nil {
+		if ref := e.typeSchema(ty.BaseSchema, dirPkg); ref.Ref != "" {
+			e.baseIds[strings.TrimPrefix(ref.Ref, e.refPrefix)] = true
+			def.AllOf = []*JSONSchema{ref}
+		}
+	}
+
+	// The schema types without the index signature have no other attributes.
+	if ty.Item != nil {
+		def.AdditionalProperties = e.typeSchema(ty.Item, dirPkg)
+	} else {
+		def.AdditionalProperties = false
+	}
+	return def
+}
+
+// attrSchema converts the type of the schema attribute with its description and default value.
+func (e *schemaExporter) attrSchema(ty *gpyrpc.KclType, dirPkg string) *JSONSchema {
+	s := e.typeSchema(ty, dirPkg)
+	s.Description = strings.TrimSpace(ty.Description)
+	s.Deprecated = isDeprecated(ty.Decorators)
+	if value, ok := kclLiteral(ty.Default); ok {
+		s.Default = value
+	}
+	return s
+}
+
+// typeSchema converts the kcl type into the JSON Schema, the schema types are referenced by '$ref'.
+func (e *schemaExporter) typeSchema(ty *gpyrpc.KclType, dirPkg string) *JSONSchema {
+	if ty == nil {
+		return &JSONSchema{}
+	}
+	switch ty.Type {
+	case "str":
+		return &JSONSchema{Type: "string"}
+	case "int":
+		return &JSONSchema{Type: "integer"}
+	case "float":
+		return &JSONSchema{Type: "number"}
+	case "bool":
+		return &JSONSchema{Type: "boolean"}
+	case "None", "NoneType":
+		return &JSONSchema{Type: "null"}
+	case "number_multiplier":
+		return &JSONSchema{Type: "string", Pattern: `^[0-9]+(\.[0-9]+)?(n|u|m|k|K|M|G|T|P)i?$`}
+	case "list":
+		s := &JSONSchema{Type: "array"}
+		if ty.Item != nil {
+			s.Items = e.typeSchema(ty.Item, dirPkg)
+		}
+		return s
+	case "dict":
+		s := &JSONSchema{Type: "object"}
+		if ty.Item != nil {
+			s.AdditionalProperties = e.typeSchema(ty.Item, dirPkg)
+		}
+		return s
+	case "union":
+		return e.unionSchema(ty.UnionTypes, dirPkg)
+	case "schema":
+		id := e.schemaId(ty, dirPkg)
+		if _, ok := e.defs[id]; !ok {
+			// The placeholder breaks the cycles of the schema types referencing each other.
+			e.defs[id] = &JSONSchema{}
+			*e.defs[id] = *e.schemaDef(ty, dirPkg)
+		}
+		return &JSONSchema{Ref: e.refPrefix + id}
+	}
+	if value, ok := kclLiteral(ty.Type); ok {
+		return &JSONSchema{Const: value}
+	}
+	// 'any' and the types without the JSON representations, e.g. the functions.
+	return &JSONSchema{}
+}
+
+// unionSchema converts the union type, the unions of the literal types are the enums.
+func (e *schemaExporter) unionSchema(types []*gpyrpc.KclType, dirPkg string) *JSONSchema {
+	var enum []json.RawMessage
+	var anyOf []*JSONSchema
+	for _, ty := range types {
+		s := e.typeSchema(ty, dirPkg)
+		if s.Const != nil {
+			enum = append(enum, s.Const)
+		}
+		anyOf = append(anyOf, s)
+	}
+	if len(enum) != 0 && len(enum) == len(types) {
+		return &JSONSchema{Enum: enum}
+	}
+	return &JSONSchema{AnyOf: anyOf}
+}
+
+// collectDefs collects the schema type of the id and the ones referenced by it.
+func (e *schemaExporter) collectDefs(id string, defs map[string]*JSONSchema) {
+	def, ok := e.defs[id]
+	if !ok {
+		return
+	}
+	if _, ok := defs[id]; ok {
+		return
+	}
+	defs[id] = def
+	var walk func(s *JSONSchema)
+	walk = func(s *JSONSchema) {
+		if s == nil {
+			return
+		}
+		if refId, ok := strings.CutPrefix(s.Ref, e.refPrefix); ok {
+			e.collectDefs(refId, defs)
+		}
+		for _, p := range s.Properties {
+			walk(p)
+		}
+		for _, a := range s.AnyOf {
+			walk(a)
+		}
+		for _, a := range s.AllOf {
+			walk(a)
+		}
+		walk(s.Items)
+		if additional, ok := s.AdditionalProperties.(*JSONSchema); ok {
+			walk(additional)
+		}
+	}
+	walk(def)
+}
+
+// isDeprecated returns true if the schema or the attribute is decorated by '@deprecated'.
+func isDeprecated(decorators []*gpyrpc.Decorator) bool {
+	for _, decorator := range decorators {
+		if decorator != nil && decorator.Name == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+// kclLiteral returns the JSON value of the kcl literal, e.g. '"foo"', '1', 'True' and 'None',
+// or the JSON compatible list and dict literals. It returns false for the other expressions.
+func kclLiteral(literal string) (json.RawMessage, bool) {
+	literal = strings.TrimSpace(literal)
+	switch literal {
+	case "":
+		return nil, false
+	case "True":
+		return json.RawMessage("true"), true
+	case "False":
+		return json.RawMessage("false"), true
+	case "None":
+		return json.RawMessage("null"), true
+	}
+	if len(literal) >= 2 && strings.HasPrefix(literal, "'") && strings.HasSuffix(literal, "'") {
+		value, err := json.Marshal(literal[1 : len(literal)-1])
+		if err != nil {
+			return nil, false
+		}
+		return value, true
+	}
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return json.RawMessage(strconv.FormatInt(i, 10)), true
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		value, err := json.Marshal(f)
+		if err != nil {
+			return nil, false
+		}
+		return value, true
+	}
+	if json.Valid([]byte(literal)) {
+		return json.RawMessage(literal), true
+	}
+	return nil, false
+}
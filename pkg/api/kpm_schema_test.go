@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+// testPkgSchemas returns the schema types of the package 'app' depending on the package 'base'.
+func testPkgSchemas() *client.PkgSchemas {
+	str := &gpyrpc.KclType{Type: "str"}
+	service := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Service",
+		PkgPath:    "__main__",
+		Properties: map[string]*gpyrpc.KclType{
+			"port": {Type: "int"},
+		},
+		Required: []string{"port"},
+	}
+	volume := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Volume",
+		PkgPath:    "base",
+		Properties: map[string]*gpyrpc.KclType{
+			"path": str,
+		},
+	}
+	base := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Base",
+		PkgPath:    "base",
+		Properties: map[string]*gpyrpc.KclType{
+			"name":   {Type: "str", Description: "the name of the application"},
+			"labels": {Type: "dict", Key: str, Item: str},
+		},
+		Required: []string{"name"},
+	}
+	app := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "App",
+		SchemaDoc:  "App is an application.",
+		PkgPath:    "__main__",
+		BaseSchema: base,
+		Properties: map[string]*gpyrpc.KclType{
+			"replicas": {Type: "int", Default: "1"},
+			"env": {Type: "union", UnionTypes: []*gpyrpc.KclType{
+				{Type: `"dev"`}, {Type: `"prod"`},
+			}},
+			"service": {
+				Type:       "schema",
+				SchemaName: "Service",
+				PkgPath:    "models",
+				Properties: service.Properties,
+			},
+			"volumes":  {Type: "list", Item: volume},
+			"image":    {Type: "union", UnionTypes: []*gpyrpc.KclType{str, {Type: "None"}}},
+			"old":      {Type: "str", Decorators: []*gpyrpc.Decorator{{Name: "deprecated"}}},
+			"_private": str,
+		},
+		Required: []string{"env", "_private"},
+	}
+	return &client.PkgSchemas{
+		Name:    "app",
+		Version: "0.0.1",
+		Schemas: []*client.SchemaType{
+			{Name: "App", RelPath: ".", KclType: app},
+			{Name: "Service", RelPath: "models", KclType: service},
+		},
+	}
+}
+
+func schemaKeys(m map[string]*JSONSchema) []string {
+	var res []string
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestNewOpenAPIV3Spec(t *testing.T) {
+	spec := NewOpenAPIV3Spec(testPkgSchemas())
+	assert.Equal(t, spec.OpenAPI, "3.1.0")
+	assert.Equal(t, spec.Info.Title, "app")
+	assert.Equal(t, spec.Info.Version, "0.0.1")
+	assert.Equal(t, spec.JSONSchemaDialect, JSON_SCHEMA_DIALECT)
+	// The schema types of the dependencies referenced are in the components, including the base schemas.
+	assert.DeepEqual(t, schemaKeys(spec.Components.Schemas), []string{"app.App", "app.models.Service", "base.Base", "base.Volume"})
+
+	app := spec.Components.Schemas["app.App"]
+	assert.Equal(t, app.Type, "object")
+	assert.Equal(t, app.Title, "App")
+	assert.Equal(t, app.Description, "App is an application.")
+	assert.Equal(t, app.AdditionalProperties, false)
+	// The base schema is referenced by 'allOf', and it is open for the attributes of the derived schema.
+	assert.Equal(t, len(app.AllOf), 1)
+	assert.Equal(t, app.AllOf[0].Ref, "#/components/schemas/base.Base")
+	base := spec.Components.Schemas["base.Base"]
+	assert.Equal(t, base.Title, "Base")
+	assert.Equal(t, base.AdditionalProperties, nil)
+	assert.DeepEqual(t, schemaKeys(base.Properties), []string{"labels", "name"})
+	assert.DeepEqual(t, base.Required, []string{"name"})
+	assert.Equal(t, spec.Components.Schemas["base.Volume"].AdditionalProperties, false)
+	// The attributes of the base schema are flattened, the private attributes are not exported.
+	assert.DeepEqual(t, schemaKeys(app.Properties), []string{"env", "image", "labels", "name", "old", "replicas", "service", "volumes"})
+	assert.DeepEqual(t, app.Required, []string{"env", "name"})
+	assert.Equal(t, app.Properties["name"].Description, "the name of the application")
+	assert.Equal(t, string(app.Properties["replicas"].Default), "1")
+	assert.Equal(t, app.Properties["service"].Ref, "#/components/schemas/app.models.Service")
+	assert.Equal(t, app.Properties["volumes"].Items.Ref, "#/components/schemas/base.Volume")
+	assert.Equal(t, app.Properties["labels"].AdditionalProperties.(*JSONSchema).Type, "string")
+	assert.Equal(t, app.Properties["old"].Deprecated, true)
+	assert.Equal(t, len(app.Properties["image"].AnyOf), 2)
+	assert.Equal(t, app.Properties["image"].AnyOf[1].Type, "null")
+
+	content, err := json.Marshal(spec)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), `"enum":["dev","prod"]`))
+	assert.Assert(t, strings.Contains(string(content), `"additionalProperties":false`))
+}
+
+func TestNewJSONSchemas(t *testing.T) {
+	docs := NewJSONSchemas(testPkgSchemas())
+	assert.Equal(t, len(docs), 2)
+
+	app := docs["app.App"]
+	assert.Equal(t, app.Schema, JSON_SCHEMA_DIALECT)
+	assert.Equal(t, app.Ref, "#/$defs/app.App")
+	assert.Equal(t, app.Title, "App")
+	// The schema types referenced, including the ones of the dependencies and the base schemas, are in '$defs'.
+	assert.DeepEqual(t, schemaKeys(app.Defs), []string{"app.App", "app.models.Service", "base.Base", "base.Volume"})
+	assert.Equal(t, app.Defs["app.App"].AllOf[0].Ref, "#/$defs/base.Base")
+	assert.Equal(t, app.Defs["app.App"].Properties["volumes"].Items.Ref, "#/$defs/base.Volume")
+
+	service := docs["app.models.Service"]
+	assert.Equal(t, service.Ref, "#/$defs/app.models.Service")
+	assert.DeepEqual(t, schemaKeys(service.Defs), []string{"app.models.Service"})
+	assert.DeepEqual(t, service.Defs["app.models.Service"].Required, []string{"port"})
+}
+
+func TestNewSchemaFiles(t *testing.T) {
+	files, err := NewSchemaFiles(testPkgSchemas(), SchemaFormatOpenAPIV3)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 1)
+	var spec map[string]interface{}
+	assert.NilError(t, json.Unmarshal(files[OPENAPI_V3_FILE], &spec))
+	assert.Equal(t, spec["openapi"], "3.1.0")
+
+	files, err = NewSchemaFiles(testPkgSchemas(), SchemaFormatJSONSchema)
+	assert.NilError(t, err)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"app.App.schema.json", "app.models.Service.schema.json"})
+
+	_, err = NewSchemaFiles(testPkgSchemas(), "swagger")
+	assert.ErrorContains(t, err, "unsupported schema format 'swagger'")
+}
+
+func TestExportSchemasOfPackage(t *testing.T) {
+	pkgPath := filepath.Join("..", "client", "test_data", "test_schema", "pkg")
+	pkg, err := GetKclPackage(pkgPath)
+	assert.NilError(t, err)
+
+	spec, err := pkg.ExportOpenAPIV3Spec()
+	assert.NilError(t, err)
+	assert.Equal(t, spec.Info.Title, "test_schema")
+	// The schema types in the hidden directories are not exported, the base schema of the dependency is.
+	assert.DeepEqual(t, schemaKeys(spec.Components.Schemas), []string{"base.Base", "test_schema.App", "test_schema.models.Service", "test_schema.models.Volume"})
+	app := spec.Components.Schemas["test_schema.App"]
+	assert.Equal(t, app.Description, "App is an application.")
+	assert.Equal(t, len(app.AllOf), 1)
+	assert.Equal(t, app.AllOf[0].Ref, "#/components/schemas/base.Base")
+	assert.DeepEqual(t, schemaKeys(app.Properties), []string{"name", "replicas"})
+	assert.Equal(t, app.AdditionalProperties, false)
+	base := spec.Components.Schemas["base.Base"]
+	assert.Equal(t, base.Description, "Base is the base of the applications.")
+	assert.DeepEqual(t, schemaKeys(base.Properties), []string{"name"})
+	assert.Equal(t, base.AdditionalProperties, nil)
+
+	docs, err := pkg.ExportJSONSchemas()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, schemaKeys(docs), []string{"test_schema.App", "test_schema.models.Service", "test_schema.models.Volume"})
+	assert.Equal(t, docs["test_schema.App"].Ref, "#/$defs/test_schema.App")
+	assert.DeepEqual(t, schemaKeys(docs["test_schema.App"].Defs), []string{"base.Base", "test_schema.App"})
+	assert.Equal(t, docs["test_schema.App"].Defs["test_schema.App"].AllOf[0].Ref, "#/$defs/base.Base")
+}
+
+func TestKclLiteral(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected string
+		ok       bool
+	}{
+		{`"foo"`, `"foo"`, true},
+		{`'foo'`, `"foo"`, true},
+		{"1", "1", true},
+		{"1.5", "1.5", true},
+		{"True", "true", true},
+		{"False", "false", true},
+		{"None", "null", true},
+		{`["a", "b"]`, `["a", "b"]`, true},
+		{"", "", false},
+		{"str", "", false},
+		{"option(\"env\")", "", false},
+	}
+	for _, tt := range tests {
+		value, ok := kclLiteral(tt.literal)
+		assert.Equal(t, ok, tt.ok, tt.literal)
+		assert.Equal(t, string(value), tt.expected, tt.literal)
+	}
+}
@@ -0,0 +1,295 @@
+// Package cache implements the content-addressed store of the kcl modules.
+//
+// The modules are stored by the checksums in kcl.mod.lock, i.e. the base64 encoded sha256 of the
+// module directory computed by 'utils.HashDir', in the layout:
+//
+//	<root>/sha256/<hex of the checksum>/<module files>
+//
+// The modules are verified before they are written into the store and when they are restored from it,
+// so a corrupted module is never used silently.
+package cache
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/otiai10/copy"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// STORE_DIR is the directory of the content-addressed store under the kpm home path.
+const STORE_DIR = ".cas"
+
+const algorithm = "sha256"
+
+// Store is the content-addressed store of the kcl modules.
+type Store struct {
+	root string
+}
+
+// NewStore returns the store in the root directory.
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Root returns the root directory of the store.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Entry is a module in the store.
+type Entry struct {
+	// Sum is the checksum of the module in kcl.mod.lock.
+	Sum string
+	// Path is the directory of the module in the store.
+	Path string
+	// Name and Version are loaded from the kcl.mod of the module, empty if they are not available.
+	Name    string
+	Version string
+	// Size is the size of the module in bytes.
+	Size int64
+	// LastUsed is the last time the module was stored or restored.
+	LastUsed time.Time
+}
+
+// Path returns the directory of the module with the checksum in the store.
+func (s *Store) Path(sum string) (string, error) {
+	digest, err := base64.StdEncoding.DecodeString(sum)
+	if err != nil || len(digest) != 32 {
+		return "", fmt.Errorf("invalid checksum '%s', expected the base64 encoded sha256", sum)
+	}
+	return filepath.Join(s.root, algorithm, hex.EncodeToString(digest)), nil
+}
+
+// Has reports whether the module with the checksum is in the store.
+func (s *Store) Has(sum string) bool {
+	path, err := s.Path(sum)
+	return err == nil && utils.DirExists(path)
+}
+
+// Put writes the module in 'srcDir' into the store by its checksum.
+// The module is verified against the checksum and written atomically,
+// it returns a 'ChecksumMismatch' error without writing the module if it does not match the checksum.
+func (s *Store) Put(sum, srcDir string) error {
+	path, err := s.Path(sum)
+	if err != nil {
+		return err
+	}
+	if utils.DirExists(path) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copy.Copy(srcDir, tmpDir); err != nil {
+		return err
+	}
+	if err := verify(sum, tmpDir); err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpDir, path)
+	// The module may be written concurrently by the others.
+	if err != nil && !utils.DirExists(path) {
+		return err
+	}
+	return nil
+}
+
+// Restore writes the module with the checksum into 'dstDir', and returns false if the module is not in the store.
+// It does nothing if 'dstDir' already holds the module, and replaces 'dstDir' if it holds a corrupted copy.
+// The module in the store is verified before it is restored, it is removed from the store
+// and a 'ChecksumMismatch' error is returned if it is corrupted.
+func (s *Store) Restore(sum, dstDir string) (bool, error) {
+	path, err := s.Path(sum)
+	if err != nil {
+		return false, err
+	}
+	if utils.DirExists(dstDir) && verify(sum, dstDir) == nil {
+		s.touch(path)
+		return true, nil
+	}
+	if !utils.DirExists(path) {
+		return false, nil
+	}
+	if err := verify(sum, path); err != nil {
+		if rmErr := os.RemoveAll(path); rmErr != nil {
+			return false, rmErr
+		}
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstDir), 0755); err != nil {
+		return false, err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dstDir), ".tmp-")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copy.Copy(path, tmpDir); err != nil {
+		return false, err
+	}
+	if err := os.RemoveAll(dstDir); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmpDir, dstDir); err != nil {
+		return false, err
+	}
+	s.touch(path)
+	return true, nil
+}
+
+// List returns the modules in the store sorted by the names and versions.
+func (s *Store) List() ([]Entry, error) {
+	dir := filepath.Join(s.root, algorithm)
+	dirEntries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, dirEntry := range dirEntries {
+		digest, err := hex.DecodeString(dirEntry.Name())
+		if !dirEntry.IsDir() || err != nil {
+			continue
+		}
+		entry, err := loadEntry(base64.StdEncoding.EncodeToString(digest), filepath.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		if entries[i].Version != entries[j].Version {
+			return entries[i].Version < entries[j].Version
+		}
+		return entries[i].Sum < entries[j].Sum
+	})
+	return entries, nil
+}
+
+// Verify rehashes the modules in the store and returns the corrupted ones.
+func (s *Store) Verify() ([]Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var corrupted []Entry
+	for _, entry := range entries {
+		if err := verify(entry.Sum, entry.Path); err != nil {
+			corrupted = append(corrupted, entry)
+		}
+	}
+	return corrupted, nil
+}
+
+// Clean removes the modules not used within 'olderThan' from the store, or all the modules if 'olderThan' is 0.
+// It returns the removed modules.
+func (s *Store) Clean(olderThan time.Duration) ([]Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var removed []Entry
+	for _, entry := range entries {
+		if olderThan > 0 && time.Since(entry.LastUsed) < olderThan {
+			continue
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// DiskUsage returns the size of the store in bytes and the number of the modules in it.
+func (s *Store) DiskUsage() (int64, int, error) {
+	entries, err := s.List()
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	return size, len(entries), nil
+}
+
+// touch updates the last used time of the module in the store.
+func (s *Store) touch(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// verify checks the module in the directory against the checksum.
+func verify(sum, dir string) error {
+	actual, err := utils.HashDir(dir)
+	if err != nil {
+		return err
+	}
+	if actual != sum {
+		return &kpmerrors.ChecksumMismatch{Name: dir, Expected: sum, Actual: actual}
+	}
+	return nil
+}
+
+// loadEntry loads the module in the store.
+func loadEntry(sum, path string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{
+		Sum:      sum,
+		Path:     path,
+		LastUsed: info.ModTime(),
+	}
+
+	var modFile struct {
+		Package struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(path, constants.KCL_MOD), &modFile); err == nil {
+		entry.Name = modFile.Package.Name
+		entry.Version = modFile.Package.Version
+	}
+
+	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			entry.Size += info.Size()
+		}
+		return nil
+	})
+	return entry, err
+}
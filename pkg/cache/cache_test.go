@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+func newTestModule(t *testing.T, name, version string) (string, string) {
+	dir := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	modFile := "[package]\nname = \"" + name + "\"\nversion = \"" + version + "\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kcl.mod"), []byte(modFile), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.k"), []byte("a = 1\n"), 0644))
+	sum, err := utils.HashDir(dir)
+	assert.NoError(t, err)
+	return dir, sum
+}
+
+func TestPutAndRestore(t *testing.T) {
+	store := NewStore(t.TempDir())
+	src, sum := newTestModule(t, "k8s", "1.28.0")
+
+	dst := filepath.Join(t.TempDir(), "k8s_1.28.0")
+	restored, err := store.Restore(sum, dst)
+	assert.NoError(t, err)
+	assert.False(t, restored)
+
+	assert.NoError(t, store.Put(sum, src))
+	assert.True(t, store.Has(sum))
+	// Putting the same module again does nothing.
+	assert.NoError(t, store.Put(sum, src))
+
+	restored, err = store.Restore(sum, dst)
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	restoredSum, err := utils.HashDir(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, sum, restoredSum)
+
+	// The corrupted module in the destination is replaced.
+	assert.NoError(t, os.WriteFile(filepath.Join(dst, "main.k"), []byte("a = 2\n"), 0644))
+	restored, err = store.Restore(sum, dst)
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	restoredSum, err = utils.HashDir(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, sum, restoredSum)
+}
+
+func TestPutChecksumMismatch(t *testing.T) {
+	store := NewStore(t.TempDir())
+	src, _ := newTestModule(t, "k8s", "1.28.0")
+	_, otherSum := newTestModule(t, "helloworld", "0.1.0")
+
+	err := store.Put(otherSum, src)
+	assert.True(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	assert.False(t, store.Has(otherSum))
+
+	_, err = store.Path("invalid")
+	assert.Error(t, err)
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	store := NewStore(t.TempDir())
+	src, sum := newTestModule(t, "k8s", "1.28.0")
+	assert.NoError(t, store.Put(sum, src))
+
+	path, err := store.Path(sum)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "main.k"), []byte("a = 2\n"), 0644))
+
+	corrupted, err := store.Verify()
+	assert.NoError(t, err)
+	assert.Len(t, corrupted, 1)
+	assert.Equal(t, sum, corrupted[0].Sum)
+
+	// The corrupted module is not restored and removed from the store.
+	restored, err := store.Restore(sum, filepath.Join(t.TempDir(), "k8s_1.28.0"))
+	assert.True(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	assert.False(t, restored)
+	assert.False(t, store.Has(sum))
+}
+
+func TestListCleanAndDiskUsage(t *testing.T) {
+	store := NewStore(t.TempDir())
+	k8s, k8sSum := newTestModule(t, "k8s", "1.28.0")
+	helloworld, helloworldSum := newTestModule(t, "helloworld", "0.1.0")
+	assert.NoError(t, store.Put(k8sSum, k8s))
+	assert.NoError(t, store.Put(helloworldSum, helloworld))
+
+	entries, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "helloworld", entries[0].Name)
+	assert.Equal(t, "0.1.0", entries[0].Version)
+	assert.Equal(t, helloworldSum, entries[0].Sum)
+	assert.Equal(t, "k8s", entries[1].Name)
+
+	size, count, err := store.DiskUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, entries[0].Size+entries[1].Size, size)
+
+	// Only the modules not used within the duration are removed.
+	k8sPath, err := store.Path(k8sSum)
+	assert.NoError(t, err)
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	assert.NoError(t, os.Chtimes(k8sPath, lastWeek, lastWeek))
+
+	removed, err := store.Clean(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.Equal(t, k8sSum, removed[0].Sum)
+	assert.False(t, store.Has(k8sSum))
+	assert.True(t, store.Has(helloworldSum))
+
+	removed, err = store.Clean(0)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 1)
+	entries, err = store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -1,9 +1,12 @@
 package checker
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -13,11 +16,14 @@ import (
 	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/oci"
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/signature"
+	"kcl-lang.io/kpm/pkg/sumdb"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -119,6 +125,9 @@ func (vc *VersionChecker) Check(kclPkg pkg.KclPkg) error {
 // SumChecker validates the dependencies sum in kclPkg.
 type SumChecker struct {
 	settings settings.Settings
+	sumDB    *sumdb.DB
+	// openSumDB opens the checksum database when the checksums are checked if 'sumDB' is not set.
+	openSumDB func() (*sumdb.DB, error)
 }
 
 // SumCheckerOption configures how we set up SumChecker.
@@ -140,12 +149,38 @@ func WithSettings(settings settings.Settings) SumCheckerOption {
 	}
 }
 
+// WithSumDB sets the checksum database for SumChecker,
+// the checksums recorded in it are trusted before the checksums in the OCI manifests.
+func WithSumDB(db *sumdb.DB) SumCheckerOption {
+	return func(s *SumChecker) {
+		s.sumDB = db
+	}
+}
+
+// WithSumDBOpener sets the function opening the checksum database for SumChecker when the checksums are checked,
+// so that the checksum database that fails to open is reported by the check rather than by creating SumChecker.
+func WithSumDBOpener(open func() (*sumdb.DB, error)) SumCheckerOption {
+	return func(s *SumChecker) {
+		s.openSumDB = open
+	}
+}
+
 // Check verifies the checksums of the dependencies in the KclPkg.
 func (sc *SumChecker) Check(kclPkg pkg.KclPkg) error {
 	if kclPkg.NoSumCheck {
 		return nil
 	}
 
+	if sc.sumDB == nil && sc.openSumDB != nil {
+		db, err := sc.openSumDB()
+		if err != nil {
+			return err
+		}
+		opened := *sc
+		opened.sumDB = db
+		sc = &opened
+	}
+
 	for _, key := range kclPkg.Dependencies.Deps.Keys() {
 		dep, _ := kclPkg.Dependencies.Deps.Get(key)
 
@@ -153,12 +188,19 @@ func (sc *SumChecker) Check(kclPkg pkg.KclPkg) error {
 			continue
 		}
 
-		trustedSum, err := sc.getTrustedSum(dep)
+		trustedSum, ok, err := sc.getTrustedSum(dep)
 		if err != nil {
 			return fmt.Errorf("failed to get checksum from trusted source: %w", err)
 		}
-		if dep.Sum != trustedSum {
-			return fmt.Errorf("checksum verification failed for '%s': expected '%s', got '%s'", dep.Name, trustedSum, dep.Sum)
+		if !ok {
+			continue
+		}
+		sum, _, err := sumdb.DepSum(dep)
+		if err != nil {
+			return err
+		}
+		if sum != trustedSum {
+			return &kpmerrors.ChecksumMismatch{Name: dep.Name, Expected: trustedSum, Actual: sum}
 		}
 	}
 	return nil
@@ -177,20 +219,44 @@ func isValidDependencyVersion(v string) bool {
 	return err == nil
 }
 
-// getTrustedSum retrieves the trusted checksum for the given dependency.
-func (sc *SumChecker) getTrustedSum(dep pkg.Dependency) (string, error) {
-	if dep.Source.Oci == nil {
-		return "", fmt.Errorf("dependency is not from OCI")
+// getTrustedSum retrieves the trusted checksum for the given dependency,
+// from the checksum database if it is recorded, or from the OCI manifest.
+// It returns false if the dependency not from OCI is not recorded in the checksum database,
+// which is trusted on the first use.
+func (sc *SumChecker) getTrustedSum(dep pkg.Dependency) (string, bool, error) {
+	if dep.Source.Oci != nil {
+		sc.populateOciFields(dep)
 	}
 
-	sc.populateOciFields(dep)
+	if sc.sumDB != nil {
+		if module, version, ok := sumdb.ModuleVersion(dep.Source); ok {
+			sum, ok, err := sc.sumDB.Lookup(module, version)
+			if err != nil {
+				return "", false, err
+			}
+			if ok {
+				return sum, true, nil
+			}
+		}
+	}
+
+	if dep.Source.Oci == nil {
+		if sc.sumDB != nil {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("dependency is not from OCI")
+	}
 
 	manifest, err := sc.fetchOciManifest(dep)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	return sc.extractChecksumFromManifest(manifest)
+	sum, err := sc.extractChecksumFromManifest(manifest)
+	if err != nil {
+		return "", false, err
+	}
+	return sum, true, nil
 }
 
 // populateOciFields fills in missing OCI fields with default values from settings.
@@ -273,3 +339,194 @@ func (sc *SumChecker) extractChecksumFromManifest(manifest ocispec.Manifest) (st
 	}
 	return "", fmt.Errorf("checksum annotation not found in manifest")
 }
+
+// SignatureChecker verifies the signatures of the OCI dependencies in kclPkg
+// by the public keys trusted for them in the settings.
+type SignatureChecker struct {
+	settings settings.Settings
+	// storePath is the directory recording the signatures verified.
+	storePath string
+	// offline verifies the signatures by the ones recorded in storePath instead of the registry.
+	offline bool
+}
+
+// SignatureCheckerOption configures how we set up SignatureChecker.
+type SignatureCheckerOption func(*SignatureChecker)
+
+// NewSignatureChecker creates a new SignatureChecker with options.
+func NewSignatureChecker(options ...SignatureCheckerOption) *SignatureChecker {
+	signatureChecker := &SignatureChecker{}
+	for _, opt := range options {
+		opt(signatureChecker)
+	}
+	return signatureChecker
+}
+
+// WithSignatureSettings sets the settings for SignatureChecker.
+func WithSignatureSettings(settings settings.Settings) SignatureCheckerOption {
+	return func(s *SignatureChecker) {
+		s.settings = settings
+	}
+}
+
+// WithSignatureStore sets the directory recording the signatures verified for SignatureChecker.
+func WithSignatureStore(storePath string) SignatureCheckerOption {
+	return func(s *SignatureChecker) {
+		s.storePath = storePath
+	}
+}
+
+// WithSignatureOffline sets whether SignatureChecker verifies the signatures offline,
+// by the ones recorded in the store instead of the registry.
+func WithSignatureOffline(offline bool) SignatureCheckerOption {
+	return func(s *SignatureChecker) {
+		s.offline = offline
+	}
+}
+
+// Check verifies the signatures of the dependencies in the kcl.mod.lock of the KclPkg,
+// the dependency replaced by the '[replace]' section is verified by its replacement.
+func (sc *SignatureChecker) Check(kclPkg pkg.KclPkg) error {
+	for _, key := range kclPkg.Dependencies.Deps.Keys() {
+		dep, _ := kclPkg.Dependencies.Deps.Get(key)
+
+		source := dep.Source
+		if dep.Replace != "" {
+			replace, err := downloader.NewSourceFromStr(dep.Replace)
+			if err != nil {
+				return err
+			}
+			source = *replace
+		}
+		if err := sc.CheckSource(&source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSource verifies the signature of the OCI package of the source by the first trust rule matching it,
+// the manifest is resolved by the digest of the source if any, otherwise by the tag.
+// The source not pinned to a digest is pinned to the digest of the manifest verified,
+// so that the package downloaded for it is refused if the tag is moved to another manifest after the check.
+// The signature verified is recorded in the store if any, in offline mode the source is verified
+// by the signatures recorded for its digest instead, and the source not pinned to a digest is refused.
+// The sources not from OCI or matching no trust rule are not verified.
+func (sc *SignatureChecker) CheckSource(source *downloader.Source) error {
+	var ociSource downloader.Oci
+	if source.Oci != nil {
+		ociSource = *source.Oci
+	} else if source.SpecOnly() {
+		ociSource = downloader.Oci{
+			Repo: utils.JoinPath(sc.settings.DefaultOciRepo(), source.ModSpec.Name),
+			Tag:  source.ModSpec.Version,
+		}
+	} else {
+		return nil
+	}
+	if len(ociSource.Reg) == 0 {
+		ociSource.Reg = sc.settings.DefaultOciRegistry()
+	}
+
+	ref := utils.JoinPath(ociSource.Reg, ociSource.Repo)
+	keyPaths, ok := sc.settings.TrustedKeys(ref)
+	if !ok {
+		return nil
+	}
+	keys := make([]ed25519.PublicKey, 0, len(keyPaths))
+	for _, keyPath := range keyPaths {
+		key, err := signature.LoadPublicKey(keyPath)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedLoadSettings, err, fmt.Sprintf("failed to load the trusted key for '%s'", ref))
+		}
+		keys = append(keys, key)
+	}
+
+	if sc.offline {
+		return sc.checkRecorded(ref, ociSource, keys)
+	}
+
+	// The signatures are fetched from the mirror of the OCI source if any.
+	repoPath := sc.settings.Mirror(ref)
+	reg, _, _ := strings.Cut(repoPath, "/")
+	cred, err := sc.GetCredentials(reg)
+	if err != nil {
+		return err
+	}
+	ociCli, err := oci.NewOciClientWithOpts(
+		oci.WithCredential(cred),
+		oci.WithRepoPath(repoPath),
+		oci.WithSettings(&sc.settings),
+	)
+	if err != nil {
+		return err
+	}
+
+	manifestRef := ociSource.Digest
+	if manifestRef == "" {
+		manifestRef = ociSource.Tag
+	}
+	payload, err := ociCli.VerifySignature(manifestRef, keys)
+	if errors.Is(err, kpmerrors.SignatureVerificationError) {
+		return reporter.NewErrorEvent(reporter.SignatureNotVerified, err, fmt.Sprintf("add the key signing '%s' to the trust rules in '%s' if it is trusted", ref, sc.settings.KpmConfFile))
+	}
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedFetchOciManifest, err, fmt.Sprintf("failed to fetch the signatures of '%s'", ref))
+	}
+	if sc.storePath != "" {
+		// The signature not recorded is fetched again from the registry by the next online check,
+		// so a failure to record it does not fail the check.
+		_ = signature.Record(sc.storePath, payload)
+	}
+
+	if ociSource.Digest == "" {
+		ociSource.Digest = payload.Subject
+		ociSource.DigestResolved = true
+		source.Oci = &ociSource
+	}
+	return nil
+}
+
+// checkRecorded verifies the OCI source pinned to a digest by the signatures recorded in the store.
+func (sc *SignatureChecker) checkRecorded(ref string, ociSource downloader.Oci, keys []ed25519.PublicKey) error {
+	if ociSource.Digest == "" {
+		return reporter.NewErrorEvent(
+			reporter.SignatureNotVerified,
+			&kpmerrors.SignatureNotVerified{Ref: ref + ":" + ociSource.Tag, Keys: keyIds(keys)},
+			fmt.Sprintf("the digest of '%s' is not locked, verify it online first", ref),
+		)
+	}
+	if sc.storePath == "" {
+		return reporter.NewErrorEvent(
+			reporter.SignatureNotVerified,
+			&kpmerrors.SignatureNotVerified{Ref: ref + "@" + ociSource.Digest, Keys: keyIds(keys)},
+			fmt.Sprintf("no signature of '%s' is recorded, verify it online first", ref),
+		)
+	}
+	_, err := signature.VerifyRecorded(sc.storePath, ref+"@"+ociSource.Digest, ociSource.Digest, keys)
+	if errors.Is(err, kpmerrors.SignatureVerificationError) {
+		return reporter.NewErrorEvent(reporter.SignatureNotVerified, err, fmt.Sprintf("no signature of '%s' recorded is verified, verify it online first", ref))
+	}
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.SignatureNotVerified, err, fmt.Sprintf("failed to load the signatures recorded for '%s'", ref))
+	}
+	return nil
+}
+
+// keyIds returns the ids of the keys.
+func keyIds(keys []ed25519.PublicKey) []string {
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, signature.KeyId(key))
+	}
+	return ids
+}
+
+// GetCredentials retrieves the OCI credentials for the given hostname.
+func (sc *SignatureChecker) GetCredentials(hostName string) (*remoteauth.Credential, error) {
+	credStore, err := downloader.LoadCredentialFile(sc.settings.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	return credStore.Credential(hostName)
+}
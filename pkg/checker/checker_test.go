@@ -1,17 +1,25 @@
 package checker
 
 import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"gotest.tools/v3/assert"
 
 	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/mock"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/signature"
+	"kcl-lang.io/kpm/pkg/sumdb"
 	"kcl-lang.io/kpm/pkg/test"
 )
 
@@ -273,3 +281,167 @@ func TestModCheckerCheck_WithTrustedSum(t *testing.T) {
 
 	test.RunTestWithGlobalLock(t, "TestModCheckerCheck_WithTrustedSum", testFunc)
 }
+
+func TestSignatureChecker_WithTrustRules(t *testing.T) {
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	deps.Set("helloworld", pkg.Dependency{
+		Name:    "helloworld",
+		Version: "0.1.0",
+		Source: downloader.Source{
+			Oci: &downloader.Oci{
+				Reg:  "localhost:5099",
+				Repo: "kcl-lang/helloworld",
+				Tag:  "0.1.0",
+			},
+		},
+	})
+	deps.Set("konfig", pkg.Dependency{
+		Name:    "konfig",
+		Version: "0.1.0",
+		Source: downloader.Source{
+			Git: &downloader.Git{
+				Url: "https://github.com/kcl-lang/konfig.git",
+				Tag: "v0.1.0",
+			},
+		},
+	})
+	kclPkg := pkg.KclPkg{
+		Dependencies: pkg.Dependencies{
+			Deps: deps,
+		},
+	}
+
+	// The dependencies matching no trust rules are not verified,
+	// and the unreachable registry is not requested.
+	signatureChecker := NewSignatureChecker(WithSignatureSettings(settings.Settings{
+		Conf: settings.KpmConf{
+			Trust: []settings.TrustRule{{Source: "ghcr.io/kcl-lang", Keys: []string{"kcl-lang.pub"}}},
+		},
+	}))
+	assert.NilError(t, signatureChecker.Check(kclPkg))
+
+	// The git dependencies are not verified.
+	konfig := deps.GetOrDefault("konfig", pkg.Dependency{}).Source
+	err := NewSignatureChecker(WithSignatureSettings(settings.Settings{
+		Conf: settings.KpmConf{
+			Trust: []settings.TrustRule{{Source: "https://github.com/*", Keys: []string{"kcl-lang.pub"}}},
+		},
+	})).CheckSource(&konfig)
+	assert.NilError(t, err)
+
+	// The trusted keys are loaded before the signatures are fetched.
+	signatureChecker = NewSignatureChecker(WithSignatureSettings(settings.Settings{
+		Conf: settings.KpmConf{
+			Trust: []settings.TrustRule{{Source: "localhost:*/kcl-lang/*", Keys: []string{filepath.Join(t.TempDir(), "not_exist.pub")}}},
+		},
+	}))
+	assert.ErrorContains(t, signatureChecker.Check(kclPkg), "failed to load the trusted key for 'localhost:5099/kcl-lang/helloworld'")
+}
+
+func TestSignatureChecker_Offline(t *testing.T) {
+	privatePem, publicPem, err := signature.GenerateKey()
+	assert.NilError(t, err)
+	keyDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(keyDir, "kpm.key"), privatePem, 0600))
+	assert.NilError(t, os.WriteFile(filepath.Join(keyDir, "kpm.pub"), publicPem, 0644))
+	privateKey, err := signature.LoadPrivateKey(filepath.Join(keyDir, "kpm.key"))
+	assert.NilError(t, err)
+	publicKey, err := signature.LoadPublicKey(filepath.Join(keyDir, "kpm.pub"))
+	assert.NilError(t, err)
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	storePath := t.TempDir()
+	// The unreachable registry is not requested in offline mode.
+	newChecker := func(storePath string) *SignatureChecker {
+		return NewSignatureChecker(
+			WithSignatureSettings(settings.Settings{
+				Conf: settings.KpmConf{
+					Trust: []settings.TrustRule{{Source: "localhost:*/kcl-lang/*", Keys: []string{filepath.Join(keyDir, "kpm.pub")}}},
+				},
+			}),
+			WithSignatureStore(storePath),
+			WithSignatureOffline(true),
+		)
+	}
+	newSource := func(digest string) *downloader.Source {
+		return &downloader.Source{
+			Oci: &downloader.Oci{
+				Reg:    "localhost:5099",
+				Repo:   "kcl-lang/helloworld",
+				Tag:    "0.1.0",
+				Digest: digest,
+			},
+		}
+	}
+
+	// The source is refused if no signature is recorded for it.
+	err = newChecker(storePath).CheckSource(newSource(digest))
+	assert.Assert(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+	err = newChecker("").CheckSource(newSource(digest))
+	assert.Assert(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	assert.NilError(t, signature.Record(storePath, signature.Payload{
+		Subject:   digest,
+		KeyId:     signature.KeyId(publicKey),
+		Signature: ed25519.Sign(privateKey, []byte(digest)),
+	}))
+	assert.NilError(t, newChecker(storePath).CheckSource(newSource(digest)))
+
+	// The source not pinned to a digest is refused, for the manifest of the tag is unknown offline.
+	err = newChecker(storePath).CheckSource(newSource(""))
+	assert.Assert(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+	assert.ErrorContains(t, err, "the digest of 'localhost:5099/kcl-lang/helloworld' is not locked")
+
+	// The source pinned to another digest is refused.
+	err = newChecker(storePath).CheckSource(newSource("sha256:" + strings.Repeat("b", 64)))
+	assert.Assert(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+	var event *reporter.KpmEvent
+	assert.Assert(t, errors.As(err, &event))
+	assert.Equal(t, event.Type(), reporter.SignatureNotVerified)
+}
+
+func TestSumChecker_WithSumDB(t *testing.T) {
+	newKclPkg := func(deps ...pkg.Dependency) pkg.KclPkg {
+		depsMap := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+		for _, dep := range deps {
+			depsMap.Set(dep.Name, dep)
+		}
+		return pkg.KclPkg{Dependencies: pkg.Dependencies{Deps: depsMap}}
+	}
+	konfig := pkg.Dependency{
+		Name:    "konfig",
+		Version: "0.4.0",
+		Sum:     "konfig-sum",
+		Source: downloader.Source{
+			Git: &downloader.Git{
+				Url:    "https://github.com/kcl-lang/konfig.git",
+				Commit: "9b5b8a4f",
+			},
+		},
+	}
+	helloworld := pkg.Dependency{
+		Name:    "helloworld",
+		Version: "0.1.0",
+		Source: downloader.Source{
+			Local: &downloader.Local{Path: "../helloworld"},
+		},
+	}
+
+	// Without the checksum database, the checksums of the git dependencies are not available.
+	err := NewSumChecker().Check(newKclPkg(konfig))
+	assert.ErrorContains(t, err, "dependency is not from OCI")
+
+	// The dependencies not recorded in the checksum database are trusted on the first use.
+	db := sumdb.New(sumdb.NewFileBackend(filepath.Join(t.TempDir(), "kcl.sum")))
+	sumChecker := NewSumChecker(WithSumDB(db))
+	assert.NilError(t, sumChecker.Check(newKclPkg(konfig, helloworld)))
+
+	assert.NilError(t, db.Verify("github.com/kcl-lang/konfig", "9b5b8a4f", "konfig-sum"))
+	assert.NilError(t, sumChecker.Check(newKclPkg(konfig, helloworld)))
+
+	// The checksum in kcl.mod.lock is verified against the record.
+	konfig.Sum = "changed-sum"
+	err = sumChecker.Check(newKclPkg(konfig))
+	assert.Assert(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	assert.ErrorContains(t, err, "expected 'konfig-sum', got 'changed-sum'")
+}
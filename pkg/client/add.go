@@ -115,6 +115,7 @@ func (c *KpmClient) Add(options ...AddOption) error {
 				EnableCache:           true,
 				CachePath:             c.homePath,
 				VisitedSpace:          c.homePath,
+				Offline:               c.offline,
 			}, nil
 		} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
 			return visitor.NewArchiveVisitor(pkgVisitor), nil
@@ -42,7 +42,7 @@ func (c *KpmClient) Check(options ...CheckOption) error {
 			checker.WithCheckers(
 				checker.NewIdentChecker(),
 				checker.NewVersionChecker(),
-				checker.NewSumChecker(),
+				checker.NewSumChecker(checker.WithSumDBOpener(c.sumDB)),
 			),
 		)
 	}
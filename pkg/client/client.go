@@ -1,12 +1,14 @@
 package client
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
 	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
 
+	"kcl-lang.io/kpm/pkg/cache"
 	"kcl-lang.io/kpm/pkg/checker"
 	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
@@ -36,6 +38,11 @@ type KpmClient struct {
 	noSumCheck bool
 	// The flag of whether to skip the verification of TLS.
 	insecureSkipTLSverify bool
+	// The maximum number of the dependencies downloaded concurrently,
+	// the dependencies are downloaded one by one if it is less than 2.
+	jobs int
+	// The flag of whether to resolve the packages from the cache only without accessing the network.
+	offline bool
 }
 
 // NewKpmClient will create a new kpm client with default settings.
@@ -51,18 +58,20 @@ func NewKpmClient() (*KpmClient, error) {
 		return nil, err
 	}
 
-	ModChecker := checker.NewModChecker(
-		checker.WithCheckers(checker.NewIdentChecker(), checker.NewVersionChecker(), checker.NewSumChecker(
-			checker.WithSettings(*settings))),
-	)
-
-	return &KpmClient{
+	client := &KpmClient{
 		logWriter:     os.Stdout,
 		settings:      *settings,
 		homePath:      homePath,
-		ModChecker:    ModChecker,
 		DepDownloader: &downloader.DepDownloader{},
-	}, nil
+		offline:       env.OfflineEnabled(),
+	}
+
+	client.ModChecker = checker.NewModChecker(
+		checker.WithCheckers(checker.NewIdentChecker(), checker.NewVersionChecker(), checker.NewSumChecker(
+			checker.WithSettings(*settings), checker.WithSumDBOpener(client.sumDB))),
+	)
+
+	return client, nil
 }
 
 // SetInsecureSkipTLSverify will set the flag of whether to skip the verification of TLS.
@@ -75,6 +84,43 @@ func (c *KpmClient) SetNoSumCheck(noSumCheck bool) {
 	c.noSumCheck = noSumCheck
 }
 
+// SetJobs will set the maximum number of the dependencies downloaded concurrently.
+func (c *KpmClient) SetJobs(jobs int) {
+	c.jobs = jobs
+}
+
+// GetJobs will return the maximum number of the dependencies downloaded concurrently.
+func (c *KpmClient) GetJobs() int {
+	return c.jobs
+}
+
+// SetOffline will set the flag of whether to resolve the packages from the cache only without accessing the network.
+func (c *KpmClient) SetOffline(offline bool) {
+	c.offline = offline
+}
+
+// GetOffline will return the flag of whether to resolve the packages from the cache only without accessing the network.
+func (c *KpmClient) GetOffline() bool {
+	return c.offline
+}
+
+// checkOnline will return an error if the operation requiring the network is performed in offline mode.
+func (c *KpmClient) checkOnline(operation string) error {
+	if c.offline {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("offline mode is enabled by '--offline' or '%s'", env.KPM_OFFLINE),
+			fmt.Sprintf("%s requires the network", operation),
+		)
+	}
+	return nil
+}
+
+// Cache will return the content-addressed store of the kcl modules under the home path of kpm.
+func (c *KpmClient) Cache() *cache.Store {
+	return cache.NewStore(filepath.Join(c.homePath, cache.STORE_DIR))
+}
+
 // GetCredsClient will return the credential store.
 func (c *KpmClient) GetCredsClient() (*downloader.CredStore, error) {
 	reloadCreds, _ := c.settings.ForceReloadCredsPerUse()
@@ -194,10 +240,15 @@ func newVisitor(source downloader.Source, kpmcli *KpmClient) visitor.Visitor {
 	}
 
 	if source.IsRemote() {
+		// In offline mode, the package is loaded from the cache.
 		return &visitor.RemoteVisitor{
 			PkgVisitor:            PkgVisitor,
 			Downloader:            kpmcli.DepDownloader,
 			InsecureSkipTLSverify: kpmcli.insecureSkipTLSverify,
+			EnableCache:           kpmcli.offline,
+			CachePath:             kpmcli.homePath,
+			Offline:               kpmcli.offline,
+			Store:                 kpmcli.Cache(),
 		}
 	} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
 		return visitor.NewArchiveVisitor(PkgVisitor)
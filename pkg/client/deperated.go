@@ -82,6 +82,7 @@ func (c *KpmClient) CompileWithOpts(opts *opt.CompileOptions) (*kcl.KCLResultLis
 		WithRunOptions(&runOpts),
 		WithRunSourceUrls(append([]string{pathSourceUrl}, opts.Entries()...)),
 		WithVendor(opts.IsVendor()),
+		WithRunProfile(opts.Profile()),
 	)
 }
 
@@ -117,6 +118,7 @@ func (c *KpmClient) CompilePkgWithOpts(kclPkg *pkg.KclPkg, opts *opt.CompileOpti
 		WithRunOptions(&runOpts),
 		WithRunSourceUrls(append([]string{pathSourceUrl}, opts.Entries()...)),
 		WithVendor(opts.IsVendor()),
+		WithRunProfile(opts.Profile()),
 	)
 }
 
@@ -142,6 +144,7 @@ func (c *KpmClient) CompileTarPkg(tarPath string, opts *opt.CompileOptions) (*kc
 		WithRunOptions(&runOpts),
 		WithRunSourceUrls(append([]string{pathSourceUrl}, opts.Entries()...)),
 		WithVendor(opts.IsVendor()),
+		WithRunProfile(opts.Profile()),
 	)
 }
 
@@ -179,6 +182,7 @@ func (c *KpmClient) CompileGitPkg(gitOpts *git.CloneOptions, compileOpts *opt.Co
 		WithRunOptions(&runOpts),
 		WithRunSourceUrls(append([]string{url.String()}, compileOpts.Entries()...)),
 		WithVendor(compileOpts.IsVendor()),
+		WithRunProfile(compileOpts.Profile()),
 	)
 }
 
@@ -212,12 +216,17 @@ func (c *KpmClient) CompileOciPkg(ociSource, version string, opts *opt.CompileOp
 		WithRunOptions(&runOpts),
 		WithRunSourceUrls(append([]string{ociSourceUrl}, opts.Entries()...)),
 		WithVendor(opts.IsVendor()),
+		WithRunProfile(opts.Profile()),
 	)
 }
 
 // DownloadFromOci will download the dependency from the oci repository.
 // Deprecated: Use the DownloadPkgFromOci instead.
 func (c *KpmClient) DownloadFromOci(dep *downloader.Oci, localPath string) (string, error) {
+	if c.offline {
+		return "", downloader.NewNotFoundOfflineError(downloader.Source{Oci: dep})
+	}
+
 	ociClient, err := oci.NewOciClient(dep.Reg, dep.Repo, &c.settings)
 	if err != nil {
 		return "", err
@@ -427,7 +436,17 @@ func GetReleasesFromSource(sourceType, uri string) ([]string, error) {
 // UpdateDeps will update the dependencies.
 // Deprecated: Use `Update` instead.
 func (c *KpmClient) UpdateDeps(kclPkg *pkg.KclPkg) error {
-	_, err := c.ResolveDepsMetadataInJsonStr(kclPkg, true)
+	// The members of a workspace only update kcl.mod and the lock file of the workspace.
+	ws, err := c.FindWorkspace(kclPkg.HomePath)
+	if err != nil {
+		return err
+	}
+	if ws != nil {
+		_, err = c.Update(WithUpdatedKclPkg(kclPkg))
+		return err
+	}
+
+	_, err = c.ResolveDepsMetadataInJsonStr(kclPkg, true)
 	if err != nil {
 		return err
 	}
@@ -435,7 +454,7 @@ func (c *KpmClient) UpdateDeps(kclPkg *pkg.KclPkg) error {
 	if ok, err := features.Enabled(features.SupportMVS); err == nil && ok {
 		_, err = c.Update(
 			WithUpdatedKclPkg(kclPkg),
-			WithOffline(false),
+			WithOffline(c.offline),
 		)
 		if err != nil {
 			return err
@@ -671,6 +690,7 @@ func (c *KpmClient) downloadPkg(options ...downloader.Option) (*pkg.KclPkg, erro
 		downloader.WithSettings(*c.GetSettings()),
 		downloader.WithCredsStore(credStore),
 		downloader.WithInsecureSkipTLSverify(opts.InsecureSkipTLSverify),
+		downloader.WithOffline(c.offline),
 	))
 
 	if err != nil {
@@ -719,6 +739,7 @@ func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*
 			downloader.WithSource(dep.Source),
 			downloader.WithLogWriter(c.logWriter),
 			downloader.WithSettings(c.settings),
+			downloader.WithOffline(c.offline),
 		))
 		if err != nil {
 			return nil, err
@@ -793,6 +814,7 @@ func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*
 			downloader.WithSettings(c.settings),
 			downloader.WithCredsStore(credStore),
 			downloader.WithInsecureSkipTLSverify(c.insecureSkipTLSverify),
+			downloader.WithOffline(c.offline),
 		))
 		if err != nil {
 			return nil, err
@@ -848,6 +870,10 @@ func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*
 // DownloadFromGit will download the dependency from the git repository.
 // Deprecated: use 'DownloadFromGit' instead.
 func (c *KpmClient) DownloadFromGit(dep *downloader.Git, localPath string) (string, error) {
+	if c.offline {
+		return "", downloader.NewNotFoundOfflineError(downloader.Source{Git: dep})
+	}
+
 	var msg string
 	if len(dep.Tag) != 0 {
 		msg = fmt.Sprintf("with tag '%s'", dep.Tag)
@@ -932,6 +958,10 @@ func (c *KpmClient) ParseKclModFile(kclPkg *pkg.KclPkg) (map[string]map[string]s
 // LoadPkgFromOci will download the kcl package from the oci repository and return an `KclPkg`.
 // Deprecated: this function is deprecated and will be removed in a future release.
 func (c *KpmClient) DownloadPkgFromOci(dep *downloader.Oci, localPath string) (*pkg.KclPkg, error) {
+	if c.offline {
+		return nil, downloader.NewNotFoundOfflineError(downloader.Source{Oci: dep})
+	}
+
 	repoPath := utils.JoinPath(dep.Reg, dep.Repo)
 	cred, err := c.GetCredentials(dep.Reg)
 	if err != nil {
@@ -1019,6 +1049,11 @@ func (c *KpmClient) PullFromOci(localPath, source, tag string) error {
 		return err
 	}
 
+	// In offline mode, the package is pulled from the cache.
+	if c.offline {
+		return c.pullFromCache(localPath, source, ociOpts)
+	}
+
 	tmpDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return reporter.NewErrorEvent(reporter.Bug, err, fmt.Sprintf("failed to create temp dir '%s'.", tmpDir))
@@ -1067,6 +1102,35 @@ func (c *KpmClient) PullFromOci(localPath, source, tag string) error {
 	return nil
 }
 
+// pullFromCache will pull a kcl package of the oci reference from the cache in offline mode.
+// Deprecated: use `Pull` instead.
+func (c *KpmClient) pullFromCache(localPath, source string, ociOpts *opt.OciOptions) error {
+	pkgSource := downloader.Source{
+		Oci: &downloader.Oci{
+			Reg:  ociOpts.Reg,
+			Repo: utils.JoinPath(ociOpts.Repo, ociOpts.Ref),
+			Tag:  ociOpts.Tag,
+		},
+	}
+
+	storagePath := ociOpts.SanitizePathWithSuffix(localPath)
+	err := newVisitor(pkgSource, c).Visit(&pkgSource, func(kPkg *pkg.KclPkg) error {
+		if err := os.MkdirAll(filepath.Dir(storagePath), os.ModePerm); err != nil {
+			return err
+		}
+		return utils.MoveOrCopy(kPkg.HomePath, storagePath)
+	})
+	if err != nil {
+		return err
+	}
+
+	reporter.ReportMsgTo(
+		fmt.Sprintf("pulled '%s' in '%s' from the cache successfully", source, storagePath),
+		c.logWriter,
+	)
+	return nil
+}
+
 // Deprecated: This function is deprecated and will be removed in a future release.
 func (c *KpmClient) ValidatePkgPullFromOci(ociOpts *opt.OciOptions, storagePath string) error {
 	kclPkg, err := c.LoadPkgFromPath(storagePath)
@@ -1208,7 +1272,7 @@ func (c *KpmClient) DownloadDeps(deps *pkg.Dependencies, lockDeps *pkg.Dependenc
 				lockedDep.Sum != expectedSum {
 				return nil, reporter.NewErrorEvent(
 					reporter.CheckSumMismatch,
-					errors.CheckSumMismatchError,
+					&errors.ChecksumMismatch{Name: lockedDep.Name, Expected: expectedSum, Actual: lockedDep.Sum},
 					fmt.Sprintf("checksum for '%s' changed in lock file '%s' and '%s'", lockedDep.Name, expectedSum, lockedDep.Sum),
 				)
 			} else {
@@ -1392,9 +1456,14 @@ func (c *KpmClient) pullTarFromOci(localPath string, ociOpts *opt.OciOptions) er
 // FetchOciManifestConfIntoJsonStr will fetch the oci manifest config of the kcl package from the oci registry and return it into json string.
 // Deprecated: use `SumChecker.FetchOciManifestIntoJsonStr` instead.
 func (c *KpmClient) FetchOciManifestIntoJsonStr(opts opt.OciFetchOptions) (string, error) {
+	if err := c.checkOnline("fetching the manifest"); err != nil {
+		return "", err
+	}
 
-	repoPath := utils.JoinPath(opts.Reg, opts.Repo)
-	cred, err := c.GetCredentials(opts.Reg)
+	// The manifest is fetched from the mirror of the OCI source if any.
+	repoPath := c.settings.Mirror(utils.JoinPath(opts.Reg, opts.Repo))
+	reg, _, _ := strings.Cut(repoPath, "/")
+	cred, err := c.GetCredentials(reg)
 	if err != nil {
 		return "", err
 	}
@@ -1419,6 +1488,10 @@ func (c *KpmClient) FetchOciManifestIntoJsonStr(opts opt.OciFetchOptions) (strin
 // AcquireTheLatestOciVersion will acquire the latest version of the OCI reference.
 // Deprecated: use the 'downloader.LatestVersion' instead.
 func (c *KpmClient) AcquireTheLatestOciVersion(ociSource downloader.Oci) (string, error) {
+	if c.offline {
+		return "", downloader.NewNotFoundOfflineError(downloader.Source{Oci: &ociSource})
+	}
+
 	repoPath := utils.JoinPath(ociSource.Reg, ociSource.Repo)
 	cred, err := c.GetCredentials(ociSource.Reg)
 	if err != nil {
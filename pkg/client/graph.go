@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/dominikbraun/graph"
 	"golang.org/x/mod/module"
@@ -26,7 +27,8 @@ func WithGraphMod(kMod *pkg.KclPkg) GraphOption {
 
 // DepGraph is the dependency graph.
 type DepGraph struct {
-	gra graph.Graph[module.Version, module.Version]
+	gra  graph.Graph[module.Version, module.Version]
+	deps map[module.Version]pkg.Dependency
 }
 
 // NewDepGraph creates a new dependency graph.
@@ -37,6 +39,7 @@ func NewDepGraph() *DepGraph {
 			graph.Directed(),
 			graph.PreventCycles(),
 		),
+		deps: make(map[module.Version]pkg.Dependency),
 	}
 }
 
@@ -50,10 +53,30 @@ func (g *DepGraph) AddVertex(name, version string) (*module.Version, error) {
 	return &root, nil
 }
 
+// AddDepVertex adds a vertex for the dependency to the dependency graph.
+// The dependency is recorded and can be got by 'Dependency' with the vertex.
+func (g *DepGraph) AddDepVertex(dep *pkg.Dependency) (*module.Version, error) {
+	vertex, err := g.AddVertex(dep.Name, dep.Version)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := g.deps[*vertex]; !ok {
+		g.deps[*vertex] = *dep
+	}
+	return vertex, nil
+}
+
+// Dependency returns the dependency of the vertex added by 'AddDepVertex'.
+func (g *DepGraph) Dependency(m module.Version) (pkg.Dependency, bool) {
+	dep, ok := g.deps[m]
+	return dep, ok
+}
+
 // AddEdge adds an edge to the dependency graph.
+// Adding an existing edge is a no-op, for a module may be required through several paths.
 func (g *DepGraph) AddEdge(parent, child module.Version) error {
 	err := g.gra.AddEdge(parent, child)
-	if err != nil {
+	if err != nil && err != graph.ErrEdgeAlreadyExists {
 		return err
 	}
 	return nil
@@ -81,6 +104,78 @@ func (g *DepGraph) DisplayGraphFromVertex(startVertex module.Version) (string, e
 	return res, nil
 }
 
+// ShortestPaths returns every shortest path from the start vertex to the vertices named 'name'.
+// Each path starts with the start vertex and ends with the target vertex.
+// If there are several versions of the target in the graph, the paths to each of them are returned.
+func (g *DepGraph) ShortestPaths(startVertex module.Version, name string) ([][]module.Version, error) {
+	adjMap, err := g.gra.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := adjMap[startVertex]; !ok {
+		return nil, graph.ErrVertexNotFound
+	}
+
+	// Traverse the graph by BFS and record all the predecessors on the shortest paths.
+	dist := map[module.Version]int{startVertex: 0}
+	preds := make(map[module.Version][]module.Version)
+	queue := []module.Version{startVertex}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range sortedVertices(adjMap[current]) {
+			if d, ok := dist[next]; !ok {
+				dist[next] = dist[current] + 1
+				preds[next] = append(preds[next], current)
+				queue = append(queue, next)
+			} else if d == dist[current]+1 {
+				preds[next] = append(preds[next], current)
+			}
+		}
+	}
+
+	var targets []module.Version
+	for vertex := range dist {
+		if vertex.Path == name && vertex != startVertex {
+			targets = append(targets, vertex)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return format(targets[i]) < format(targets[j])
+	})
+
+	// Walk back from the targets to the start vertex to collect the paths.
+	var paths [][]module.Version
+	var walk func(vertex module.Version, suffix []module.Version)
+	walk = func(vertex module.Version, suffix []module.Version) {
+		path := append([]module.Version{vertex}, suffix...)
+		if vertex == startVertex {
+			paths = append(paths, path)
+			return
+		}
+		for _, pred := range preds[vertex] {
+			walk(pred, path)
+		}
+	}
+	for _, target := range targets {
+		walk(target, nil)
+	}
+
+	return paths, nil
+}
+
+// sortedVertices returns the vertices in the edge map sorted by name and version.
+func sortedVertices(edges map[module.Version]graph.Edge[module.Version]) []module.Version {
+	vertices := make([]module.Version, 0, len(edges))
+	for vertex := range edges {
+		vertices = append(vertices, vertex)
+	}
+	sort.Slice(vertices, func(i, j int) bool {
+		return format(vertices[i]) < format(vertices[j])
+	})
+	return vertices
+}
+
 // format formats the module version to string.
 func format(m module.Version) string {
 	formattedMsg := m.Path
@@ -106,22 +201,67 @@ func (c *KpmClient) Graph(opts ...GraphOption) (*DepGraph, error) {
 		return nil, fmt.Errorf("kMod is required")
 	}
 
+	// The members of a workspace are resolved together,
+	// the graph is made of the versions selected for the whole workspace.
+	ws, err := c.FindWorkspace(kMod.HomePath)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		res, err := c.resolveWorkspace(ws.withMember(kMod), resolver.WithOffline(c.offline))
+		if err != nil {
+			return nil, err
+		}
+		return res.selectedGraph()
+	}
+
 	// Create the dependency graph.
 	dGraph := NewDepGraph()
 	// Take the current KCL module as the start vertex
 	dGraph.AddVertex(kMod.GetPkgName(), kMod.GetPkgVersion())
 
+	// Create a new dependency resolver
+	depResolver := resolver.DepsResolver{
+		DefaultCachePath:      c.homePath,
+		InsecureSkipTLSverify: c.insecureSkipTLSverify,
+		Downloader:            c.DepDownloader,
+		Settings:              &c.settings,
+		LogWriter:             c.logWriter,
+		Jobs:                  c.jobs,
+		Cache:                 c.Cache(),
+	}
+
+	err = c.resolveIntoGraph(dGraph, &depResolver, kMod, resolver.WithOffline(c.offline))
+	if err != nil {
+		return nil, err
+	}
+
+	return dGraph, nil
+}
+
+// resolveIntoGraph resolves the dependencies of the KCL Module by the resolver
+// and adds them into the dependency graph, the options are applied to the resolving, e.g. 'resolver.WithOffline'.
+func (c *KpmClient) resolveIntoGraph(dGraph *DepGraph, depResolver *resolver.DepsResolver, kMod *pkg.KclPkg, resolveOpts ...resolver.ResolveOption) error {
 	modDeps := kMod.ModFile.Dependencies.Deps
 	if modDeps == nil {
-		return nil, fmt.Errorf("kcl.mod dependencies is nil")
+		return fmt.Errorf("kcl.mod dependencies is nil")
 	}
 
 	// ResolveFunc is the function for resolving each dependency when traversing the dependency graph.
 	resolverFunc := func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
 		if dep != nil && parentPkg != nil {
+			// The checksum of the dependency is recorded in the kcl.mod.lock of the current KCL module.
+			if dep.Sum == "" && kMod.Dependencies.Deps != nil {
+				if lockDep, ok := kMod.Dependencies.Deps.Get(dep.Name); ok && lockDep.Version == dep.Version {
+					depWithSum := *dep
+					depWithSum.Sum = lockDep.Sum
+					dep = &depWithSum
+				}
+			}
+
 			// Set the dep as a vertex into graph.
-			depVertex, err := dGraph.AddVertex(dep.Name, dep.Version)
-			if err != nil && err != graph.ErrVertexAlreadyExists {
+			depVertex, err := dGraph.AddDepVertex(dep)
+			if err != nil {
 				return err
 			}
 
@@ -144,24 +284,60 @@ func (c *KpmClient) Graph(opts ...GraphOption) (*DepGraph, error) {
 		return nil
 	}
 
-	// Create a new dependency resolver
-	depResolver := resolver.DepsResolver{
-		DefaultCachePath:      c.homePath,
-		InsecureSkipTLSverify: c.insecureSkipTLSverify,
-		Downloader:            c.DepDownloader,
-		Settings:              &c.settings,
-		LogWriter:             c.logWriter,
-	}
-	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs, resolverFunc)
+	// The resolver may be shared by several modules, e.g. the members of a workspace,
+	// so the resolve funcs for the previous module are replaced.
+	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs[:0], resolverFunc)
 
-	err := depResolver.Resolve(
+	return depResolver.Resolve(append([]resolver.ResolveOption{
 		resolver.WithEnableCache(true),
 		resolver.WithResolveKclMod(kMod),
-	)
+	}, resolveOpts...)...)
+}
 
+// Why explains why the module named 'name' is in the build list of the given KCL Module.
+// It returns every shortest path from the KCL Module to the module named 'name',
+// each path starts with the KCL Module itself and ends with the module named 'name'.
+func (c *KpmClient) Why(name string, opts ...GraphOption) ([][]pkg.Dependency, error) {
+	options := &GraphOptions{}
+	for _, o := range opts {
+		err := o(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kMod := options.kMod
+	if kMod == nil {
+		return nil, fmt.Errorf("kMod is required")
+	}
+
+	dGraph, err := c.Graph(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return dGraph, nil
+	root := module.Version{Path: kMod.GetPkgName(), Version: kMod.GetPkgVersion()}
+	paths, err := dGraph.ShortestPaths(root, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var res [][]pkg.Dependency
+	for _, path := range paths {
+		chain := make([]pkg.Dependency, 0, len(path))
+		for _, vertex := range path {
+			dep, ok := dGraph.Dependency(vertex)
+			if !ok {
+				dep = pkg.Dependency{
+					Name:          vertex.Path,
+					Version:       vertex.Version,
+					LocalFullPath: kMod.HomePath,
+				}
+			}
+			chain = append(chain, dep)
+		}
+		res = append(res, chain)
+	}
+
+	return res, nil
 }
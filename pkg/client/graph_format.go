@@ -0,0 +1,325 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	GraphFormatText    = "text"
+	GraphFormatDot     = "dot"
+	GraphFormatJson    = "json"
+	GraphFormatMermaid = "mermaid"
+)
+
+// GraphNode is a module in the dependency graph with its metadata.
+type GraphNode struct {
+	// Name is the name of the module.
+	Name string `json:"name"`
+	// Version is the version of the module.
+	Version string `json:"version,omitempty"`
+	// Source is the source type of the module, e.g. "git", "oci" or "local".
+	// It is empty for the root module.
+	Source string `json:"source,omitempty"`
+	// Sum is the checksum of the module recorded in kcl.mod.lock.
+	Sum string `json:"sum,omitempty"`
+	// Root is true if the module is the root module of the graph.
+	Root bool `json:"root,omitempty"`
+	// Direct is true if the module is a direct dependency of the root module.
+	Direct bool `json:"direct"`
+}
+
+// ID returns the identity of the node in the graph, e.g. "helloworld@0.1.4".
+func (n GraphNode) ID() string {
+	return format(module.Version{Path: n.Name, Version: n.Version})
+}
+
+// GraphEdge is a requirement from one module to another in the dependency graph.
+type GraphEdge struct {
+	// From is the ID of the module which requires the other.
+	From string `json:"from"`
+	// To is the ID of the module which is required.
+	To string `json:"to"`
+}
+
+// GraphFormatter formats the dependency graph into string.
+// Implement it to render the dependency graph in a custom format by 'DepGraph.Display'.
+// The nodes and edges are sorted and the first node is always the root module.
+type GraphFormatter interface {
+	Format(nodes []GraphNode, edges []GraphEdge) (string, error)
+}
+
+// NewGraphFormatter returns the built-in formatter for the format name.
+func NewGraphFormatter(formatName string) (GraphFormatter, error) {
+	switch formatName {
+	case "", GraphFormatText:
+		return &TextGraphFormatter{}, nil
+	case GraphFormatDot:
+		return &DotGraphFormatter{}, nil
+	case GraphFormatJson:
+		return &JsonGraphFormatter{}, nil
+	case GraphFormatMermaid:
+		return &MermaidGraphFormatter{}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unsupported graph format '%s', supported formats are: %s",
+			formatName,
+			strings.Join([]string{GraphFormatText, GraphFormatDot, GraphFormatJson, GraphFormatMermaid}, ", "),
+		)
+	}
+}
+
+// Display formats the dependency graph reachable from the start vertex by the formatter.
+func (g *DepGraph) Display(startVertex module.Version, formatter GraphFormatter) (string, error) {
+	nodes, edges, err := g.NodesAndEdges(startVertex)
+	if err != nil {
+		return "", err
+	}
+	return formatter.Format(nodes, edges)
+}
+
+// NodesAndEdges returns the nodes and edges of the dependency graph reachable from the start vertex.
+// The first node is the start vertex, the other nodes and the edges are sorted by the name and version.
+func (g *DepGraph) NodesAndEdges(startVertex module.Version) ([]GraphNode, []GraphEdge, error) {
+	adjMap, err := g.gra.AdjacencyMap()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := adjMap[startVertex]; !ok {
+		return nil, nil, fmt.Errorf("module '%s' not found in the dependency graph", format(startVertex))
+	}
+
+	// Collect all the vertices reachable from the start vertex.
+	visited := map[module.Version]bool{startVertex: true}
+	queue := []module.Version{startVertex}
+	var vertices []module.Version
+	var edges []GraphEdge
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range sortedVertices(adjMap[current]) {
+			edges = append(edges, GraphEdge{From: format(current), To: format(next)})
+			if !visited[next] {
+				visited[next] = true
+				vertices = append(vertices, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	sort.Slice(vertices, func(i, j int) bool {
+		return format(vertices[i]) < format(vertices[j])
+	})
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+
+	nodes := []GraphNode{{Name: startVertex.Path, Version: startVertex.Version, Root: true}}
+	for _, vertex := range vertices {
+		node := GraphNode{Name: vertex.Path, Version: vertex.Version}
+		if dep, ok := g.Dependency(vertex); ok {
+			node.Source = dep.GetSourceType()
+			node.Sum = dep.Sum
+		}
+		_, node.Direct = adjMap[startVertex][vertex]
+		nodes = append(nodes, node)
+	}
+
+	return nodes, edges, nil
+}
+
+// TextGraphFormatter formats the dependency graph into the plain edge list "a@v b@v".
+type TextGraphFormatter struct{}
+
+func (f *TextGraphFormatter) Format(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	var sb strings.Builder
+	for _, edge := range edges {
+		sb.WriteString(fmt.Sprintf("%s %s\n", edge.From, edge.To))
+	}
+	return sb.String(), nil
+}
+
+// DotGraphFormatter formats the dependency graph into the Graphviz DOT language.
+// The indirect dependencies are drawn with dashed lines.
+type DotGraphFormatter struct{}
+
+func (f *DotGraphFormatter) Format(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	var sb strings.Builder
+	name := ""
+	if len(nodes) > 0 {
+		name = nodes[0].ID()
+	}
+	sb.WriteString(fmt.Sprintf("digraph %q {\n", name))
+	for _, node := range nodes {
+		label := node.ID()
+		if node.Source != "" {
+			label += "\n" + node.Source
+		}
+		attrs := []string{fmt.Sprintf("label=%q", label)}
+		if node.Sum != "" {
+			attrs = append(attrs, fmt.Sprintf("tooltip=%q", node.Sum))
+		}
+		if !node.Root && !node.Direct {
+			attrs = append(attrs, "style=dashed")
+		}
+		sb.WriteString(fmt.Sprintf("  %q [%s];\n", node.ID(), strings.Join(attrs, ", ")))
+	}
+	for _, edge := range edges {
+		sb.WriteString(fmt.Sprintf("  %q -> %q;\n", edge.From, edge.To))
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// JsonGraphFormatter formats the dependency graph into JSON with nodes and edges.
+type JsonGraphFormatter struct{}
+
+func (f *JsonGraphFormatter) Format(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	if edges == nil {
+		edges = []GraphEdge{}
+	}
+	graph := struct {
+		Nodes []GraphNode `json:"nodes"`
+		Edges []GraphEdge `json:"edges"`
+	}{
+		Nodes: nodes,
+		Edges: edges,
+	}
+	res, err := json.MarshalIndent(&graph, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(res) + "\n", nil
+}
+
+// MermaidGraphFormatter formats the dependency graph into the Mermaid flowchart.
+// The indirect dependencies are drawn with dashed lines.
+type MermaidGraphFormatter struct{}
+
+func (f *MermaidGraphFormatter) Format(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("graph TD\n")
+	ids := make(map[string]string, len(nodes))
+	var indirect []string
+	for i, node := range nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.ID()] = id
+		label := node.ID()
+		if node.Source != "" {
+			label += "<br/>" + node.Source
+		}
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", id, label))
+		if !node.Root && !node.Direct {
+			indirect = append(indirect, id)
+		}
+	}
+	for _, edge := range edges {
+		sb.WriteString(fmt.Sprintf("  %s --> %s\n", ids[edge.From], ids[edge.To]))
+	}
+	if len(indirect) > 0 {
+		sb.WriteString("  classDef indirect stroke-dasharray: 5 5\n")
+		sb.WriteString(fmt.Sprintf("  class %s indirect\n", strings.Join(indirect, ",")))
+	}
+	return sb.String(), nil
+}
+
+// TreeGraphFormatter formats the dependency graph into a tree, e.g.
+//
+//	pkg@0.0.1
+//	├── dep1@0.0.1 (local)
+//	│   └── dep2@0.0.1 (local)
+//	└── dep3@0.0.1 (local)
+//	    └── dep2@0.0.1 (local) (*)
+//
+// The subtree of a module is only printed once, the later occurrences are marked with '(*)'.
+type TreeGraphFormatter struct {
+	// Depth is the max depth of the tree to print, the root is at depth 0.
+	// The negative depth means no limit.
+	Depth int
+	// Invert is the name of the module to print the reverse dependencies of.
+	// If it is set, the tree starts from the modules named 'Invert' and goes up to the root.
+	Invert string
+}
+
+func (f *TreeGraphFormatter) Format(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	if len(nodes) == 0 {
+		return "", nil
+	}
+
+	nodeMap := make(map[string]GraphNode, len(nodes))
+	for _, node := range nodes {
+		nodeMap[node.ID()] = node
+	}
+
+	children := make(map[string][]string)
+	for _, edge := range edges {
+		if f.Invert != "" {
+			children[edge.To] = append(children[edge.To], edge.From)
+		} else {
+			children[edge.From] = append(children[edge.From], edge.To)
+		}
+	}
+
+	var roots []string
+	if f.Invert != "" {
+		for _, node := range nodes {
+			if node.Name == f.Invert {
+				roots = append(roots, node.ID())
+			}
+		}
+		if len(roots) == 0 {
+			return "", fmt.Errorf("module '%s' not found in the dependency graph", f.Invert)
+		}
+	} else {
+		roots = []string{nodes[0].ID()}
+	}
+
+	var sb strings.Builder
+	printed := make(map[string]bool)
+	var printTree func(id, prefix string, depth int)
+	printTree = func(id, prefix string, depth int) {
+		if f.Depth >= 0 && depth >= f.Depth {
+			return
+		}
+		printed[id] = true
+		for i, child := range children[id] {
+			branch, indent := "├── ", "│   "
+			if i == len(children[id])-1 {
+				branch, indent = "└── ", "    "
+			}
+			sb.WriteString(prefix + branch + treeNodeLabel(nodeMap[child]))
+			if printed[child] && len(children[child]) > 0 {
+				sb.WriteString(" (*)\n")
+				continue
+			}
+			sb.WriteString("\n")
+			printTree(child, prefix+indent, depth+1)
+		}
+	}
+
+	for i, root := range roots {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(treeNodeLabel(nodeMap[root]) + "\n")
+		printTree(root, "", 0)
+	}
+
+	return sb.String(), nil
+}
+
+// treeNodeLabel returns the label of the node in the tree, e.g. 'helloworld@0.1.4 (oci)'.
+func treeNodeLabel(node GraphNode) string {
+	label := node.ID()
+	if node.Source != "" {
+		label += fmt.Sprintf(" (%s)", node.Source)
+	}
+	return label
+}
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,3 +49,187 @@ func testGraph(t *testing.T) {
 	assert.Contains(t, utils.RmNewline(graStr), "pkg@0.0.1 helloworld@0.1.4")
 	assert.Contains(t, utils.RmNewline(graStr), "dep@0.0.1 helloworld@0.1.4")
 }
+
+func TestWhy(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestWhy", TestFunc: testWhy}})
+}
+
+func testWhy(t *testing.T, kpmcli *KpmClient) {
+	modPath := filepath.Join(getTestDir("test_why"), "pkg")
+
+	kMod, err := kpmcli.LoadPkgFromPath(modPath)
+	if err != nil {
+		t.Fatalf("failed to load kcl package: %v", err)
+	}
+
+	paths, err := kpmcli.Why("dep2", WithGraphMod(kMod))
+	if err != nil {
+		t.Fatalf("failed to explain why: %v", err)
+	}
+
+	var chains []string
+	for _, path := range paths {
+		var chain []string
+		for _, dep := range path {
+			chain = append(chain, dep.Name+"@"+dep.Version+":"+dep.GetSourceType())
+		}
+		chains = append(chains, strings.Join(chain, " "))
+	}
+
+	assert.Equal(t, []string{
+		"pkg@0.0.1: dep1@0.0.1:local dep2@0.0.1:local",
+		"pkg@0.0.1: dep3@0.0.1:local dep2@0.0.1:local",
+	}, chains)
+
+	paths, err = kpmcli.Why("not_exist", WithGraphMod(kMod))
+	if err != nil {
+		t.Fatalf("failed to explain why: %v", err)
+	}
+	assert.Empty(t, paths)
+}
+
+func TestGraphFormat(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestGraphFormat", TestFunc: testGraphFormat}})
+}
+
+func testGraphFormat(t *testing.T, kpmcli *KpmClient) {
+	testPath := getTestDir("test_graph_format")
+	modPath := filepath.Join(testPath, "pkg")
+
+	kMod, err := kpmcli.LoadPkgFromPath(modPath)
+	if err != nil {
+		t.Fatalf("failed to load kcl package: %v", err)
+	}
+
+	dGraph, err := kpmcli.Graph(WithGraphMod(kMod))
+	if err != nil {
+		t.Fatalf("failed to create dependency graph: %v", err)
+	}
+
+	root := module.Version{Path: kMod.GetPkgName(), Version: kMod.GetPkgVersion()}
+	nodes, _, err := dGraph.NodesAndEdges(root)
+	if err != nil {
+		t.Fatalf("failed to get the nodes of graph: %v", err)
+	}
+	assert.Equal(t, []GraphNode{
+		{Name: "pkg", Version: "0.0.1", Root: true},
+		{Name: "dep1", Version: "0.0.1", Source: "local", Sum: "dep1Sum=", Direct: true},
+		{Name: "dep2", Version: "0.0.1", Source: "local", Sum: "dep2Sum=", Direct: false},
+		{Name: "dep3", Version: "0.0.1", Source: "local", Direct: true},
+	}, nodes)
+
+	for _, format := range []string{GraphFormatText, GraphFormatDot, GraphFormatJson, GraphFormatMermaid} {
+		formatter, err := NewGraphFormatter(format)
+		if err != nil {
+			t.Fatalf("failed to create formatter %s: %v", format, err)
+		}
+
+		res, err := dGraph.Display(root, formatter)
+		if err != nil {
+			t.Fatalf("failed to display graph in %s: %v", format, err)
+		}
+
+		expected, err := os.ReadFile(filepath.Join(testPath, "expect."+format))
+		if err != nil {
+			t.Fatalf("failed to read the expected %s: %v", format, err)
+		}
+		assert.Equal(t, utils.RmNewline(string(expected)), utils.RmNewline(res))
+	}
+
+	_, err = NewGraphFormatter("invalid")
+	assert.Equal(t, "unsupported graph format 'invalid', supported formats are: text, dot, json, mermaid", err.Error())
+}
+
+func TestGraphTree(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestGraphTree", TestFunc: testGraphTree}})
+}
+
+func testGraphTree(t *testing.T, kpmcli *KpmClient) {
+	modPath := filepath.Join(getTestDir("test_graph_format"), "pkg")
+
+	kMod, err := kpmcli.LoadPkgFromPath(modPath)
+	if err != nil {
+		t.Fatalf("failed to load kcl package: %v", err)
+	}
+
+	dGraph, err := kpmcli.Graph(WithGraphMod(kMod))
+	if err != nil {
+		t.Fatalf("failed to create dependency graph: %v", err)
+	}
+
+	root := module.Version{Path: kMod.GetPkgName(), Version: kMod.GetPkgVersion()}
+	testCases := []struct {
+		name      string
+		formatter *TreeGraphFormatter
+		expected  string
+	}{
+		{
+			name:      "Tree",
+			formatter: &TreeGraphFormatter{Depth: -1},
+			expected: `pkg@0.0.1
+├── dep1@0.0.1 (local)
+│   └── dep2@0.0.1 (local)
+└── dep3@0.0.1 (local)
+    └── dep2@0.0.1 (local)
+`,
+		},
+		{
+			name:      "Depth",
+			formatter: &TreeGraphFormatter{Depth: 1},
+			expected: `pkg@0.0.1
+├── dep1@0.0.1 (local)
+└── dep3@0.0.1 (local)
+`,
+		},
+		{
+			name:      "Invert",
+			formatter: &TreeGraphFormatter{Depth: -1, Invert: "dep2"},
+			expected: `dep2@0.0.1 (local)
+├── dep1@0.0.1 (local)
+│   └── pkg@0.0.1
+└── dep3@0.0.1 (local)
+    └── pkg@0.0.1
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := dGraph.Display(root, tc.formatter)
+			if err != nil {
+				t.Fatalf("failed to display graph: %v", err)
+			}
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+
+	_, err = dGraph.Display(root, &TreeGraphFormatter{Depth: -1, Invert: "not_exist"})
+	assert.Equal(t, "module 'not_exist' not found in the dependency graph", err.Error())
+}
+
+func TestTreeGraphFormatterDuplicates(t *testing.T) {
+	nodes := []GraphNode{
+		{Name: "pkg", Version: "0.0.1", Root: true},
+		{Name: "a", Version: "0.0.1", Source: "oci", Direct: true},
+		{Name: "b", Version: "0.0.1", Source: "oci", Direct: true},
+		{Name: "c", Version: "0.0.1", Source: "git"},
+		{Name: "d", Version: "0.0.1", Source: "git"},
+	}
+	edges := []GraphEdge{
+		{From: "a@0.0.1", To: "c@0.0.1"},
+		{From: "b@0.0.1", To: "c@0.0.1"},
+		{From: "c@0.0.1", To: "d@0.0.1"},
+		{From: "pkg@0.0.1", To: "a@0.0.1"},
+		{From: "pkg@0.0.1", To: "b@0.0.1"},
+	}
+
+	res, err := (&TreeGraphFormatter{Depth: -1}).Format(nodes, edges)
+	assert.Nil(t, err)
+	assert.Equal(t, `pkg@0.0.1
+├── a@0.0.1 (oci)
+│   └── c@0.0.1 (git)
+│       └── d@0.0.1 (git)
+└── b@0.0.1 (oci)
+    └── c@0.0.1 (git) (*)
+`, res)
+}
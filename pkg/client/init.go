@@ -2,14 +2,20 @@ package client
 
 import (
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/templates"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -19,6 +25,9 @@ type InitOptions struct {
 	ModName    string
 	ModVersion string
 	WorkDir    string
+	// Template is the template to initialize the package from, it is the name of a built-in template,
+	// the path of a local directory, an OCI url 'oci://...' or a git url.
+	Template string
 }
 
 type InitOption func(*InitOptions) error
@@ -51,6 +60,14 @@ func WithInitModName(modName string) InitOption {
 	}
 }
 
+// WithInitTemplate sets the template to initialize the package from.
+func WithInitTemplate(template string) InitOption {
+	return func(opts *InitOptions) error {
+		opts.Template = template
+		return nil
+	}
+}
+
 func (c *KpmClient) Init(options ...InitOption) error {
 	opts := &InitOptions{}
 	for _, option := range options {
@@ -98,9 +115,128 @@ func (c *KpmClient) Init(options ...InitOption) error {
 		Version:  modVer,
 	})
 
+	if opts.Template != "" {
+		return c.initFromTemplate(&kclPkg, opts.Template, workDir)
+	}
+
 	return c.InitEmptyPkg(&kclPkg)
 }
 
+// initFromTemplate initializes the package from the template, which is a built-in template,
+// a local directory, or a kcl package downloaded from an OCI registry or a git repository.
+func (c *KpmClient) initFromTemplate(kclPkg *pkg.KclPkg, template, workDir string) error {
+	if builtin, ok := templates.Builtin(template); ok {
+		return c.renderTemplate(kclPkg, builtin)
+	}
+
+	localPath := template
+	if !filepath.IsAbs(localPath) {
+		localPath = filepath.Join(workDir, localPath)
+	}
+	if utils.DirExists(localPath) {
+		return c.renderTemplate(kclPkg, os.DirFS(localPath))
+	}
+
+	source, err := templateSource(template)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return reporter.NewErrorEvent(
+			reporter.TemplateNotFound,
+			fmt.Errorf("'%s' is neither a built-in template ('%s'), a local directory nor a remote url",
+				template, strings.Join(templates.Builtins(), "', '")),
+			fmt.Sprintf("failed to find the template '%s'", template),
+		)
+	}
+
+	// The template is refused before it is downloaded if its signature is not trusted.
+	if err := c.verifySourceSignature(source); err != nil {
+		return err
+	}
+
+	return newVisitor(*source, c).Visit(source, func(templatePkg *pkg.KclPkg) error {
+		return c.renderTemplate(kclPkg, os.DirFS(templatePkg.HomePath))
+	})
+}
+
+// templateSource returns the source of the remote template, e.g. 'oci://ghcr.io/kcl-lang/k8s-app?tag=0.1.0',
+// 'https://github.com/kcl-lang/templates.git' or 'git://github.com/kcl-lang/templates?tag=v0.1.0'.
+// It returns nil if the template is not remote.
+func templateSource(template string) (*downloader.Source, error) {
+	switch {
+	case strings.HasPrefix(template, constants.OciScheme+"://"),
+		strings.HasPrefix(template, constants.GitScheme+"://"),
+		strings.HasPrefix(template, constants.SshScheme+"://"):
+		return downloader.NewSourceFromStr(template)
+	case strings.HasPrefix(template, constants.HttpsScheme+"://"), strings.HasPrefix(template, constants.HttpScheme+"://"):
+		// The git repositories over http(s), the ref is set by the query, e.g. '?tag=v0.1.0'.
+		u, err := url.Parse(template)
+		if err != nil {
+			return nil, err
+		}
+		git := &downloader.Git{
+			Tag:    u.Query().Get(constants.Tag),
+			Commit: u.Query().Get(constants.GitCommit),
+			Branch: u.Query().Get(constants.GitBranch),
+		}
+		u.RawQuery = ""
+		git.Url = u.String()
+		return &downloader.Source{Git: git}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// renderTemplate renders the template into the home path of the package,
+// the name and the version of the package in the kcl.mod from the template are set to the ones of the package.
+func (c *KpmClient) renderTemplate(kclPkg *pkg.KclPkg, template fs.FS) error {
+	created, existing, err := templates.Render(template, kclPkg.HomePath, templates.Values{
+		Name:    kclPkg.ModFile.Pkg.Name,
+		Version: kclPkg.ModFile.Pkg.Version,
+		Edition: kclPkg.ModFile.Pkg.Edition,
+	})
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedCreateFile, err, "failed to render the template")
+	}
+	for _, path := range created {
+		reporter.ReportMsgTo(fmt.Sprintf("creating new :%s", path), c.GetLogWriter())
+	}
+	for _, path := range existing {
+		reporter.ReportMsgTo(fmt.Sprintf("'%s' already exists", path), c.GetLogWriter())
+	}
+
+	modFilePath := kclPkg.ModFile.GetModFilePath()
+	if slices.Contains(created, modFilePath) {
+		templatePkg, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(kclPkg.HomePath),
+			pkg.WithSettings(&c.settings),
+		)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedLoadKclMod, err, fmt.Sprintf("failed to load '%s' from the template", modFilePath))
+		}
+		templatePkg.ModFile.Pkg.Name = kclPkg.ModFile.Pkg.Name
+		templatePkg.ModFile.Pkg.Version = kclPkg.ModFile.Pkg.Version
+		err = templatePkg.ModFile.StoreModFile()
+		if err != nil {
+			return err
+		}
+		*kclPkg = *templatePkg
+	}
+
+	// The package files missing in the template are created as the empty package.
+	if !utils.DirExists(modFilePath) {
+		err = c.createIfNotExist(modFilePath, kclPkg.ModFile.StoreModFile)
+		if err != nil {
+			return err
+		}
+	}
+	if !utils.DirExists(kclPkg.ModFile.GetModLockFilePath()) {
+		return c.createIfNotExist(kclPkg.ModFile.GetModLockFilePath(), kclPkg.LockDepsVersion)
+	}
+	return nil
+}
+
 // createIfNotExist will create a file if it does not exist.
 func (c *KpmClient) createIfNotExist(filepath string, storeFunc func() error) error {
 	reporter.ReportMsgTo(fmt.Sprintf("creating new :%s", filepath), c.GetLogWriter())
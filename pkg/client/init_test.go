@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/runner"
 	"kcl-lang.io/kpm/pkg/utils"
@@ -293,3 +294,75 @@ func TestModInitWithExistFile(t *testing.T) {
 
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestModInitWithExitModFile", TestFunc: testFunc}})
 }
+
+// test 'kcl mod init --template <template>'
+func TestModInitTemplate(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		workDir := t.TempDir()
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		// The built-in template.
+		err := kpmcli.Init(
+			WithInitWorkDir(workDir),
+			WithInitModName("my_app"),
+			WithInitModVersion("0.2.0"),
+			WithInitTemplate("k8s-app"),
+		)
+		assert.Nil(t, err)
+		modPath := filepath.Join(workDir, "my_app")
+		assert.FileExists(t, filepath.Join(modPath, "app", "app.k"))
+		assert.FileExists(t, filepath.Join(modPath, "kcl.mod.lock"))
+		assert.Contains(t, buf.String(), fmt.Sprintf("creating new :%s", filepath.Join(modPath, "main.k")))
+		mainK, err := os.ReadFile(filepath.Join(modPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(mainK), `name = "my_app"`)
+		kmod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(modPath))
+		assert.Nil(t, err)
+		assert.Equal(t, kmod.ModFile.Pkg.Name, "my_app")
+		assert.Equal(t, kmod.ModFile.Pkg.Version, "0.2.0")
+		assert.Equal(t, kmod.ModFile.Pkg.Edition, runner.GetKclVersion())
+
+		// The existing files are not overwritten.
+		buf.Reset()
+		assert.Nil(t, os.WriteFile(filepath.Join(modPath, "main.k"), []byte("a = 1"), 0644))
+		err = kpmcli.Init(
+			WithInitModPath(modPath),
+			WithInitTemplate("k8s-app"),
+		)
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), fmt.Sprintf("'%s' already exists", filepath.Join(modPath, "main.k")))
+		mainK, err = os.ReadFile(filepath.Join(modPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Equal(t, string(mainK), "a = 1")
+
+		// The local template, the name and the version in its kcl.mod are replaced.
+		err = kpmcli.Init(
+			WithInitWorkDir(workDir),
+			WithInitModName("my_lib"),
+			WithInitTemplate(filepath.Join(getTestDir("test_init_template"), "local_template")),
+		)
+		assert.Nil(t, err)
+		modPath = filepath.Join(workDir, "my_lib")
+		kmod, err = pkg.LoadKclPkgWithOpts(pkg.WithPath(modPath))
+		assert.Nil(t, err)
+		assert.Equal(t, kmod.ModFile.Pkg.Name, "my_lib")
+		assert.Equal(t, kmod.ModFile.Pkg.Version, "0.0.1")
+		assert.Equal(t, kmod.ModFile.Pkg.Edition, "v0.9.0")
+		mainK, err = os.ReadFile(filepath.Join(modPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Equal(t, string(mainK), "name = \"my_lib\"\nversion = \"0.0.1\"\n")
+		assert.FileExists(t, filepath.Join(modPath, "docs", "my_lib.md"))
+
+		// The unknown template.
+		err = kpmcli.Init(
+			WithInitWorkDir(workDir),
+			WithInitModName("my_other"),
+			WithInitTemplate("not_exist"),
+		)
+		assert.ErrorIs(t, err, kpmerrors.PathNotFound)
+		assert.Contains(t, err.Error(), "failed to find the template 'not_exist'")
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestModInitTemplate", TestFunc: testFunc}})
+}
@@ -12,6 +12,10 @@ import (
 
 // LoginOci will login to the oci registry.
 func (c *KpmClient) LoginOci(hostname, username, password string) error {
+	if err := c.checkOnline(fmt.Sprintf("logging in to '%s'", hostname)); err != nil {
+		return err
+	}
+
 	// Allow plaintext credentials for plain HTTP registries
 	defaultOciPlainHttp, forceOciPlainHttp := c.GetSettings().ForceOciPlainHttp()
 	allowPlaintext := false
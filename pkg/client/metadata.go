@@ -15,11 +15,15 @@ import (
 func (c *KpmClient) ResolvePkgDepsMetadata(kclPkg *pkg.KclPkg, update bool) error {
 	var err error
 	if kclPkg.IsVendorMode() {
-		err = c.VendorDeps(kclPkg)
+		err = c.vendorDepsWithOpts(kclPkg, &UpdateOptions{
+			offline:     c.offline || !update,
+			skipMissing: !update,
+		})
 	} else {
 		_, err = c.Update(
 			WithUpdatedKclPkg(kclPkg),
 			WithOffline(!update),
+			withSkipMissing(!update),
 			WithUpdateModFile(false),
 		)
 	}
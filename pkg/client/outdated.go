@@ -0,0 +1,226 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"kcl-lang.io/kpm/pkg/downloader"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+)
+
+// OutdatedOptions is the options for listing the outdated dependencies of a kcl package.
+type OutdatedOptions struct {
+	// KclPkg is the kcl package to list the outdated dependencies of.
+	KclPkg *pkg.KclPkg
+}
+
+type OutdatedOption func(*OutdatedOptions) error
+
+// WithOutdatedKclPkg sets the kcl package to list the outdated dependencies of.
+func WithOutdatedKclPkg(kclPkg *pkg.KclPkg) OutdatedOption {
+	return func(opts *OutdatedOptions) error {
+		if kclPkg == nil {
+			return fmt.Errorf("kclPkg cannot be nil")
+		}
+		opts.KclPkg = kclPkg
+		return nil
+	}
+}
+
+// OutdatedDep is a dependency with newer versions available.
+type OutdatedDep struct {
+	// Name is the name of the dependency in kcl.mod.
+	Name string `json:"name"`
+	// Source is the source type of the dependency, "git" or "oci".
+	Source string `json:"source"`
+	// Current is the version of the dependency in use,
+	// which is the version pinned in kcl.mod.lock or the version in kcl.mod,
+	// or the tag of the git dependency pinned to a tag.
+	Current string `json:"current"`
+	// LatestCompatible is the latest version with the same major version as the current one.
+	// It is empty if no compatible version is available or the dependency is skipped.
+	LatestCompatible string `json:"latest_compatible"`
+	// Latest is the latest version of the dependency.
+	Latest string `json:"latest"`
+	// Skipped is the reason why the dependency is not checked, e.g. it is pinned to a git branch or commit,
+	// which can not be compared with the released versions.
+	// LatestCompatible and Latest are empty if it is skipped.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Outdated lists the dependencies in kcl.mod with newer versions available.
+// The local dependencies are skipped, and the dependencies not in semantic versions,
+// e.g. pinned to a git branch or commit, are listed with the reason why they are skipped.
+func (c *KpmClient) Outdated(options ...OutdatedOption) ([]OutdatedDep, error) {
+	opts := &OutdatedOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	kMod := opts.KclPkg
+	if kMod == nil {
+		return nil, fmt.Errorf("kcl package is nil")
+	}
+
+	// The versions of the dependencies are listed from the registries.
+	if err := c.checkOnline("checking the outdated dependencies"); err != nil {
+		return nil, err
+	}
+
+	modDeps := kMod.ModFile.Dependencies.Deps
+	if modDeps == nil {
+		return nil, fmt.Errorf("kcl.mod dependencies is nil")
+	}
+	lockDeps := kMod.Dependencies.Deps
+	if lockDeps == nil {
+		return nil, fmt.Errorf("kcl.mod.lock dependencies is nil")
+	}
+
+	credStore, err := downloader.LoadCredentialFile(c.settings.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []OutdatedDep
+	for _, depName := range modDeps.Keys() {
+		dep, ok := modDeps.Get(depName)
+		if !ok {
+			return nil, fmt.Errorf("failed to get dependency %s", depName)
+		}
+		if dep.Source.Oci == nil && dep.Source.Git == nil {
+			continue
+		}
+
+		pinnedDep := dep
+		if lockDep, ok := lockDeps.Get(depName); ok && lockDep.Version != "" {
+			pinnedDep = lockDep
+		}
+		current := currentVersion(&pinnedDep)
+		if reason := skippedReason(&pinnedDep, current); reason != "" {
+			res = append(res, OutdatedDep{
+				Name:    depName,
+				Source:  dep.GetSourceType(),
+				Current: current,
+				Skipped: reason,
+			})
+			continue
+		}
+
+		source := dep.Source
+		if source.Oci != nil {
+			oci := *source.Oci
+			source.Oci = &oci
+		}
+		downloadOpts := downloader.NewDownloadOptions(
+			downloader.WithSource(source),
+			downloader.WithLogWriter(c.logWriter),
+			downloader.WithSettings(c.settings),
+			downloader.WithCredsStore(credStore),
+			downloader.WithInsecureSkipTLSverify(c.insecureSkipTLSverify),
+		)
+
+		versions, err := c.DepDownloader.Versions(downloadOpts)
+		if err != nil {
+			return nil, reporter.NewErrorEvent(
+				reporter.FailedGetPackageVersions,
+				err,
+				fmt.Sprintf("failed to get the versions of dependency '%s'", depName),
+			)
+		}
+
+		// For the OCI source, the latest version is the latest tag selected by the registry client.
+		// For the git source, the latest version is the latest tag, not the latest commit.
+		var latest string
+		if source.Oci != nil {
+			latest, err = c.DepDownloader.LatestVersion(downloadOpts)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		outdatedDep, ok := newOutdatedDep(depName, dep.GetSourceType(), current, latest, versions)
+		if ok {
+			res = append(res, outdatedDep)
+		}
+	}
+
+	return res, nil
+}
+
+// currentVersion returns the version of the dependency in use to compare with the versions available.
+// The git dependencies are locked with the versions of the packages in their kcl.mod, and the versions
+// available are the tags of the repositories, so the tag is used for the git dependency pinned to a tag.
+func currentVersion(dep *pkg.Dependency) string {
+	if git := dep.Source.Git; git != nil && git.Tag != "" {
+		return git.Tag
+	}
+	return dep.Version
+}
+
+// newOutdatedDep selects the latest and the latest compatible versions from the versions of the dependency,
+// and returns false if no newer version is available.
+// If 'latest' is empty, the latest one of the versions is selected.
+func newOutdatedDep(name, sourceType, current, latest string, versions []string) (OutdatedDep, bool) {
+	// Skip the versions that are not semantic versions, e.g. 'latest' or 'main'.
+	var validVersions []string
+	for _, v := range versions {
+		if _, err := version.NewVersion(v); err == nil {
+			validVersions = append(validVersions, v)
+		}
+	}
+
+	if latest == "" {
+		latest, _ = semver.LatestVersion(validVersions)
+	}
+	if latest == "" {
+		return OutdatedDep{}, false
+	}
+
+	outdatedDep := OutdatedDep{
+		Name:    name,
+		Source:  sourceType,
+		Current: current,
+		Latest:  latest,
+	}
+
+	currentVersion, err := version.NewVersion(current)
+	if err != nil {
+		// The current version is not a semantic version, e.g. a git commit or branch,
+		// which can not be compared with the versions.
+		return OutdatedDep{}, false
+	}
+
+	if compatible, err := semver.LatestCompatibleVersion(validVersions, current); err == nil {
+		outdatedDep.LatestCompatible = compatible
+	}
+
+	latestVersion, err := version.NewVersion(latest)
+	if err != nil || !latestVersion.GreaterThan(currentVersion) {
+		return OutdatedDep{}, false
+	}
+
+	return outdatedDep, true
+}
+
+// skippedReason returns the reason why the dependency is not checked for the newer versions,
+// or an empty string if it is checked.
+// The git dependencies pinned to a branch or commit are locked with the versions of the packages,
+// not the tags, and the branches and commits are not ordered with the tags, so they are skipped.
+func skippedReason(dep *pkg.Dependency, current string) string {
+	if git := dep.Source.Git; git != nil && git.Tag == "" {
+		if git.Commit != "" {
+			return fmt.Sprintf("pinned to the git commit '%s', not a released version", git.Commit)
+		}
+		if git.Branch != "" {
+			return fmt.Sprintf("pinned to the git branch '%s', not a released version", git.Branch)
+		}
+	}
+	if _, err := version.NewVersion(current); err != nil {
+		return fmt.Sprintf("the version '%s' is not a semantic version", current)
+	}
+	return ""
+}
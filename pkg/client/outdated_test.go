@@ -0,0 +1,100 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/downloader"
+	pkg "kcl-lang.io/kpm/pkg/package"
+)
+
+func TestNewOutdatedDep(t *testing.T) {
+	versions := []string{"1.27.0", "1.28.0", "1.28.3", "1.31.0", "2.0.0", "latest", "main"}
+
+	testCases := []struct {
+		name       string
+		sourceType string
+		current    string
+		latest     string
+		expected   OutdatedDep
+		outdated   bool
+	}{
+		{
+			name:       "OciWithLatestTag",
+			sourceType: "oci",
+			current:    "1.28.0",
+			latest:     "2.0.0",
+			expected:   OutdatedDep{Name: "k8s", Source: "oci", Current: "1.28.0", LatestCompatible: "1.31.0", Latest: "2.0.0"},
+			outdated:   true,
+		},
+		{
+			name:       "GitWithoutLatestTag",
+			sourceType: "git",
+			current:    "1.28.0",
+			expected:   OutdatedDep{Name: "k8s", Source: "git", Current: "1.28.0", LatestCompatible: "1.31.0", Latest: "2.0.0"},
+			outdated:   true,
+		},
+		{
+			name:       "UpToDate",
+			sourceType: "oci",
+			current:    "2.0.0",
+			latest:     "2.0.0",
+			outdated:   false,
+		},
+		{
+			name:       "GitCommit",
+			sourceType: "git",
+			current:    "ade147b",
+			outdated:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dep, outdated := newOutdatedDep("k8s", tc.sourceType, tc.current, tc.latest, versions)
+			assert.Equal(t, tc.outdated, outdated)
+			assert.Equal(t, tc.expected, dep)
+		})
+	}
+
+	_, outdated := newOutdatedDep("k8s", "git", "1.28.0", "", []string{"main"})
+	assert.False(t, outdated)
+}
+
+func TestSkippedReason(t *testing.T) {
+	gitDep := func(git downloader.Git) *pkg.Dependency {
+		return &pkg.Dependency{Name: "flask", Version: "0.0.1", Source: downloader.Source{Git: &git}}
+	}
+	url := "https://github.com/kcl-lang/flask-demo-kcl-manifests.git"
+
+	assert.Equal(t, "pinned to the git commit 'ade147b', not a released version", skippedReason(gitDep(downloader.Git{Url: url, Commit: "ade147b"}), "0.0.1"))
+	assert.Equal(t, "pinned to the git branch 'main', not a released version", skippedReason(gitDep(downloader.Git{Url: url, Branch: "main"}), "0.0.1"))
+	assert.Equal(t, "", skippedReason(gitDep(downloader.Git{Url: url, Tag: "v0.1.0"}), "v0.1.0"))
+
+	ociDep := &pkg.Dependency{Name: "k8s", Version: "latest", Source: downloader.Source{Oci: &downloader.Oci{Tag: "latest"}}}
+	assert.Equal(t, "the version 'latest' is not a semantic version", skippedReason(ociDep, "latest"))
+	ociDep.Version = "1.28.0"
+	assert.Equal(t, "", skippedReason(ociDep, "1.28.0"))
+}
+
+func TestCurrentVersion(t *testing.T) {
+	// The git dependency pinned to a tag is locked with the version of the package, not the tag.
+	kpkg, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(getTestDir(filepath.Join("issues", "github.com", "kcl-lang", "kpm", "issues", "587"))),
+	)
+	assert.NoError(t, err)
+	lockDep, ok := kpkg.Dependencies.Deps.Get("flask_manifests")
+	assert.True(t, ok)
+	assert.Equal(t, "0.0.1", lockDep.Version)
+	assert.Equal(t, "v0.1.0", currentVersion(&lockDep))
+
+	_, outdated := newOutdatedDep("flask_manifests", "git", currentVersion(&lockDep), "", []string{"v0.1.0"})
+	assert.False(t, outdated)
+	outdatedDep, outdated := newOutdatedDep("flask_manifests", "git", currentVersion(&lockDep), "", []string{"v0.1.0", "v0.2.0"})
+	assert.True(t, outdated)
+	assert.Equal(t, OutdatedDep{Name: "flask_manifests", Source: "git", Current: "v0.1.0", LatestCompatible: "v0.2.0", Latest: "v0.2.0"}, outdatedDep)
+
+	ociDep := &pkg.Dependency{Name: "k8s", Version: "1.28.0", Source: downloader.Source{Oci: &downloader.Oci{Tag: "1.28.0"}}}
+	assert.Equal(t, "1.28.0", currentVersion(ociDep))
+}
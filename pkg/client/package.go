@@ -1,6 +1,10 @@
 package client
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"kcl-lang.io/kpm/pkg/env"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
@@ -45,3 +49,56 @@ func (c *KpmClient) Package(kclPkg *pkg.KclPkg, tarPath string, vendorMode bool)
 	}
 	return nil
 }
+
+// PackageFiles returns the files to be packaged into 'tarPath' by 'Package',
+// the files are the slash-separated paths relative to the package root sorted in the order of the tar.
+func (c *KpmClient) PackageFiles(kclPkg *pkg.KclPkg, tarPath string) ([]string, error) {
+	files, err := utils.ListPackageFiles(kclPkg.HomePath, tarPath, kclPkg.GetPkgInclude(), kclPkg.GetPkgExclude())
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedPackage, err, "failed to list the files of the kcl module")
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.Info.IsDir() {
+			names = append(names, file.Name)
+		}
+	}
+	return names, nil
+}
+
+// VerifyReproducible will package the kcl package twice and compare the digests of the two "*.tar" files,
+// and return the digest if the package is reproducible.
+func (c *KpmClient) VerifyReproducible(kclPkg *pkg.KclPkg, vendorMode bool) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return "", reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to create temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var digests []string
+	for _, build := range []string{"first", "second"} {
+		buildDir := filepath.Join(tmpDir, build)
+		if err := os.MkdirAll(buildDir, 0755); err != nil {
+			return "", reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to create temp dir")
+		}
+		tarPath := filepath.Join(buildDir, kclPkg.GetPkgTarName())
+		if err := c.Package(kclPkg, tarPath, vendorMode); err != nil {
+			return "", err
+		}
+		digest, err := utils.FileDigest(tarPath)
+		if err != nil {
+			return "", reporter.NewErrorEvent(reporter.FailedPackage, err, fmt.Sprintf("failed to compute the digest of '%s'", tarPath))
+		}
+		digests = append(digests, digest)
+	}
+
+	if digests[0] != digests[1] {
+		return "", reporter.NewErrorEvent(
+			reporter.PackageNotReproducible,
+			fmt.Errorf("the package '%s' is not reproducible, the digests of the two builds are '%s' and '%s'", kclPkg.GetPkgName(), digests[0], digests[1]),
+			"check whether the files in the package are changed during packaging",
+		)
+	}
+	return digests[0], nil
+}
@@ -2,7 +2,10 @@ package client
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
@@ -78,3 +81,37 @@ func TestPackage(t *testing.T) {
 	// Clean up after test
 	_ = os.Remove(tarPath)
 }
+
+// TestVerifyReproducible verifies that packaging the same package twice yields the same digest.
+func TestVerifyReproducible(t *testing.T) {
+	client := &KpmClient{}
+
+	tempDir := t.TempDir()
+	kclPkg := pkg.NewKclPkg(&opt.InitOptions{
+		InitPath: tempDir,
+	})
+	if err := os.WriteFile(filepath.Join(tempDir, "main.k"), []byte("a = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := client.VerifyReproducible(&kclPkg, false)
+	if err != nil {
+		t.Fatalf("VerifyReproducible failed: %v", err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("Expected a sha256 digest but got %s", digest)
+	}
+
+	// The digest does not depend on the mtime of the files.
+	mtime := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(tempDir, "main.k"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	newDigest, err := client.VerifyReproducible(&kclPkg, false)
+	if err != nil {
+		t.Fatalf("VerifyReproducible failed: %v", err)
+	}
+	if newDigest != digest {
+		t.Errorf("Expected digest %s but got %s", digest, newDigest)
+	}
+}
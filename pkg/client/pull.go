@@ -110,6 +110,11 @@ func (c *KpmClient) Pull(options ...PullOption) (*pkg.KclPkg, error) {
 	pkgSource := opts.Source
 	pulledFullPath := filepath.Join(opts.LocalPath, sourceFilePath)
 
+	// The package is refused before it is pulled if its signature is not trusted.
+	if err := c.verifySourceSignature(pkgSource); err != nil {
+		return nil, err
+	}
+
 	err = newVisitor(*pkgSource, c).Visit(pkgSource, func(kPkg *pkg.KclPkg) error {
 		if !utils.DirExists(filepath.Dir(pulledFullPath)) {
 			err := os.MkdirAll(filepath.Dir(pulledFullPath), os.ModePerm)
@@ -1,8 +1,10 @@
 package client
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"os"
+	"strings"
 
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/errors"
@@ -10,6 +12,7 @@ import (
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/signature"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -25,6 +28,9 @@ type PushOptions struct {
 	ModPath    string
 	VendorMode bool
 	Force      bool
+	// SigningKey is the path of the PEM encoded ed25519 private key to sign the pushed package,
+	// the package is not signed if it is empty.
+	SigningKey string
 }
 
 type PushOption func(*PushOptions) error
@@ -64,6 +70,14 @@ func WithPushForce(allowForce bool) PushOption {
 	}
 }
 
+// WithPushSigningKey sets the path of the private key to sign the pushed package for the Push method.
+func WithPushSigningKey(keyPath string) PushOption {
+	return func(opts *PushOptions) error {
+		opts.SigningKey = keyPath
+		return nil
+	}
+}
+
 // fillDefaultPushOptions will fill the default values for the PushOptions.
 func (c *KpmClient) fillDefaultPushOptions(ociOpt *opt.OciOptions, kMod *pkg.KclPkg) {
 	if ociOpt.Reg == "" {
@@ -81,6 +95,10 @@ func (c *KpmClient) fillDefaultPushOptions(ociOpt *opt.OciOptions, kMod *pkg.Kcl
 
 // Push will push a kcl package to a registry.
 func (c *KpmClient) Push(opts ...PushOption) error {
+	if err := c.checkOnline("pushing the package"); err != nil {
+		return err
+	}
+
 	pushOpts := &PushOptions{}
 	for _, opt := range opts {
 		if err := opt(pushOpts); err != nil {
@@ -97,6 +115,15 @@ func (c *KpmClient) Push(opts ...PushOption) error {
 		return err
 	}
 
+	// The local path replacements only work on the local machine, the module with them can not be pushed.
+	if localReplaces := kMod.ModFile.Replaces.LocalReplaces(); len(localReplaces) != 0 {
+		return reporter.NewErrorEvent(
+			reporter.FailedPush,
+			fmt.Errorf("dependencies '%s' are replaced by local paths", strings.Join(localReplaces, "', '")),
+			"remove the local path replacements in the '[replace]' section of kcl.mod before pushing",
+		)
+	}
+
 	source := pushOpts.Source
 	ociUrl, err := source.ToString()
 	if err != nil {
@@ -164,6 +191,15 @@ func (c *KpmClient) pushToOci(localPath string, ociOpts *opt.OciOptions, pushOpt
 
 	ociCli.SetLogWriter(c.logWriter)
 
+	// The signing key is loaded before pushing, so an invalid key does not leave an unsigned package in the registry.
+	var signingKey ed25519.PrivateKey
+	if pushOpts.SigningKey != "" {
+		signingKey, err = signature.LoadPrivateKey(pushOpts.SigningKey)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedSign, err, fmt.Sprintf("failed to load the signing key '%s'", pushOpts.SigningKey))
+		}
+	}
+
 	exist, err := ociCli.ContainsTag(ociOpts.Tag)
 	if err != (*reporter.KpmEvent)(nil) {
 		return err
@@ -183,5 +219,6 @@ func (c *KpmClient) pushToOci(localPath string, ociOpts *opt.OciOptions, pushOpt
 
 	return ociCli.PushWithOciManifest(localPath, ociOpts.Tag, &opt.OciManifestOptions{
 		Annotations: ociOpts.Annotations,
+		SigningKey:  signingKey,
 	})
 }
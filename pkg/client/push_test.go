@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,9 +20,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/mock"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/signature"
 )
 
 // pushWithForce - helper function for push operations with force parameter
@@ -219,3 +224,177 @@ func TestPushForceOption(t *testing.T) {
 		})
 	}
 }
+
+func TestPushWithLocalReplace(t *testing.T) {
+	kpmcli, err := NewKpmClient()
+	assert.NoError(t, err)
+
+	err = kpmcli.Push(
+		WithPushModPath(filepath.Join(getTestDir("test_update_with_replace"), "pkg")),
+		WithPushSource(downloader.Source{
+			Oci: &downloader.Oci{
+				Reg:  "localhost:5001",
+				Repo: "test/pkg",
+				Tag:  "0.0.1",
+			},
+		}),
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dependencies 'helloworld' are replaced by local paths")
+}
+
+func TestPushWithInvalidSigningKey(t *testing.T) {
+	kpmcli, err := NewKpmClient()
+	assert.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "kpm.key")
+	err = os.WriteFile(keyPath, []byte("not a key"), 0600)
+	assert.NoError(t, err)
+
+	err = kpmcli.Push(
+		WithPushModPath(filepath.Join(getTestDir("test_push"), "push_0")),
+		WithPushSigningKey(keyPath),
+		WithPushSource(downloader.Source{
+			Oci: &downloader.Oci{
+				Reg:  "localhost:5001",
+				Repo: "test/push_0",
+				Tag:  "0.0.1",
+			},
+		}),
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load the signing key")
+}
+
+func TestPushSignedAndVerify(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Skipping test on Windows")
+		}
+		enableLocalRegistryPlainHTTP(t, kpmcli)
+
+		err := mock.StartDockerRegistry()
+		if err != nil {
+			t.Errorf("Error starting docker registry: %v", err)
+		}
+
+		defer func() {
+			err = mock.CleanTestEnv()
+			if err != nil {
+				t.Errorf("Error stopping docker registry: %v", err)
+			}
+		}()
+		waitForRegistry(t, "localhost:5002")
+
+		kpmcli.SetInsecureSkipTLSverify(true)
+		err = kpmcli.LoginOci("localhost:5002", "test", "1234")
+		if err != nil {
+			t.Errorf("Error logging in to docker registry: %v", err)
+		}
+
+		// Generate the signing key and another key not signing the package.
+		keyDir := t.TempDir()
+		for _, name := range []string{"kpm", "other"} {
+			privateKey, publicKey, err := signature.GenerateKey()
+			assert.NoError(t, err)
+			assert.NoError(t, os.WriteFile(filepath.Join(keyDir, name+".key"), privateKey, 0600))
+			assert.NoError(t, os.WriteFile(filepath.Join(keyDir, name+".pub"), publicKey, 0644))
+		}
+
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		pushedModPath := filepath.Join(getTestDir("test_push"), "push_0")
+		for _, repo := range []string{"test/signed", "test/unsigned"} {
+			opts := []PushOption{
+				WithPushModPath(pushedModPath),
+				WithPushForce(true),
+				WithPushSource(downloader.Source{
+					Oci: &downloader.Oci{
+						Reg:  "localhost:5002",
+						Repo: repo,
+					},
+				}),
+			}
+			if repo == "test/signed" {
+				opts = append(opts, WithPushSigningKey(filepath.Join(keyDir, "kpm.key")))
+			}
+			err = kpmcli.Push(opts...)
+			if err != (*reporter.KpmEvent)(nil) {
+				t.Errorf("Error pushing to '%s': %v", repo, err)
+			}
+		}
+		assert.Contains(t, buf.String(), "signed [registry] localhost:5002/test/signed with key sha256:")
+
+		conf := kpmcli.GetSettings().Conf
+		t.Cleanup(func() {
+			kpmcli.GetSettings().Conf = conf
+		})
+		trust := func(source, key string) {
+			kpmcli.GetSettings().Conf.Trust = []settings.TrustRule{
+				{Source: source, Keys: []string{filepath.Join(keyDir, key)}},
+			}
+		}
+		pull := func(repo string) error {
+			_, err := kpmcli.Pull(
+				WithLocalPath(t.TempDir()),
+				WithPullSourceUrl("oci://localhost:5002/"+repo+"?tag=0.0.1"),
+			)
+			return err
+		}
+
+		// The signed package is pulled by the trusted key.
+		trust("localhost:5002/test/signed", "kpm.pub")
+		assert.NoError(t, pull("test/signed"))
+		// The packages matching no trust rules are not verified.
+		assert.NoError(t, pull("test/unsigned"))
+
+		// The package is refused if it is not signed by the trusted keys.
+		trust("localhost:5002/test/*", "other.pub")
+		err = pull("test/signed")
+		assert.ErrorIs(t, err, kpmerrors.SignatureVerificationError)
+		trust("localhost:5002/test/*", "kpm.pub")
+		err = pull("test/unsigned")
+		assert.ErrorIs(t, err, kpmerrors.SignatureVerificationError)
+
+		// The source is pinned to the digest of the manifest verified before the package is downloaded.
+		source, err := downloader.NewSourceFromStr("oci://localhost:5002/test/signed?tag=0.0.1")
+		assert.NoError(t, err)
+		assert.NoError(t, kpmcli.verifySourceSignature(source))
+		assert.Regexp(t, "^sha256:[0-9a-f]{64}$", source.Oci.Digest)
+		// The signature verified is recorded to verify the source pinned to the digest offline,
+		// the source not pinned is refused offline.
+		assert.NoError(t, kpmcli.checkSourceSignature(source, true))
+		unpinned, err := downloader.NewSourceFromStr("oci://localhost:5002/test/signed?tag=0.0.1")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, kpmcli.checkSourceSignature(unpinned, true), kpmerrors.SignatureVerificationError)
+		// The manifest is resolved by the digest instead of the tag if the source is pinned.
+		source.Oci.Digest = "sha256:" + strings.Repeat("0", 64)
+		assert.Error(t, kpmcli.verifySourceSignature(source))
+		assert.ErrorIs(t, kpmcli.checkSourceSignature(source, true), kpmerrors.SignatureVerificationError)
+
+		// The dependencies are verified when updating the package.
+		testMod := filepath.Join(t.TempDir(), "test_signed_mod")
+		err = kpmcli.Init(WithInitModPath(testMod))
+		assert.NoError(t, err)
+		kMod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(testMod))
+		assert.NoError(t, err)
+		err = kpmcli.Add(
+			WithAddKclPkg(kMod),
+			WithAddSourceUrl("oci://localhost:5002/test/unsigned"),
+			WithAddModSpec(&downloader.ModSpec{Name: "push_0", Version: "0.0.1"}),
+		)
+		assert.ErrorIs(t, err, kpmerrors.SignatureVerificationError)
+		err = kpmcli.Add(
+			WithAddKclPkg(kMod),
+			WithAddSourceUrl("oci://localhost:5002/test/signed"),
+			WithAddModSpec(&downloader.ModSpec{Name: "push_0", Version: "0.0.1"}),
+		)
+		assert.NoError(t, err)
+
+		// The package is not run if it is not signed by the trusted keys.
+		_, err = kpmcli.Run(WithRunSourceUrl("oci://localhost:5002/test/unsigned?tag=0.0.1"))
+		assert.ErrorIs(t, err, kpmerrors.SignatureVerificationError)
+	}
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestPushSignedAndVerify", TestFunc: testFunc}})
+}
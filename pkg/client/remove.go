@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"os"
+
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/resolver"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// RemoveOptions is the options for removing dependencies from a kcl package.
+type RemoveOptions struct {
+	// KclPkg is the kcl package to remove the dependencies from.
+	KclPkg *pkg.KclPkg
+	// DepNames is the names of the dependencies to be removed.
+	// The name is the key of the dependency in kcl.mod.
+	DepNames []string
+}
+
+type RemoveOption func(*RemoveOptions) error
+
+// WithRemoveKclPkg sets the kcl package to remove the dependencies from.
+func WithRemoveKclPkg(kclPkg *pkg.KclPkg) RemoveOption {
+	return func(opts *RemoveOptions) error {
+		if kclPkg == nil {
+			return fmt.Errorf("kclPkg cannot be nil")
+		}
+		opts.KclPkg = kclPkg
+		return nil
+	}
+}
+
+// WithRemoveDepNames sets the names of the dependencies to be removed.
+func WithRemoveDepNames(depNames ...string) RemoveOption {
+	return func(opts *RemoveOptions) error {
+		opts.DepNames = append(opts.DepNames, depNames...)
+		return nil
+	}
+}
+
+// Remove removes the dependencies from kcl.mod, re-resolves the dependency graph
+// to prune the dependencies that are no longer reachable from kcl.mod.lock,
+// and cleans the pruned dependencies from the vendor directory in the vendor mode.
+func (c *KpmClient) Remove(options ...RemoveOption) error {
+	opts := &RemoveOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return err
+		}
+	}
+
+	kMod := opts.KclPkg
+	if kMod == nil {
+		return fmt.Errorf("kcl package is nil")
+	}
+
+	if len(opts.DepNames) == 0 {
+		return reporter.NewErrorEvent(reporter.InvalidCmd, fmt.Errorf("at least one dependency name is required"))
+	}
+
+	modDeps := kMod.ModFile.Dependencies.Deps
+	if modDeps == nil {
+		return fmt.Errorf("kcl.mod dependencies is nil")
+	}
+	lockDeps := kMod.Dependencies.Deps
+	if lockDeps == nil {
+		return fmt.Errorf("kcl.mod.lock dependencies is nil")
+	}
+
+	// Make sure all the dependencies exist before removing any of them.
+	for _, depName := range opts.DepNames {
+		if _, ok := modDeps.Get(depName); !ok {
+			return reporter.NewErrorEvent(
+				reporter.DependencyNotFound,
+				fmt.Errorf("dependency '%s' not found in '%s'", depName, kMod.ModFile.GetModFilePath()),
+			)
+		}
+	}
+
+	for _, depName := range opts.DepNames {
+		reporter.ReportEventTo(
+			reporter.NewEvent(reporter.RemoveDep, fmt.Sprintf("removing dependency '%s'", depName)),
+			c.logWriter,
+		)
+		modDeps.Delete(depName)
+	}
+
+	// Collect the dependencies that are still reachable from kcl.mod.
+	reachable := make(map[string]struct{})
+	depResolver := resolver.DepsResolver{
+		DefaultCachePath:      c.homePath,
+		InsecureSkipTLSverify: c.insecureSkipTLSverify,
+		Downloader:            c.DepDownloader,
+		Settings:              &c.settings,
+		LogWriter:             c.logWriter,
+		Jobs:                  c.jobs,
+		Cache:                 c.Cache(),
+	}
+	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs, func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+		reachable[dep.Name] = struct{}{}
+		return nil
+	})
+
+	err := depResolver.Resolve(
+		resolver.WithResolveKclMod(kMod),
+		resolver.WithEnableCache(true),
+		resolver.WithCachePath(c.homePath),
+		resolver.WithOffline(c.offline),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Prune the dependencies which are not reachable from kcl.mod.lock.
+	var pruned []pkg.Dependency
+	for _, depName := range lockDeps.Keys() {
+		if _, ok := reachable[depName]; ok {
+			continue
+		}
+		dep, ok := lockDeps.Get(depName)
+		if !ok {
+			return fmt.Errorf("failed to get dependency %s", depName)
+		}
+		pruned = append(pruned, dep)
+		lockDeps.Delete(depName)
+	}
+
+	// Clean the pruned dependencies from the vendor directory.
+	if kMod.IsVendorMode() {
+		for _, dep := range pruned {
+			if dep.IsFromLocal() {
+				continue
+			}
+			vendorFullPath := c.getDepStorePath(kMod.HomePath, &dep, true)
+			if utils.DirExists(vendorFullPath) {
+				err := os.RemoveAll(vendorFullPath)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	err = kMod.UpdateModAndLockFile()
+	if err != nil {
+		return err
+	}
+
+	for _, depName := range opts.DepNames {
+		reporter.ReportMsgTo(fmt.Sprintf("remove dependency '%s' successfully", depName), c.logWriter)
+	}
+
+	return nil
+}
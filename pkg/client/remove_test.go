@@ -0,0 +1,89 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+func TestRemove(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestRemoveWithIndirectDeps", TestFunc: testRemoveWithIndirectDeps}})
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestRemoveNotFound", TestFunc: testRemoveNotFound}})
+}
+
+func testRemoveWithIndirectDeps(t *testing.T, kpmcli *KpmClient) {
+	pkgPath := filepath.Join(getTestDir("test_remove"), "pkg")
+
+	modPath := filepath.Join(pkgPath, "kcl.mod")
+	lockPath := filepath.Join(pkgPath, "kcl.mod.lock")
+
+	if err := copy.Copy(filepath.Join(pkgPath, "kcl.mod.bk"), modPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := copy.Copy(filepath.Join(pkgPath, "kcl.mod.lock.bk"), lockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		_ = os.Remove(modPath)
+		_ = os.Remove(lockPath)
+	}()
+
+	kpkg, err := kpmcli.LoadPkgFromPath(pkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = kpmcli.Remove(
+		WithRemoveKclPkg(kpkg),
+		WithRemoveDepNames("dep1"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMod, err := os.ReadFile(filepath.Join(pkgPath, "kcl.mod.expect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLock, err := os.ReadFile(filepath.Join(pkgPath, "kcl.mod.lock.expect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotMod, err := os.ReadFile(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotLock, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, utils.RmNewline(string(expectedMod)), utils.RmNewline(string(gotMod)))
+	assert.Equal(t, utils.RmNewline(string(expectedLock)), utils.RmNewline(string(gotLock)))
+}
+
+func testRemoveNotFound(t *testing.T, kpmcli *KpmClient) {
+	pkgPath := filepath.Join(getTestDir("test_remove"), "dep1")
+
+	kpkg, err := kpmcli.LoadPkgFromPath(pkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = kpmcli.Remove(
+		WithRemoveKclPkg(kpkg),
+		WithRemoveDepNames("not_exist"),
+	)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "dependency 'not_exist' not found")
+	}
+
+	// kcl.mod should not be changed if the dependency is not found.
+	_, ok := kpkg.ModFile.Deps.Get("dep2")
+	assert.True(t, ok)
+}
@@ -82,6 +82,8 @@ import (
 type RunOptions struct {
 	settingYamlFiles []string
 	vendor           bool
+	// profile is the name of the profile in kcl.mod, the default profile is used if it is empty.
+	profile string
 	// Sources is the sources of the package.
 	// It can be a local *.k path, a local *.tar/*.tgz path, a local directory, a remote git/oci path,.
 	Sources []*downloader.Source
@@ -121,6 +123,15 @@ func WithRunOptions(runOpts *RunOptions) RunOption {
 	}
 }
 
+// WithRunProfile selects the profile in kcl.mod for running the kcl package, e.g. 'prod' for '[profile.prod]'.
+// The named profile inherits the options it does not set from the default profile '[profile]'.
+func WithRunProfile(profile string) RunOption {
+	return func(ro *RunOptions) error {
+		ro.profile = profile
+		return nil
+	}
+}
+
 func WithLogger(l io.Writer) RunOption {
 	return func(ro *RunOptions) error {
 		if ro.Option == nil {
@@ -390,10 +401,14 @@ func (o *RunOptions) getCompileOptionsFromYaml(workdir string) *kcl.Option {
 	return resOpts
 }
 
-// applyCompileOptionsFromKclMod applies the compile options from the kcl.mod file.
-func getCompileOptionsFromKclMod(kclPkg *pkg.KclPkg) *kcl.Option {
+// applyCompileOptionsFromKclMod applies the compile options from the profile in the kcl.mod file.
+func getCompileOptionsFromKclMod(kclPkg *pkg.KclPkg, profile string) (*kcl.Option, error) {
+	profileOpts, err := kclPkg.GetKclOptsByProfile(profile)
+	if err != nil {
+		return nil, err
+	}
 	resOpts := kcl.NewOption()
-	resOpts.Merge(*kclPkg.GetKclOpts())
+	resOpts.Merge(*profileOpts)
 	var updatedKFilenameList []string
 	// transform the relative path to the absolute path in kcl.yaml by kcl.mod path
 	for _, kfile := range resOpts.KFilenameList {
@@ -403,7 +418,7 @@ func getCompileOptionsFromKclMod(kclPkg *pkg.KclPkg) *kcl.Option {
 		updatedKFilenameList = append(updatedKFilenameList, kfile)
 	}
 	resOpts.KFilenameList = updatedKFilenameList
-	return resOpts
+	return resOpts, nil
 }
 
 // applyCompileOptions applies the compile options from cli, kcl.yaml and kcl.mod.
@@ -462,7 +477,10 @@ func (o *RunOptions) applyCompileOptions(source downloader.Source, kclPkg *pkg.K
 
 	cliOpts := o.Option
 	// Get the compile options from kcl.mod
-	modOpts := getCompileOptionsFromKclMod(kclPkg)
+	modOpts, err := getCompileOptionsFromKclMod(kclPkg, o.profile)
+	if err != nil {
+		return err
+	}
 
 	// Get the compile options from kcl.yaml
 	var yamlOpts *kcl.Option
@@ -636,6 +654,12 @@ func (c *KpmClient) Run(options ...RunOption) (*kcl.KCLResultList, error) {
 		return nil, err
 	}
 
+	// The package is not downloaded and compiled if its signature is not trusted.
+	err = c.verifySourceSignature(pkgSource)
+	if err != nil {
+		return nil, err
+	}
+
 	// Visit the root package source.
 	var res *kcl.KCLResultList
 	err = newVisitor(*pkgSource, c).Visit(pkgSource, func(kclPkg *pkg.KclPkg) error {
@@ -9,6 +9,7 @@ import (
 	"gotest.tools/v3/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/features"
+	"kcl-lang.io/kpm/pkg/opt"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -105,6 +106,62 @@ func TestRun(t *testing.T) {
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestRunOciWithSettingsFile", TestFunc: testRunOciWithSettingsFile}})
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestRunWithModSpecVersion", TestFunc: testRunWithModSpecVersion}})
 }
+func TestRunWithProfile(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		pkgPath := getTestDir("test_run_profile")
+
+		for profile, stdout := range map[string]string{
+			// The default profile '[profile]'.
+			"": "stdout",
+			// '[profile.prod]' sets the arguments and inherits the entries.
+			"prod": "stdout_prod",
+			// '[profile.debug]' sets the entries and inherits the arguments.
+			"debug": "stdout_debug",
+		} {
+			res, err := kpmcli.Run(
+				WithRunSourceUrl(pkgPath),
+				WithRunProfile(profile),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expect, err := os.ReadFile(filepath.Join(pkgPath, stdout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, utils.RmNewline(res.GetRawYamlResult()), utils.RmNewline(string(expect)))
+		}
+
+		_, err := kpmcli.Run(
+			WithRunSourceUrl(pkgPath),
+			WithRunProfile("staging"),
+		)
+		assert.ErrorContains(t, err, "profile 'staging' not found")
+		assert.ErrorContains(t, err, "the profiles are 'debug', 'prod'")
+
+		// The profile selected by 'kpm run --profile' is passed through the compile options.
+		opts := opt.DefaultCompileOptions()
+		opts.SetPkgPath(pkgPath)
+		opts.SetProfile("prod")
+		res, err := kpmcli.CompileWithOpts(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect, err := os.ReadFile(filepath.Join(pkgPath, "stdout_prod"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, utils.RmNewline(res.GetRawYamlResult()), utils.RmNewline(string(expect)))
+
+		opts.SetProfile("staging")
+		_, err = kpmcli.CompileWithOpts(opts)
+		assert.ErrorContains(t, err, "profile 'staging' not found")
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "testRunWithProfile", TestFunc: testFunc}})
+}
+
 func TestRunWithHyphenEntries(t *testing.T) {
 	testFunc := func(t *testing.T, kpmcli *KpmClient) {
 		pkgPath := getTestDir("test_run_hyphen_entries")
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kcl-lang.io/kcl-go/pkg/kcl"
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// SchemaOptions contains the options for getting the schema types of a kcl package.
+type SchemaOptions struct {
+	// Source is the source of the package.
+	// It can be a local directory, a local *.tar/*.tgz path, a remote git/oci path.
+	Source *downloader.Source
+	// SchemaName filters the schema types by the name, all the schema types are returned if it is empty.
+	SchemaName string
+}
+
+type SchemaOption func(*SchemaOptions) error
+
+// WithSchemaSource sets the source of the package to get the schema types from.
+func WithSchemaSource(source *downloader.Source) SchemaOption {
+	return func(opts *SchemaOptions) error {
+		if source == nil {
+			return errors.New("source cannot be nil")
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithSchemaSourceUrl sets the source url of the package to get the schema types from,
+// e.g. 'oci://ghcr.io/kcl-lang/k8s?tag=1.28', 'git://github.com/kcl-lang/konfig?tag=v0.4.0' or a local path.
+func WithSchemaSourceUrl(sourceUrl string) SchemaOption {
+	return func(opts *SchemaOptions) error {
+		source, err := downloader.NewSourceFromStr(sourceUrl)
+		if err != nil {
+			return err
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithSchemaName filters the schema types by the name.
+func WithSchemaName(name string) SchemaOption {
+	return func(opts *SchemaOptions) error {
+		opts.SchemaName = name
+		return nil
+	}
+}
+
+// SchemaType is a schema type defined in a kcl package.
+type SchemaType struct {
+	// Name is the name of the schema.
+	Name string `json:"name"`
+	// RelPath is the path of the directory where the schema is defined, relative to the package home path.
+	RelPath string `json:"rel_path"`
+	*gpyrpc.KclType
+}
+
+// PkgSchemas are the schema types of a kcl package.
+type PkgSchemas struct {
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Version is the version of the package.
+	Version string `json:"version"`
+	// Schemas are the schema types in the order of the relative paths and the names.
+	Schemas []*SchemaType `json:"schemas"`
+}
+
+// Schema returns the schema types of the kcl package from the source.
+// The dependencies of the package are resolved once for all the directories in the package.
+func (c *KpmClient) Schema(options ...SchemaOption) (*PkgSchemas, error) {
+	opts := &SchemaOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Source == nil {
+		return nil, errors.New("no source provided")
+	}
+
+	source := *opts.Source
+	if source.IsLocalPath() && !filepath.IsAbs(source.Path) {
+		absPath, err := filepath.Abs(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		source.Path = absPath
+	}
+
+	// The schema types are not extracted from the package whose signature is not trusted.
+	err := c.verifySourceSignature(&source)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *PkgSchemas
+	err = newVisitor(source, c).Visit(&source, func(kclPkg *pkg.KclPkg) error {
+		schemas, err := c.getSchemaTypes(kclPkg, opts.SchemaName)
+		if err != nil {
+			return err
+		}
+		res = &PkgSchemas{
+			Name:    kclPkg.GetPkgName(),
+			Version: kclPkg.GetPkgTag(),
+			Schemas: schemas,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SchemaInJsonStr returns the schema types of the kcl package from the source in json.
+func (c *KpmClient) SchemaInJsonStr(options ...SchemaOption) (string, error) {
+	schemas, err := c.Schema(options...)
+	if err != nil {
+		return "", err
+	}
+	jsonData, err := json.Marshal(schemas)
+	if err != nil {
+		return "", reporter.NewErrorEvent(reporter.Bug, err, "internal bug: failed to marshal the schema types into json")
+	}
+	return string(jsonData), nil
+}
+
+// getSchemaTypes returns the schema types in all the directories of the package, the hidden directories are skipped.
+func (c *KpmClient) getSchemaTypes(kclPkg *pkg.KclPkg, schemaName string) ([]*SchemaType, error) {
+	depsMap, err := c.ResolveDepsIntoMap(kclPkg)
+	if err != nil {
+		return nil, err
+	}
+	opts := kcl.NewOption()
+	for depName, depPath := range depsMap {
+		opts.Merge(kcl.WithExternalPkgs(fmt.Sprintf(constants.EXTERNAL_PKGS_ARG_PATTERN, depName, depPath)))
+	}
+
+	schemas := []*SchemaType{}
+	err = filepath.WalkDir(kclPkg.HomePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != kclPkg.HomePath && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		typeMap, err := kcl.GetFullSchemaTypeMapping([]string{path}, "", *opts)
+		if err != nil && err.Error() != kpmerrors.NoKclFiles.Error() {
+			return reporter.NewErrorEvent(reporter.CompileFailed, err, fmt.Sprintf("failed to get the schema types in '%s'", path))
+		}
+
+		relPath, err := filepath.Rel(kclPkg.HomePath, path)
+		if err != nil {
+			return err
+		}
+		for name, ty := range typeMap {
+			// The schema instances are not the schema types.
+			if ty.Type != "schema" || ty.SchemaName != name {
+				continue
+			}
+			if schemaName != "" && name != schemaName {
+				continue
+			}
+			schemas = append(schemas, &SchemaType{
+				Name:    name,
+				RelPath: filepath.ToSlash(relPath),
+				KclType: ty,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].RelPath != schemas[j].RelPath {
+			return schemas[i].RelPath < schemas[j].RelPath
+		}
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas, nil
+}
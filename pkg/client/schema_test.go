@@ -0,0 +1,58 @@
+package client
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		pkgPath := filepath.Join(getTestDir("test_schema"), "pkg")
+
+		res, err := kpmcli.Schema(WithSchemaSourceUrl(pkgPath))
+		assert.NoError(t, err)
+		assert.Equal(t, "test_schema", res.Name)
+		assert.Equal(t, "0.0.1", res.Version)
+
+		// The schema instances and the schema types in the hidden directories are not returned.
+		var names []string
+		for _, schema := range res.Schemas {
+			names = append(names, schema.RelPath+"/"+schema.Name)
+		}
+		if !assert.Equal(t, []string{"./App", "models/Service", "models/Volume"}, names) {
+			return
+		}
+		// The base schema is resolved from the dependency.
+		assert.Equal(t, "Base", res.Schemas[0].BaseSchema.SchemaName)
+		assert.Contains(t, res.Schemas[0].Properties, "name")
+		assert.Contains(t, res.Schemas[0].Properties, "replicas")
+
+		res, err = kpmcli.Schema(WithSchemaSourceUrl(pkgPath), WithSchemaName("Service"))
+		assert.NoError(t, err)
+		if !assert.Equal(t, 1, len(res.Schemas)) {
+			return
+		}
+		assert.Equal(t, "models", res.Schemas[0].RelPath)
+		assert.Equal(t, "Service", res.Schemas[0].Name)
+
+		jsonStr, err := kpmcli.SchemaInJsonStr(WithSchemaSourceUrl(pkgPath), WithSchemaName("Volume"))
+		assert.NoError(t, err)
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(jsonStr), &decoded))
+		assert.Equal(t, "test_schema", decoded["name"])
+		schemas := decoded["schemas"].([]interface{})
+		if !assert.Equal(t, 1, len(schemas)) {
+			return
+		}
+		assert.Equal(t, "Volume", schemas[0].(map[string]interface{})["name"])
+		assert.Equal(t, "models", schemas[0].(map[string]interface{})["rel_path"])
+
+		_, err = kpmcli.Schema()
+		assert.Error(t, err)
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestSchema", TestFunc: testFunc}})
+}
@@ -0,0 +1,7 @@
+[package]
+name = "dep3"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+dep2 = { path = "../dep2" }
@@ -0,0 +1,8 @@
+[package]
+name = "pkg"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+dep1 = { path = "../dep1" }
+dep3 = { path = "../dep3" }
@@ -0,0 +1,4 @@
+[package]
+name = "dep3"
+edition = "v0.12.3"
+version = "0.0.1"
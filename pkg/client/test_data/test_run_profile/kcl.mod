@@ -0,0 +1,14 @@
+[package]
+name = "test_run_profile"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[profile]
+entries = ["main.k"]
+arguments = ["env=dev", "replicas=1"]
+
+[profile.prod]
+arguments = ["env=prod", "replicas=3"]
+
+[profile.debug]
+entries = ["main.k", "debug.k"]
@@ -0,0 +1,4 @@
+[package]
+name = "base"
+edition = "v0.9.0"
+version = "0.0.1"
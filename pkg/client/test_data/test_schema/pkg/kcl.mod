@@ -0,0 +1,7 @@
+[package]
+name = "test_schema"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[dependencies]
+base = { path = "../base" }
@@ -0,0 +1,4 @@
+[package]
+name = "helloworld"
+edition = "v0.12.3"
+version = "0.1.5"
@@ -0,0 +1,10 @@
+[package]
+name = "pkg"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+helloworld = "0.1.4"
+
+[replace]
+helloworld = { path = "../helloworld" }
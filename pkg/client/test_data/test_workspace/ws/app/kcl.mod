@@ -0,0 +1,8 @@
+[package]
+name = "app"
+edition = "v0.10.0"
+version = "0.0.1"
+
+[dependencies]
+base = "0.0.1"
+helloworld = "0.1.1"
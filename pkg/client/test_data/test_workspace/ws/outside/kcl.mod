@@ -0,0 +1,7 @@
+[package]
+name = "outside"
+edition = "v0.10.0"
+version = "0.0.1"
+
+[dependencies]
+helloworld = "0.1.0"
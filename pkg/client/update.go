@@ -2,18 +2,25 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"kcl-lang.io/kpm/pkg/checker"
 	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/features"
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/resolver"
+	"kcl-lang.io/kpm/pkg/semver"
+	"kcl-lang.io/kpm/pkg/signature"
+	"kcl-lang.io/kpm/pkg/sumdb"
 	"kcl-lang.io/kpm/pkg/utils"
 	"oras.land/oras-go/v2"
 )
@@ -24,6 +31,7 @@ import (
 type UpdateOptions struct {
 	kpkg          *pkg.KclPkg
 	offline       bool
+	skipMissing   bool
 	updateModFile bool
 }
 
@@ -45,6 +53,15 @@ func WithOffline(offline bool) UpdateOption {
 	}
 }
 
+// withSkipMissing sets the flag to skip the dependencies not found in the cache in offline mode,
+// which is used to resolve the metadata of the existing dependencies only.
+func withSkipMissing(skipMissing bool) UpdateOption {
+	return func(opts *UpdateOptions) error {
+		opts.skipMissing = skipMissing
+		return nil
+	}
+}
+
 // WithUpdatedKclPkg sets the kcl package to be updated.
 func WithUpdatedKclPkg(kpkg *pkg.KclPkg) UpdateOption {
 	return func(opts *UpdateOptions) error {
@@ -65,13 +82,16 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 	if kMod == nil {
 		return nil, fmt.Errorf("kcl package is nil")
 	}
+	// The client in offline mode updates the package offline.
+	opts.offline = opts.offline || c.offline
 
-	if ok, err := features.Enabled(features.SupportModCheck); err == nil && ok && c.noSumCheck {
+	// The checksums are checked against the trusted source, which is not available in offline mode.
+	if ok, err := features.Enabled(features.SupportModCheck); err == nil && ok && c.noSumCheck && !opts.offline {
 		c.ModChecker = checker.NewModChecker(
 			checker.WithCheckers(
 				checker.NewIdentChecker(),
 				checker.NewVersionChecker(),
-				checker.NewSumChecker(),
+				checker.NewSumChecker(checker.WithSumDBOpener(c.sumDB)),
 			),
 		)
 
@@ -85,6 +105,15 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 
 	kMod.NoSumCheck = c.noSumCheck
 
+	// The members of a workspace are updated together and share the lock file of the workspace.
+	ws, err := c.FindWorkspace(kMod.HomePath)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		return c.updateWorkspaceMember(ws, kMod, opts)
+	}
+
 	modDeps := kMod.ModFile.Dependencies.Deps
 	if modDeps == nil {
 		return nil, fmt.Errorf("kcl.mod dependencies is nil")
@@ -101,12 +130,24 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 		Downloader:            c.DepDownloader,
 		Settings:              &c.settings,
 		LogWriter:             c.logWriter,
+		Jobs:                  c.jobs,
+		Cache:                 c.Cache(),
+		CheckSource:           c.checkSourceSignature,
 	}
 	// ResolveFunc is the function for resolving each dependency when traversing the dependency graph.
 	resolverFunc := func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+		// The dependency replaced by the '[replace]' section is locked with the replacement and without checksum,
+		// and the requirement in kcl.mod is kept.
+		if dep.Replace != "" {
+			dep.Sum = ""
+			kMod.Dependencies.Deps.Set(dep.Name, *dep)
+			return nil
+		}
+
 		selectedModDep := dep
 		// Check if the dependency exists in the mod file.
-		if existDep, exist := modDeps.Get(dep.Name); exist {
+		// The version constraint in the mod file is kept and the selected version is pinned in the lock file.
+		if existDep, exist := modDeps.Get(dep.Name); exist && !semver.IsConstraint(existDep.Version) {
 			if ok, err := features.Enabled(features.SupportMVS); err == nil && ok {
 				// if the dependency exists in the mod file,
 				// check the version and select the greater one.
@@ -122,26 +163,33 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 
 		selectedDep := dep
 		// Check if the dependency exists in the lock file.
-		if existDep, exist := lockDeps.Get(dep.Name); exist {
+		// The dependency locked with a replacement is not reused after the replacement is removed.
+		if existDep, exist := lockDeps.Get(dep.Name); exist && existDep.Replace == "" {
 			if ok, err := features.Enabled(features.SupportMVS); err == nil && ok {
 				// If the dependency exists in the lock file,
-				// check the version and select the greater one.
-				if less, err := dep.VersionLessThan(&existDep); less && err == nil {
+				// check the version and select the greater one satisfying the version constraints.
+				if less, err := dep.VersionLessThan(&existDep); less && err == nil &&
+					depResolver.CheckVersionRequirements(dep.Name, existDep.Version) == nil {
 					selectedDep = &existDep
 				}
 			}
 		}
 
 		// Check if the checksum of the dependency exists in the lock file.
-		if existDep, exist := lockDeps.Get(dep.Name); exist {
+		if existDep, exist := lockDeps.Get(dep.Name); exist && existDep.Replace == "" {
 			if equal, err := existDep.VersionEqual(selectedDep); equal && err == nil {
 				selectedDep.Sum = existDep.Sum
 			}
 		}
 
 		selectedDep.LocalFullPath = dep.LocalFullPath
-		if selectedDep.Sum == "" {
-			if !env.SkipChecksumCheck(selectedDep.Name) {
+		// The checksum is acquired from the registry, which is not available in offline mode.
+		if selectedDep.Sum == "" && !env.SkipChecksumCheck(selectedDep.Name) {
+			if opts.offline {
+				if !opts.skipMissing && !c.noSumCheck && selectedDep.Source.Oci != nil {
+					return newSumNotLockedOfflineError(selectedDep)
+				}
+			} else {
 				sum, err := c.AcquireDepSum(*selectedDep)
 				if err != nil {
 					return err
@@ -153,31 +201,56 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 		}
 		kMod.Dependencies.Deps.Set(dep.Name, *selectedDep)
 
+		// Store the remote dependency by its checksum to restore it without downloading next time.
+		// The dependency not matching the checksum is not stored and the errors are ignored.
+		if dep.Source.IsRemote() && selectedDep.Sum != "" && utils.DirExists(selectedDep.LocalFullPath) {
+			_ = depResolver.Cache.Put(selectedDep.Sum, selectedDep.LocalFullPath)
+		}
+
 		return nil
 	}
 	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs, resolverFunc)
 
-	err := depResolver.Resolve(
+	err = depResolver.Resolve(
 		resolver.WithResolveKclMod(kMod),
 		resolver.WithEnableCache(true),
 		resolver.WithCachePath(c.homePath),
 		resolver.WithOffline(opts.offline),
+		resolver.WithSkipMissing(opts.skipMissing),
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if opts.updateModFile && utils.DirExists(filepath.Join(kMod.HomePath, constants.KCL_MOD)) {
-		err = kMod.UpdateModFile()
-		if err != nil {
-			return nil, err
+	// Check the selected versions against the version constraints on them,
+	// a greater version may be selected by the other packages requiring the exact version.
+	var constrainedDeps []string
+	for depName := range depResolver.VersionRequirements() {
+		constrainedDeps = append(constrainedDeps, depName)
+	}
+	sort.Strings(constrainedDeps)
+	for _, depName := range constrainedDeps {
+		if lockDep, ok := lockDeps.Get(depName); ok {
+			err = depResolver.CheckVersionRequirements(depName, lockDep.Version)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// Generate file kcl.mod.lock.
-	if !kMod.NoSumCheck && utils.DirExists(filepath.Join(kMod.HomePath, constants.KCL_MOD)) {
-		err := kMod.LockDepsVersion()
+	err = c.checkSumDB(kMod, opts.offline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate file kcl.mod and kcl.mod.lock.
+	if utils.DirExists(filepath.Join(kMod.HomePath, constants.KCL_MOD)) {
+		if opts.updateModFile {
+			err = kMod.UpdateModAndLockFile()
+		} else if !kMod.NoSumCheck {
+			err = kMod.LockDepsVersion()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -186,6 +259,100 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 	return kMod, nil
 }
 
+// sumDB opens the checksum database by '$KPM_SUMDB', it returns nil if the checksum database is disabled,
+// or if it is remote and the client is in offline mode.
+func (c *KpmClient) sumDB() (*sumdb.DB, error) {
+	db, err := sumdb.Open(env.GetSumDB(), c.homePath)
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.UnknownEnv, err, fmt.Sprintf("unknown environment variable '%s=%s'", env.KPM_SUMDB, env.GetSumDB()))
+	}
+	if db != nil && db.IsRemote() && c.offline {
+		return nil, nil
+	}
+	return db, nil
+}
+
+// checkSumDB verifies the checksums of the remote dependencies of the package against the checksum database,
+// and records the checksums of the dependencies used for the first time.
+// The dependencies replaced by the '[replace]' section and from the local paths are not recorded.
+func (c *KpmClient) checkSumDB(kMod *pkg.KclPkg, offline bool) error {
+	db, err := c.sumDB()
+	if err != nil || db == nil {
+		return err
+	}
+	// The remote checksum database is not available when the package is updated offline.
+	if db.IsRemote() && offline {
+		return nil
+	}
+
+	for _, name := range kMod.Dependencies.Deps.Keys() {
+		dep, _ := kMod.Dependencies.Deps.Get(name)
+		if dep.Replace != "" || !dep.Source.IsRemote() {
+			continue
+		}
+
+		source := dep.Source
+		if source.Oci != nil {
+			ociSource := *source.Oci
+			if len(ociSource.Reg) == 0 {
+				ociSource.Reg = c.GetSettings().DefaultOciRegistry()
+			}
+			if len(ociSource.Repo) == 0 {
+				ociSource.Repo = utils.JoinPath(c.GetSettings().DefaultOciRepo(), dep.Name)
+			}
+			source.Oci = &ociSource
+		}
+		module, version, ok := sumdb.ModuleVersion(source)
+		if !ok {
+			continue
+		}
+		sum, ok, err := sumdb.DepSum(dep)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		err = db.Verify(module, version, sum)
+		if errors.Is(err, kpmerrors.CheckSumMismatchError) {
+			return reporter.NewErrorEvent(reporter.CheckSumMismatch, err, fmt.Sprintf("the checksum of '%s' is not the one recorded in the checksum database", dep.Name))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySourceSignature verifies the signature of the OCI package of the source by the trust rules in 'kpm.json'
+// before the package is downloaded, and pins the source to the digest of the manifest verified.
+// In offline mode of the client, it is verified by the signatures recorded in the kpm home path.
+func (c *KpmClient) verifySourceSignature(source *downloader.Source) error {
+	return c.checkSourceSignature(source, c.offline)
+}
+
+// checkSourceSignature verifies the signature of the OCI package of the source by the trust rules in 'kpm.json',
+// the signature verified online is recorded in the kpm home path to verify the package offline by it.
+// In offline mode, the source pinned to no digest or signed by no signature recorded is refused.
+func (c *KpmClient) checkSourceSignature(source *downloader.Source, offline bool) error {
+	return checker.NewSignatureChecker(
+		checker.WithSignatureSettings(c.settings),
+		checker.WithSignatureStore(filepath.Join(c.homePath, signature.STORE_DIR)),
+		checker.WithSignatureOffline(offline),
+	).CheckSource(source)
+}
+
+// newSumNotLockedOfflineError returns the error that the checksum of the OCI dependency is not locked
+// and can not be acquired from the registry in offline mode.
+func newSumNotLockedOfflineError(dep *pkg.Dependency) error {
+	return reporter.NewErrorEvent(
+		reporter.NotFoundOffline,
+		kpmerrors.NotFoundOffline,
+		fmt.Sprintf("the checksum of '%s' version '%s' is not locked and can not be acquired in offline mode, update the dependencies without '--offline' to lock it", dep.Name, dep.Version),
+	)
+}
+
 // AcquireDepSum will acquire the checksum of the dependency from the OCI registry.
 func (c *KpmClient) AcquireDepSum(dep pkg.Dependency) (string, error) {
 	// Only the dependencies from the OCI need can be checked.
@@ -198,6 +365,11 @@ func (c *KpmClient) AcquireDepSum(dep pkg.Dependency) (string, error) {
 			urlpath := utils.JoinPath(c.GetSettings().DefaultOciRepo(), dep.Name)
 			dep.Source.Oci.Repo = urlpath
 		}
+		// The manifest of the package downloaded is fetched by its digest if it is known.
+		ref := dep.Source.Oci.Tag
+		if len(dep.Source.Oci.Digest) != 0 {
+			ref = dep.Source.Oci.Digest
+		}
 		// Fetch the metadata of the OCI manifest.
 		manifest := ocispec.Manifest{}
 		jsonDesc, err := c.FetchOciManifestIntoJsonStr(opt.OciFetchOptions{
@@ -205,7 +377,7 @@ func (c *KpmClient) AcquireDepSum(dep pkg.Dependency) (string, error) {
 			OciOptions: opt.OciOptions{
 				Reg:  dep.Source.Oci.Reg,
 				Repo: dep.Source.Oci.Repo,
-				Tag:  dep.Source.Oci.Tag,
+				Tag:  ref,
 			},
 		})
 
@@ -1,13 +1,21 @@
 package client
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/elliotchance/orderedmap/v2"
 	"github.com/otiai10/copy"
 	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/features"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/sumdb"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -18,6 +26,7 @@ func TestUpdate(t *testing.T) {
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestUpdateDefaultRegistryDep", TestFunc: testUpdateDefaultRegistryDep}})
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestUpdateWithKclModAndLock", TestFunc: testUpdateKclModAndLock}})
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestUpdate", TestFunc: testUpdate}})
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestUpdateWithReplace", TestFunc: testUpdateWithReplace}})
 }
 
 func testUpdate(t *testing.T, kpmcli *KpmClient) {
@@ -85,3 +94,126 @@ func testUpdate(t *testing.T, kpmcli *KpmClient) {
 		assert.Equal(t, utils.RmNewline(string(expectedModLock)), utils.RmNewline(string(gotModLock)))
 	}
 }
+
+func testUpdateWithReplace(t *testing.T, kpmcli *KpmClient) {
+	tmpDir := t.TempDir()
+	err := copy.Copy(getTestDir("test_update_with_replace"), tmpDir)
+	assert.NilError(t, err)
+	pkgPath := filepath.Join(tmpDir, "pkg")
+
+	kpkg, err := kpmcli.LoadPkgFromPath(pkgPath)
+	assert.NilError(t, err)
+
+	_, err = kpmcli.Update(WithUpdatedKclPkg(kpkg), WithOffline(true))
+	assert.NilError(t, err)
+
+	// The dependency is resolved from the replacement.
+	dep, ok := kpkg.Dependencies.Deps.Get("helloworld")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, dep.Version, "0.1.5")
+	assert.Equal(t, dep.LocalFullPath, filepath.Join(tmpDir, "helloworld"))
+
+	// The replacement is recorded in kcl.mod.lock and the requirement in kcl.mod is kept.
+	gotModLock, err := os.ReadFile(filepath.Join(pkgPath, "kcl.mod.lock"))
+	assert.NilError(t, err)
+	assert.Equal(t, utils.RmNewline(string(gotModLock)), utils.RmNewline(`[dependencies]
+  [dependencies.helloworld]
+    name = "helloworld"
+    full_name = "helloworld_0.1.5"
+    version = "0.1.5"
+    replace = "../helloworld"
+    reg = "ghcr.io"
+    repo = "kcl-lang/helloworld"
+    oci_tag = "0.1.4"
+`))
+	gotMod, err := os.ReadFile(filepath.Join(pkgPath, "kcl.mod"))
+	assert.NilError(t, err)
+	expectedMod, err := os.ReadFile(filepath.Join(getTestDir("test_update_with_replace"), "pkg", "kcl.mod"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(gotMod), string(expectedMod))
+}
+
+func TestInvalidSumDB(t *testing.T) {
+	os.Setenv(env.KPM_SUMDB, "kcl.sum")
+	defer os.Unsetenv(env.KPM_SUMDB)
+
+	// The invalid checksum database is reported when the checksums are checked, not by creating the client.
+	kpmcli, err := NewKpmClient()
+	assert.NilError(t, err)
+
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	deps.Set("helloworld", pkg.Dependency{
+		Name:    "helloworld",
+		Version: "0.1.0",
+		Source: downloader.Source{
+			Local: &downloader.Local{Path: "../helloworld"},
+		},
+	})
+	kMod := &pkg.KclPkg{
+		ModFile:      pkg.ModFile{Pkg: pkg.Package{Name: "invalid_sumdb", Version: "0.0.1"}},
+		Dependencies: pkg.Dependencies{Deps: deps},
+	}
+
+	var event *reporter.KpmEvent
+	err = kpmcli.checkSumDB(kMod, false)
+	assert.Assert(t, errors.As(err, &event))
+	assert.Equal(t, event.Type(), reporter.UnknownEnv)
+	err = kpmcli.ModChecker.Check(*kMod)
+	assert.Assert(t, errors.As(err, &event))
+	assert.Equal(t, event.Type(), reporter.UnknownEnv)
+}
+
+func TestCheckSumDB(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestCheckSumDB", TestFunc: testCheckSumDB}})
+}
+
+func testCheckSumDB(t *testing.T, kpmcli *KpmClient) {
+	dbPath := filepath.Join(t.TempDir(), "kcl.sum")
+	os.Setenv(env.KPM_SUMDB, dbPath)
+	defer os.Unsetenv(env.KPM_SUMDB)
+
+	konfigPath := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(konfigPath, "main.k"), []byte("a = 1"), 0644))
+	konfigSum, err := utils.HashDir(konfigPath)
+	assert.NilError(t, err)
+
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	deps.Set("konfig", pkg.Dependency{
+		Name:          "konfig",
+		Version:       "0.4.0",
+		LocalFullPath: konfigPath,
+		Source: downloader.Source{
+			Git: &downloader.Git{
+				Url:    "https://github.com/kcl-lang/konfig.git",
+				Commit: "9b5b8a4f",
+			},
+		},
+	})
+	deps.Set("helloworld", pkg.Dependency{
+		Name:          "helloworld",
+		Version:       "0.1.0",
+		LocalFullPath: t.TempDir(),
+		Source: downloader.Source{
+			Local: &downloader.Local{Path: "../helloworld"},
+		},
+	})
+	kMod := &pkg.KclPkg{Dependencies: pkg.Dependencies{Deps: deps}}
+
+	// The git dependency without checksum in kcl.mod.lock is recorded by the checksum of its content,
+	// and the local dependency is not recorded.
+	assert.NilError(t, kpmcli.checkSumDB(kMod, false))
+	content, err := os.ReadFile(dbPath)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "github.com/kcl-lang/konfig 9b5b8a4f "+konfigSum+"\n")
+	assert.NilError(t, kpmcli.checkSumDB(kMod, false))
+
+	// The content changed for the same commit is refused.
+	assert.NilError(t, os.WriteFile(filepath.Join(konfigPath, "main.k"), []byte("a = 2"), 0644))
+	err = kpmcli.checkSumDB(kMod, false)
+	assert.Assert(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	assert.ErrorContains(t, err, "github.com/kcl-lang/konfig@9b5b8a4f")
+
+	// The checksum database is disabled by 'off'.
+	os.Setenv(env.KPM_SUMDB, sumdb.OFF)
+	assert.NilError(t, kpmcli.checkSumDB(kMod, false))
+}
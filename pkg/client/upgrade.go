@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+)
+
+// UpgradeOptions is the option for upgrading the dependencies of a package.
+// Upgrading the dependencies means pinning the dependencies to the versions in the build list selected by MVS,
+// and updating the package with the pinned dependencies.
+type UpgradeOptions struct {
+	kpkg     *pkg.KclPkg
+	versions []module.Version
+}
+
+type UpgradeOption func(*UpgradeOptions) error
+
+// WithUpgradedKclPkg sets the kcl package whose dependencies are upgraded.
+func WithUpgradedKclPkg(kpkg *pkg.KclPkg) UpgradeOption {
+	return func(opts *UpgradeOptions) error {
+		opts.kpkg = kpkg
+		return nil
+	}
+}
+
+// WithUpgradedVersions sets the build list the dependencies are upgraded to.
+func WithUpgradedVersions(versions []module.Version) UpgradeOption {
+	return func(opts *UpgradeOptions) error {
+		opts.versions = versions
+		return nil
+	}
+}
+
+// Upgrade pins the dependencies of the package in kcl.mod and kcl.mod.lock to the versions in the build list,
+// the version constraints in kcl.mod are kept, and reports the changes of the versions.
+//
+// The files are written only after the dependencies are resolved and checked, see 'pkg.KclPkg.UpdateModAndLockFile'.
+func (c *KpmClient) Upgrade(options ...UpgradeOption) (*pkg.KclPkg, error) {
+	opts := &UpgradeOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	kMod := opts.kpkg
+	if kMod == nil {
+		return nil, fmt.Errorf("kcl package is nil")
+	}
+	modDeps := kMod.ModFile.Dependencies.Deps
+	lockDeps := kMod.Dependencies.Deps
+
+	before := make(map[string]string)
+	for _, depName := range lockDeps.Keys() {
+		dep, _ := lockDeps.Get(depName)
+		before[depName] = dep.Version
+	}
+
+	target := module.Version{Path: kMod.GetPkgName(), Version: kMod.GetPkgVersion()}
+	for _, m := range opts.versions {
+		if m.Path == target.Path || m.Version == "none" {
+			continue
+		}
+		if dep, ok := modDeps.Get(m.Path); ok && !dep.IsFromLocal() && !semver.IsConstraint(dep.Version) && dep.Version != m.Version {
+			pinDepVersion(&dep, m.Version)
+			modDeps.Set(m.Path, dep)
+		}
+		if dep, ok := lockDeps.Get(m.Path); ok && !dep.IsFromLocal() && dep.Version != m.Version {
+			pinDepVersion(&dep, m.Version)
+			lockDeps.Set(m.Path, dep)
+		}
+	}
+
+	upgradedPkg, err := c.Update(WithUpdatedKclPkg(kMod))
+	if err != nil {
+		return nil, err
+	}
+
+	after := make(map[string]string)
+	for _, depName := range upgradedPkg.Dependencies.Deps.Keys() {
+		dep, _ := upgradedPkg.Dependencies.Deps.Get(depName)
+		after[depName] = dep.Version
+	}
+
+	diff := formatVersionDiff(before, after)
+	if diff == "" {
+		reporter.ReportMsgTo("all dependencies are up to date", c.logWriter)
+	} else {
+		reporter.ReportMsgTo(diff, c.logWriter)
+	}
+	return upgradedPkg, nil
+}
+
+// pinDepVersion pins the dependency to the version.
+// The sources are copied to avoid changing the other dependencies sharing them.
+func pinDepVersion(dep *pkg.Dependency, version string) {
+	if dep.Source.ModSpec != nil && dep.Source.ModSpec.Version != "" {
+		modSpec := *dep.Source.ModSpec
+		modSpec.Version = version
+		dep.Source.ModSpec = &modSpec
+	}
+	if dep.Source.Oci != nil {
+		oci := *dep.Source.Oci
+		oci.Tag = version
+		dep.Source.Oci = &oci
+	}
+	if dep.Source.Git != nil {
+		git := *dep.Source.Git
+		git.Tag = version
+		git.Commit = ""
+		git.Branch = ""
+		dep.Source.Git = &git
+	}
+	dep.Version = version
+	dep.Sum = ""
+	dep.FullName = dep.GenDepFullName()
+}
+
+// formatVersionDiff formats the changes of the dependency versions, e.g.
+//
+//   - k8s@1.28
+//   - k8s@1.31
+func formatVersionDiff(before, after map[string]string) string {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var lines []string
+	for _, name := range names {
+		oldVersion, hasOld := before[name]
+		newVersion, hasNew := after[name]
+		if hasOld && hasNew && oldVersion == newVersion {
+			continue
+		}
+		if hasOld {
+			lines = append(lines, fmt.Sprintf("- %s@%s", name, oldVersion))
+		}
+		if hasNew {
+			lines = append(lines, fmt.Sprintf("+ %s@%s", name, newVersion))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
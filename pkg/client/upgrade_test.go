@@ -0,0 +1,54 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/downloader"
+	pkg "kcl-lang.io/kpm/pkg/package"
+)
+
+func TestPinDepVersion(t *testing.T) {
+	ociSource := &downloader.Oci{Reg: "ghcr.io", Repo: "kcl-lang/k8s", Tag: "1.28"}
+	modSpec := &downloader.ModSpec{Name: "k8s", Version: "1.28"}
+	dep := pkg.Dependency{
+		Name:     "k8s",
+		FullName: "k8s_1.28",
+		Version:  "1.28",
+		Sum:      "Sum",
+		Source: downloader.Source{
+			ModSpec: modSpec,
+			Oci:     ociSource,
+		},
+	}
+	shared := dep
+
+	pinDepVersion(&dep, "1.31")
+	assert.Equal(t, "1.31", dep.Version)
+	assert.Equal(t, "k8s_1.31", dep.FullName)
+	assert.Equal(t, "", dep.Sum)
+	assert.Equal(t, "1.31", dep.Source.Oci.Tag)
+	assert.Equal(t, "1.31", dep.Source.ModSpec.Version)
+	// The sources shared with the other dependencies are not changed.
+	assert.Equal(t, "1.28", shared.Source.Oci.Tag)
+	assert.Equal(t, "1.28", shared.Source.ModSpec.Version)
+
+	gitDep := pkg.Dependency{
+		Name:    "flask",
+		Version: "main",
+		Source: downloader.Source{
+			Git: &downloader.Git{Url: "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", Branch: "main"},
+		},
+	}
+	pinDepVersion(&gitDep, "v0.2.0")
+	assert.Equal(t, "v0.2.0", gitDep.Source.Git.Tag)
+	assert.Equal(t, "", gitDep.Source.Git.Branch)
+	assert.Equal(t, "v0.2.0", gitDep.Version)
+}
+
+func TestFormatVersionDiff(t *testing.T) {
+	before := map[string]string{"k8s": "1.28", "helloworld": "0.1.0", "kcl_lib": "0.0.1"}
+	after := map[string]string{"k8s": "1.31", "helloworld": "0.1.0", "json_merge_patch": "0.1.0"}
+	assert.Equal(t, "+ json_merge_patch@0.1.0\n- k8s@1.28\n+ k8s@1.31\n- kcl_lib@0.0.1", formatVersionDiff(before, after))
+	assert.Equal(t, "", formatVersionDiff(before, before))
+}
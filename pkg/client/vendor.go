@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/hashicorp/go-version"
@@ -13,12 +15,19 @@ import (
 	"kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/features"
 	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/utils"
 	"kcl-lang.io/kpm/pkg/visitor"
 )
 
 // VendorDeps will vendor all the dependencies of the current kcl package.
 func (c *KpmClient) VendorDeps(kclPkg *pkg.KclPkg) error {
+	return c.vendorDepsWithOpts(kclPkg, &UpdateOptions{offline: c.offline})
+}
+
+// vendorDepsWithOpts vendors all the dependencies of the kcl package,
+// the dependencies of the workspace member are resolved by the options 'opts'.
+func (c *KpmClient) vendorDepsWithOpts(kclPkg *pkg.KclPkg, opts *UpdateOptions) error {
 	// Mkdir the dir "vendor".
 	vendorPath := kclPkg.LocalVendorPath()
 	err := os.MkdirAll(vendorPath, 0755)
@@ -26,6 +35,15 @@ func (c *KpmClient) VendorDeps(kclPkg *pkg.KclPkg) error {
 		return err
 	}
 
+	// The members of a workspace vendor the dependencies selected for the whole workspace.
+	ws, err := c.FindWorkspace(kclPkg.HomePath)
+	if err != nil {
+		return err
+	}
+	if ws != nil {
+		return c.vendorWorkspaceMember(ws, kclPkg, vendorPath, opts)
+	}
+
 	return c.vendorDeps(kclPkg, vendorPath)
 }
 
@@ -178,6 +196,7 @@ func (c *KpmClient) selectVendoredDeps(kpkg *pkg.KclPkg, vendorPath string, vend
 				InsecureSkipTLSverify: c.insecureSkipTLSverify,
 				EnableCache:           true,
 				CachePath:             c.homePath,
+				Offline:               c.offline,
 			}, nil
 		} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
 			return visitor.NewArchiveVisitor(pkgVisitor), nil
@@ -292,3 +311,215 @@ func (c *KpmClient) selectVendoredDeps(kpkg *pkg.KclPkg, vendorPath string, vend
 	}
 	return nil
 }
+
+// VendorOptions is the options for vendoring the dependencies of a package.
+type VendorOptions struct {
+	kclPkg *pkg.KclPkg
+	prune  bool
+}
+
+type VendorOption func(*VendorOptions) error
+
+// WithVendorKclPkg sets the kcl package whose dependencies are vendored.
+func WithVendorKclPkg(kclPkg *pkg.KclPkg) VendorOption {
+	return func(opts *VendorOptions) error {
+		opts.kclPkg = kclPkg
+		return nil
+	}
+}
+
+// WithVendorPrune sets the flag to delete the directories in the vendor directory
+// which are not the dependencies in kcl.mod.lock.
+func WithVendorPrune(prune bool) VendorOption {
+	return func(opts *VendorOptions) error {
+		opts.prune = prune
+		return nil
+	}
+}
+
+// Vendor will vendor all the dependencies of the package into the vendor directory,
+// and write the manifest 'vendor/modules.txt' listing the vendored dependencies.
+// It returns the names of the pruned directories if the prune option is set.
+func (c *KpmClient) Vendor(options ...VendorOption) ([]string, error) {
+	opts := &VendorOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	kclPkg := opts.kclPkg
+	if kclPkg == nil {
+		return nil, fmt.Errorf("kcl package is nil")
+	}
+
+	err := c.VendorDeps(kclPkg)
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedVendor, err, "failed to vendor dependencies")
+	}
+
+	var pruned []string
+	if opts.prune {
+		pruned, err = pruneVendor(kclPkg)
+		if err != nil {
+			return nil, reporter.NewErrorEvent(reporter.FailedVendor, err, "failed to prune the vendor directory")
+		}
+	}
+
+	err = writeVendorModules(kclPkg)
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedVendor, err, fmt.Sprintf("failed to write '%s'", constants.VENDOR_MODULES_TXT))
+	}
+	return pruned, nil
+}
+
+// VerifyVendor will re-hash every vendored dependency and compare the hash to the checksum in kcl.mod.lock.
+// The dependencies without checksums, e.g. the git dependencies, are not verified.
+func (c *KpmClient) VerifyVendor(kclPkg *pkg.KclPkg) error {
+	var problems []string
+	var mismatched bool
+	for _, dep := range vendoredDeps(kclPkg) {
+		vendorDir, ok := findVendorDir(kclPkg, dep)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("'%s' is not vendored", dep.Name))
+			continue
+		}
+		if dep.Sum == "" {
+			continue
+		}
+		sum, err := utils.HashDir(filepath.Join(kclPkg.LocalVendorPath(), vendorDir))
+		if err != nil {
+			return err
+		}
+		if sum != dep.Sum {
+			problems = append(problems, fmt.Sprintf("checksum mismatch for '%s' in 'vendor/%s': expected '%s', got '%s'", dep.Name, vendorDir, dep.Sum, sum))
+			mismatched = true
+		}
+	}
+
+	if len(problems) != 0 {
+		err := fmt.Errorf("%s", strings.Join(problems, "\n"))
+		if mismatched {
+			err = errors.Wrap(errors.CheckSumMismatchError, err)
+		}
+		return reporter.NewErrorEvent(
+			reporter.FailedVerifyVendor,
+			err,
+			"run 'kpm vendor' to vendor the dependencies again",
+		)
+	}
+	return nil
+}
+
+// vendoredDeps returns the dependencies in kcl.mod.lock to be vendored sorted by the names,
+// the local dependencies and the replaced dependencies are not vendored.
+func vendoredDeps(kclPkg *pkg.KclPkg) []pkg.Dependency {
+	var deps []pkg.Dependency
+	if kclPkg.Dependencies.Deps == nil {
+		return deps
+	}
+	for _, name := range kclPkg.Dependencies.Deps.Keys() {
+		dep, ok := kclPkg.Dependencies.Deps.Get(name)
+		if !ok || dep.IsFromLocal() || dep.Replace != "" {
+			continue
+		}
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Name < deps[j].Name
+	})
+	return deps
+}
+
+// vendorDirNames returns the possible names of the directory of the dependency in the vendor directory.
+func vendorDirNames(dep pkg.Dependency) []string {
+	names := []string{dep.GenPathSuffix()}
+	if fullName := dep.GenDepFullName(); fullName != names[0] {
+		names = append(names, fullName)
+	}
+	return names
+}
+
+// findVendorDir returns the name of the directory of the dependency in the vendor directory.
+func findVendorDir(kclPkg *pkg.KclPkg, dep pkg.Dependency) (string, bool) {
+	for _, name := range vendorDirNames(dep) {
+		if utils.DirExists(filepath.Join(kclPkg.LocalVendorPath(), name)) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// pruneVendor deletes the directories in the vendor directory which are not the dependencies in kcl.mod.lock,
+// and returns the names of the deleted directories.
+func pruneVendor(kclPkg *pkg.KclPkg) ([]string, error) {
+	keep := map[string]bool{}
+	if kclPkg.Dependencies.Deps != nil {
+		for _, name := range kclPkg.Dependencies.Deps.Keys() {
+			dep, _ := kclPkg.Dependencies.Deps.Get(name)
+			for _, dirName := range vendorDirNames(dep) {
+				keep[dirName] = true
+			}
+		}
+	}
+
+	vendorPath := kclPkg.LocalVendorPath()
+	entries, err := os.ReadDir(vendorPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for _, entry := range entries {
+		if !entry.IsDir() || keep[entry.Name()] {
+			continue
+		}
+		err := os.RemoveAll(filepath.Join(vendorPath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pruned = append(pruned, entry.Name())
+	}
+	return pruned, nil
+}
+
+// writeVendorModules writes the manifest 'vendor/modules.txt' listing the vendored dependencies, e.g.
+//
+//	# helloworld 0.1.0 oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0
+//	## sum yNADGqn3jclWtfpwvWMHBsgkAKzOaMWg/VYxfcOJs64=
+//	## hash yNADGqn3jclWtfpwvWMHBsgkAKzOaMWg/VYxfcOJs64=
+//	helloworld_0.1.0
+//
+// The 'sum' is the checksum in kcl.mod.lock and the 'hash' is the hash of the vendored directory,
+// so that the changes of the vendored dependencies can be reviewed from the manifest.
+func writeVendorModules(kclPkg *pkg.KclPkg) error {
+	var sb strings.Builder
+	for _, dep := range vendoredDeps(kclPkg) {
+		vendorDir, ok := findVendorDir(kclPkg, dep)
+		if !ok {
+			return fmt.Errorf("'%s' is not vendored", dep.Name)
+		}
+		source, err := dep.Source.ToString()
+		if err != nil {
+			return err
+		}
+		hash, err := utils.HashDir(filepath.Join(kclPkg.LocalVendorPath(), vendorDir))
+		if err != nil {
+			return err
+		}
+
+		sb.WriteString(strings.TrimSpace(fmt.Sprintf("# %s %s %s", dep.Name, dep.Version, source)))
+		sb.WriteString("\n")
+		if dep.Sum != "" {
+			sb.WriteString(fmt.Sprintf("## sum %s\n", dep.Sum))
+		}
+		sb.WriteString(fmt.Sprintf("## hash %s\n", hash))
+		sb.WriteString(vendorDir)
+		sb.WriteString("\n")
+	}
+
+	return utils.StoreToFile(filepath.Join(kclPkg.LocalVendorPath(), constants.VENDOR_MODULES_TXT), sb.String())
+}
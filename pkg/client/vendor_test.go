@@ -1,12 +1,14 @@
 package client
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/features"
 	pkg "kcl-lang.io/kpm/pkg/package"
@@ -88,6 +90,71 @@ func testVendorDeps(t *testing.T, kpmcli *KpmClient) {
 	os.RemoveAll(filepath.Join(testDir, "my_kcl"))
 }
 
+func testVendorVerifyAndPrune(t *testing.T, kpmcli *KpmClient) {
+	testDir := getTestDir("resolve_deps")
+	kpm_home := filepath.Join(testDir, "kpm_home")
+	kcl1Sum, _ := utils.HashDir(filepath.Join(kpm_home, "kcl1_0.0.1"))
+	kcl2Sum, _ := utils.HashDir(filepath.Join(kpm_home, "kcl2_0.0.1"))
+
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	for name, sum := range map[string]string{"kcl1": kcl1Sum, "kcl2": kcl2Sum} {
+		deps.Set(name, pkg.Dependency{
+			Name:     name,
+			FullName: name + "_0.0.1",
+			Version:  "0.0.1",
+			Sum:      sum,
+			Source: downloader.Source{
+				Oci: &downloader.Oci{
+					Reg:  "ghcr.io",
+					Repo: "kcl-lang/" + name,
+					Tag:  "0.0.1",
+				},
+			},
+		})
+	}
+
+	homePath := t.TempDir()
+	kclPkg := pkg.KclPkg{
+		ModFile: pkg.ModFile{
+			HomePath:     homePath,
+			Dependencies: pkg.Dependencies{Deps: deps},
+		},
+		HomePath:     homePath,
+		Dependencies: pkg.Dependencies{Deps: deps},
+	}
+
+	vendorPath := filepath.Join(homePath, "vendor")
+	assert.Equal(t, os.MkdirAll(filepath.Join(vendorPath, "stale_0.0.1"), 0755), nil)
+
+	kpmcli.homePath = kpm_home
+	pruned, err := kpmcli.Vendor(WithVendorKclPkg(&kclPkg), WithVendorPrune(true))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pruned, []string{"stale_0.0.1"})
+	assert.Equal(t, utils.DirExists(filepath.Join(vendorPath, "stale_0.0.1")), false)
+
+	modules, err := os.ReadFile(filepath.Join(vendorPath, constants.VENDOR_MODULES_TXT))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(modules), fmt.Sprintf(`# kcl1 0.0.1 oci://ghcr.io/kcl-lang/kcl1?tag=0.0.1
+## sum %[1]s
+## hash %[1]s
+kcl1_0.0.1
+# kcl2 0.0.1 oci://ghcr.io/kcl-lang/kcl2?tag=0.0.1
+## sum %[2]s
+## hash %[2]s
+kcl2_0.0.1
+`, kcl1Sum, kcl2Sum))
+
+	assert.Equal(t, kpmcli.VerifyVendor(&kclPkg), nil)
+
+	// Tamper with a vendored dependency and remove another one.
+	assert.Equal(t, os.WriteFile(filepath.Join(vendorPath, "kcl1_0.0.1", "tampered.k"), []byte("a = 1"), 0644), nil)
+	assert.Equal(t, os.RemoveAll(filepath.Join(vendorPath, "kcl2_0.0.1")), nil)
+	err = kpmcli.VerifyVendor(&kclPkg)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("checksum mismatch for 'kcl1' in 'vendor/kcl1_0.0.1': expected '%s'", kcl1Sum))
+	assert.Contains(t, err.Error(), "'kcl2' is not vendored")
+}
+
 func testVendorWithMVS(t *testing.T, kpmcli *KpmClient) {
 	features.Enable(features.SupportMVS)
 	defer features.Disable(features.SupportMVS)
@@ -109,4 +176,5 @@ func testVendorWithMVS(t *testing.T, kpmcli *KpmClient) {
 func TestVendorWithGlobalLock(t *testing.T) {
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestVendorDeps", TestFunc: testVendorDeps}})
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestVendorWithMVS", TestFunc: testVendorWithMVS}})
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestVendorVerifyAndPrune", TestFunc: testVendorVerifyAndPrune}})
 }
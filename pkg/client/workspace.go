@@ -0,0 +1,502 @@
+package client
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/elliotchance/orderedmap/v2"
+	"github.com/hashicorp/go-version"
+	"github.com/otiai10/copy"
+	"golang.org/x/mod/module"
+	"kcl-lang.io/kpm/pkg/3rdparty/mvs"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/env"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/resolver"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// Workspace is a set of KCL modules listed in 'kcl.work', which are resolved together
+// and share one lock file 'kcl.work.lock' at the workspace root.
+// The members requiring each other are resolved as local dependencies.
+type Workspace struct {
+	// WorkFile is the workspace file 'kcl.work'.
+	WorkFile *pkg.WorkFile
+	// Members is the member modules in the order of 'kcl.work'.
+	Members []*pkg.KclPkg
+	// Dependencies is the dependencies shared by the members locked in 'kcl.work.lock'.
+	Dependencies *pkg.Dependencies
+}
+
+// workspaceRoot is the virtual root vertex of the workspace in the dependency graph, which requires all the members.
+var workspaceRoot = module.Version{Path: pkg.WORK_FILE}
+
+// LoadWorkspace loads the workspace from the workspace root containing 'kcl.work'.
+func (c *KpmClient) LoadWorkspace(path string) (*Workspace, error) {
+	workFile, err := pkg.LoadWorkFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{WorkFile: workFile}
+	for _, memberPath := range workFile.MemberPaths() {
+		if !utils.DirExists(filepath.Join(memberPath, pkg.MOD_FILE)) {
+			return nil, reporter.NewErrorEvent(
+				reporter.InvalidWorkspace,
+				fmt.Errorf("the workspace member '%s' is not a kcl module, '%s' not found", memberPath, pkg.MOD_FILE),
+			)
+		}
+		member, err := c.LoadPkgFromPath(memberPath)
+		if err != nil {
+			return nil, err
+		}
+		if existing := ws.memberByName(member.GetPkgName()); existing != nil {
+			return nil, reporter.NewErrorEvent(
+				reporter.InvalidWorkspace,
+				fmt.Errorf("the workspace members '%s' and '%s' have the same name '%s'", existing.HomePath, member.HomePath, member.GetPkgName()),
+			)
+		}
+		ws.Members = append(ws.Members, member)
+	}
+
+	ws.Dependencies, err = workFile.LoadWorkLockDeps()
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// FindWorkspace finds the workspace which the KCL module in 'pkgPath' is a member of.
+// It searches 'kcl.work' from 'pkgPath' up to the file system root,
+// and returns nil if not found, the module is not a member or the workspace is disabled by '$KCL_WORK=off'.
+func (c *KpmClient) FindWorkspace(pkgPath string) (*Workspace, error) {
+	if env.WorkspaceDisabled() || pkgPath == "" {
+		return nil, nil
+	}
+
+	root, err := pkg.FindWorkFile(pkgPath)
+	if err != nil || root == "" {
+		return nil, err
+	}
+
+	ws, err := c.LoadWorkspace(root)
+	if err != nil {
+		return nil, err
+	}
+	if ws.member(pkgPath) == nil {
+		return nil, nil
+	}
+	return ws, nil
+}
+
+// member returns the member in the path, or nil if there is no member in the path.
+func (ws *Workspace) member(path string) *pkg.KclPkg {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	for _, member := range ws.Members {
+		if filepath.Clean(member.HomePath) == absPath {
+			return member
+		}
+	}
+	return nil
+}
+
+// memberByName returns the member named 'name', or nil if there is no member named 'name'.
+func (ws *Workspace) memberByName(name string) *pkg.KclPkg {
+	for _, member := range ws.Members {
+		if member.GetPkgName() == name {
+			return member
+		}
+	}
+	return nil
+}
+
+// withMember replaces the member in the same path with 'kMod',
+// to resolve the workspace with the changes of 'kMod' not stored in kcl.mod yet.
+func (ws *Workspace) withMember(kMod *pkg.KclPkg) *Workspace {
+	newWs := *ws
+	newWs.Members = make([]*pkg.KclPkg, len(ws.Members))
+	for i, member := range ws.Members {
+		if member == ws.member(kMod.HomePath) {
+			newWs.Members[i] = kMod
+		} else {
+			newWs.Members[i] = member
+		}
+	}
+	return &newWs
+}
+
+// linkMembers returns a copy of the member whose dependencies on the other members
+// are replaced by the local dependencies on the member paths.
+// The member itself is not changed, so the dependencies are stored into its kcl.mod as they are.
+func (ws *Workspace) linkMembers(member *pkg.KclPkg) *pkg.KclPkg {
+	linked := *member
+	linked.ModFile.Dependencies.Deps = orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	for _, depName := range member.ModFile.Dependencies.Deps.Keys() {
+		dep, _ := member.ModFile.Dependencies.Deps.Get(depName)
+		if depMember := ws.memberByName(depName); depMember != nil && depMember.HomePath != member.HomePath {
+			dep = pkg.Dependency{
+				Name:          depName,
+				Version:       depMember.GetPkgVersion(),
+				LocalFullPath: depMember.HomePath,
+				Source: downloader.Source{
+					Local: &downloader.Local{Path: depMember.HomePath},
+				},
+			}
+			dep.FullName = dep.GenDepFullName()
+		}
+		linked.ModFile.Dependencies.Deps.Set(depName, dep)
+	}
+	return &linked
+}
+
+// workspaceResolution is the result of resolving all the members of a workspace together.
+type workspaceResolution struct {
+	ws *Workspace
+	// graph contains all the versions of the modules required in the workspace.
+	graph *DepGraph
+	// selected is the versions selected by MVS, the key is the name of the module.
+	selected map[string]module.Version
+}
+
+// workspaceReqs is the module requirement graph of the workspace for MVS.
+type workspaceReqs struct {
+	ws    *Workspace
+	graph *DepGraph
+}
+
+func (r *workspaceReqs) Required(m module.Version) ([]module.Version, error) {
+	adjMap, err := r.graph.gra.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+	return sortedVertices(adjMap[m]), nil
+}
+
+// Max returns the greater version, the version of the workspace member is always selected.
+// The versions which are not semantic versions, e.g. git commits, are compared as strings.
+func (r *workspaceReqs) Max(path, v1, v2 string) string {
+	if v1 == "none" || v2 == "" {
+		return v2
+	}
+	if v2 == "none" || v1 == "" {
+		return v1
+	}
+	if member := r.ws.memberByName(path); member != nil {
+		if v2 == member.GetPkgVersion() {
+			return v2
+		}
+		return v1
+	}
+	version1, err1 := version.NewVersion(v1)
+	version2, err2 := version.NewVersion(v2)
+	if err1 != nil || err2 != nil {
+		if v1 > v2 {
+			return v1
+		}
+		return v2
+	}
+	if version1.GreaterThan(version2) {
+		return v1
+	}
+	return v2
+}
+
+// resolveWorkspace resolves all the members of the workspace together,
+// and selects one version for each module required in the workspace by MVS.
+func (c *KpmClient) resolveWorkspace(ws *Workspace, resolveOpts ...resolver.ResolveOption) (*workspaceResolution, error) {
+	dGraph := NewDepGraph()
+	if _, err := dGraph.AddVertex(workspaceRoot.Path, workspaceRoot.Version); err != nil {
+		return nil, err
+	}
+
+	depResolver := resolver.DepsResolver{
+		DefaultCachePath:      c.homePath,
+		InsecureSkipTLSverify: c.insecureSkipTLSverify,
+		Downloader:            c.DepDownloader,
+		Settings:              &c.settings,
+		LogWriter:             c.logWriter,
+		Jobs:                  c.jobs,
+		Cache:                 c.Cache(),
+		CheckSource:           c.checkSourceSignature,
+	}
+
+	for _, member := range ws.Members {
+		memberVertex, err := dGraph.AddVertex(member.GetPkgName(), member.GetPkgVersion())
+		if err != nil {
+			return nil, err
+		}
+		if err := dGraph.AddEdge(workspaceRoot, *memberVertex); err != nil {
+			return nil, err
+		}
+		if err := c.resolveIntoGraph(dGraph, &depResolver, ws.linkMembers(member), resolveOpts...); err != nil {
+			return nil, err
+		}
+	}
+
+	buildList, err := mvs.BuildList([]module.Version{workspaceRoot}, &workspaceReqs{ws: ws, graph: dGraph})
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedUpdatingBuildList, err, "failed to resolve the workspace")
+	}
+
+	selected := make(map[string]module.Version, len(buildList))
+	for _, m := range buildList[1:] {
+		// The selected versions should satisfy the version constraints in the workspace.
+		if err := depResolver.CheckVersionRequirements(m.Path, m.Version); err != nil {
+			return nil, err
+		}
+		selected[m.Path] = m
+	}
+
+	return &workspaceResolution{ws: ws, graph: dGraph, selected: selected}, nil
+}
+
+// selectedGraph returns the dependency graph made of the selected versions only,
+// each module requires the selected version of its dependencies.
+func (res *workspaceResolution) selectedGraph() (*DepGraph, error) {
+	adjMap, err := res.graph.gra.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+
+	dGraph := NewDepGraph()
+	for _, m := range append([]module.Version{workspaceRoot}, res.selectedModules()...) {
+		if _, err := res.addSelectedVertex(dGraph, m); err != nil {
+			return nil, err
+		}
+		for _, req := range sortedVertices(adjMap[m]) {
+			selectedReq, err := res.addSelectedVertex(dGraph, res.selected[req.Path])
+			if err != nil {
+				return nil, err
+			}
+			if err := dGraph.AddEdge(m, *selectedReq); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return dGraph, nil
+}
+
+func (res *workspaceResolution) addSelectedVertex(dGraph *DepGraph, m module.Version) (*module.Version, error) {
+	if dep, ok := res.graph.Dependency(m); ok {
+		return dGraph.AddDepVertex(&dep)
+	}
+	return dGraph.AddVertex(m.Path, m.Version)
+}
+
+// selectedModules returns the selected modules sorted by name.
+func (res *workspaceResolution) selectedModules() []module.Version {
+	modules := make([]module.Version, 0, len(res.selected))
+	for _, m := range res.selected {
+		modules = append(modules, m)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+	return modules
+}
+
+// lockDeps returns the selected dependencies to lock in 'kcl.work.lock', the members are not locked.
+// The checksums are reused from 'kcl.work.lock' and the kcl.mod.lock of the members if the versions are the same,
+// otherwise they are acquired by 'acquireSum'.
+func (res *workspaceResolution) lockDeps(acquireSum func(dep pkg.Dependency) (string, error)) (*pkg.Dependencies, error) {
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	for _, m := range res.selectedModules() {
+		if res.ws.memberByName(m.Path) != nil {
+			continue
+		}
+		dep, ok := res.graph.Dependency(m)
+		if !ok {
+			return nil, fmt.Errorf("failed to get dependency %s", m.Path)
+		}
+
+		// The local paths in 'kcl.work.lock' are relative to the workspace root.
+		if dep.IsFromLocal() {
+			relPath, err := filepath.Rel(res.ws.WorkFile.HomePath, dep.LocalFullPath)
+			if err != nil {
+				return nil, err
+			}
+			dep.Source = downloader.Source{Local: &downloader.Local{Path: filepath.ToSlash(relPath)}}
+		}
+
+		// The dependencies replaced by the '[replace]' section are locked without checksums.
+		if dep.Replace == "" {
+			if lockDep, ok := res.ws.Dependencies.Deps.Get(m.Path); ok && lockDep.Version == dep.Version && lockDep.Sum != "" {
+				dep.Sum = lockDep.Sum
+			}
+			if dep.Sum == "" {
+				sum, err := acquireSum(dep)
+				if err != nil {
+					return nil, err
+				}
+				dep.Sum = sum
+			}
+		}
+		deps.Set(m.Path, dep)
+	}
+	return &pkg.Dependencies{Deps: deps}, nil
+}
+
+// memberDeps returns the selected dependencies reachable from the member,
+// including the other members it requires as local dependencies.
+func (res *workspaceResolution) memberDeps(member *pkg.KclPkg, lockDeps *pkg.Dependencies) (*pkg.Dependencies, error) {
+	dGraph, err := res.selectedGraph()
+	if err != nil {
+		return nil, err
+	}
+	adjMap, err := dGraph.gra.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+
+	start := res.selected[member.GetPkgName()]
+	visited := map[module.Version]bool{start: true}
+	queue := []module.Version{start}
+	var names []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range sortedVertices(adjMap[current]) {
+			if !visited[next] {
+				visited[next] = true
+				names = append(names, next.Path)
+				queue = append(queue, next)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	deps := orderedmap.NewOrderedMap[string, pkg.Dependency]()
+	for _, name := range names {
+		if depMember := res.ws.memberByName(name); depMember != nil {
+			dep, _ := dGraph.Dependency(res.selected[name])
+			deps.Set(name, dep)
+			continue
+		}
+		dep, ok := lockDeps.Deps.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("failed to get dependency %s", name)
+		}
+		if graphDep, ok := res.graph.Dependency(res.selected[name]); ok {
+			dep.LocalFullPath = graphDep.LocalFullPath
+		}
+		deps.Set(name, dep)
+	}
+	return &pkg.Dependencies{Deps: deps}, nil
+}
+
+// updateWorkspaceMember updates the member 'kMod' of the workspace by resolving all the members together.
+// The shared dependencies are locked in 'kcl.work.lock' instead of the kcl.mod.lock of the member,
+// and the dependencies of 'kMod' are set to the selected dependencies reachable from it.
+func (c *KpmClient) updateWorkspaceMember(ws *Workspace, kMod *pkg.KclPkg, opts *UpdateOptions) (*pkg.KclPkg, error) {
+	ws = ws.withMember(kMod)
+	lockDeps, err := c.selectWorkspaceMemberDeps(ws, kMod, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.updateModFile && utils.DirExists(filepath.Join(kMod.HomePath, pkg.MOD_FILE)) {
+		err = kMod.UpdateModFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.noSumCheck {
+		err = ws.WorkFile.StoreWorkLockDeps(lockDeps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ws.Dependencies = lockDeps
+
+	return kMod, nil
+}
+
+// selectWorkspaceMemberDeps resolves all the members of the workspace 'ws' together,
+// sets the dependencies of the member 'kMod' to the selected dependencies reachable from it
+// and returns the dependencies selected for the whole workspace. Nothing is written.
+func (c *KpmClient) selectWorkspaceMemberDeps(ws *Workspace, kMod *pkg.KclPkg, opts *UpdateOptions) (*pkg.Dependencies, error) {
+	res, err := c.resolveWorkspace(ws, resolver.WithOffline(opts.offline), resolver.WithSkipMissing(opts.skipMissing))
+	if err != nil {
+		return nil, err
+	}
+
+	lockDeps, err := res.lockDeps(func(dep pkg.Dependency) (string, error) {
+		// Reuse the checksums in the kcl.mod.lock of the members.
+		for _, member := range ws.Members {
+			if lockDep, ok := member.Dependencies.Deps.Get(dep.Name); ok && lockDep.Version == dep.Version && lockDep.Sum != "" {
+				return lockDep.Sum, nil
+			}
+		}
+		if c.noSumCheck || env.SkipChecksumCheck(dep.Name) {
+			return "", nil
+		}
+		if opts.offline {
+			if !opts.skipMissing && dep.Source.Oci != nil {
+				return "", newSumNotLockedOfflineError(&dep)
+			}
+			return "", nil
+		}
+		return c.AcquireDepSum(dep)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	memberDeps, err := res.memberDeps(kMod, lockDeps)
+	if err != nil {
+		return nil, err
+	}
+	kMod.Dependencies = *memberDeps
+
+	err = c.checkSumDB(kMod, opts.offline)
+	if err != nil {
+		return nil, err
+	}
+	return lockDeps, nil
+}
+
+// vendorWorkspaceMember vendors the dependencies of the member 'kclPkg' selected for the workspace
+// into the vendor directory of the member. The other members are not vendored as local dependencies.
+// The dependencies are resolved by the options 'opts', and 'kcl.work.lock' is not written by vendoring.
+// The dependencies not found in the cache are not vendored if they are skipped by the options.
+func (c *KpmClient) vendorWorkspaceMember(ws *Workspace, kclPkg *pkg.KclPkg, vendorPath string, opts *UpdateOptions) error {
+	_, err := c.selectWorkspaceMemberDeps(ws.withMember(kclPkg), kclPkg, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, depName := range kclPkg.Dependencies.Deps.Keys() {
+		dep, ok := kclPkg.Dependencies.Deps.Get(depName)
+		if !ok {
+			return fmt.Errorf("failed to get dependency %s", depName)
+		}
+		if dep.IsFromLocal() {
+			continue
+		}
+
+		vendorFullPath := filepath.Join(vendorPath, dep.GenDepFullName())
+		if !utils.DirExists(vendorFullPath) {
+			if !utils.DirExists(dep.LocalFullPath) {
+				if opts.skipMissing {
+					continue
+				}
+				return fmt.Errorf("failed to find the dependency %s in '%s'", depName, dep.LocalFullPath)
+			}
+			err := copy.Copy(dep.LocalFullPath, vendorFullPath)
+			if err != nil {
+				return err
+			}
+		}
+		dep.LocalFullPath = vendorFullPath
+		kclPkg.Dependencies.Deps.Set(depName, dep)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/mod/module"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/features"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// prepareWorkspace copies the workspace and the cached dependencies into a temp dir,
+// and returns the workspace root.
+func prepareWorkspace(t *testing.T, kpmcli *KpmClient) string {
+	features.Disable(features.SupportNewStorage)
+	tmpDir := t.TempDir()
+	testPath := getTestDir("test_workspace")
+	assert.NoError(t, copy.Copy(testPath, tmpDir))
+	kpmcli.SetHomePath(filepath.Join(tmpDir, "kpm_home"))
+	return filepath.Join(tmpDir, "ws")
+}
+
+func TestWorkspace(t *testing.T) {
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{
+		{Name: "TestUpdateWorkspace", TestFunc: testUpdateWorkspace},
+		{Name: "TestWorkspaceGraph", TestFunc: testWorkspaceGraph},
+		{Name: "TestFindWorkspace", TestFunc: testFindWorkspace},
+		{Name: "TestVendorWorkspace", TestFunc: testVendorWorkspace},
+		{Name: "TestUpdateOfflineWithoutSum", TestFunc: testUpdateOfflineWithoutSum},
+	})
+}
+
+func testUpdateWorkspace(t *testing.T, kpmcli *KpmClient) {
+	wsPath := prepareWorkspace(t, kpmcli)
+	// The checksums of the cached dependencies are not fetched from the registry.
+	t.Setenv("KPM_NO_SUM", "helloworld")
+
+	base, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "base"))
+	assert.NoError(t, err)
+
+	_, err = kpmcli.Update(WithUpdatedKclPkg(base), WithOffline(true))
+	assert.NoError(t, err)
+
+	// The version required by the other member is selected.
+	dep, ok := base.Dependencies.Deps.Get("helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, "0.1.1", dep.Version)
+	assert.Equal(t, filepath.Join(kpmcli.homePath, "helloworld_0.1.1"), dep.LocalFullPath)
+	assert.Equal(t, []string{"helloworld"}, base.Dependencies.Deps.Keys())
+
+	// The shared lock file is at the workspace root, the members have no kcl.mod.lock.
+	lockDeps, err := pkg.LoadLockDeps(wsPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, lockDeps.Deps.Len())
+	workLock, err := os.ReadFile(filepath.Join(wsPath, pkg.WORK_LOCK_FILE))
+	assert.NoError(t, err)
+	assert.Contains(t, string(workLock), `version = "0.1.1"`)
+	assert.NotContains(t, string(workLock), "[dependencies.base]")
+	assert.False(t, fileExists(filepath.Join(wsPath, "base", pkg.MOD_LOCK_FILE)))
+
+	// The kcl.mod of the member is not changed.
+	modContent, err := os.ReadFile(filepath.Join(wsPath, "base", pkg.MOD_FILE))
+	assert.NoError(t, err)
+	assert.Contains(t, string(modContent), `helloworld = "0.1.0"`)
+
+	// The member requiring the other member resolves it as a local dependency.
+	app, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "app"))
+	assert.NoError(t, err)
+	depMap, err := kpmcli.ResolveDepsIntoMap(app)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"base":       filepath.Join(wsPath, "base"),
+		"helloworld": filepath.Join(kpmcli.homePath, "helloworld_0.1.1"),
+	}, depMap)
+
+	// The module which is not a member is resolved alone.
+	outside, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "outside"))
+	assert.NoError(t, err)
+	_, err = kpmcli.Update(WithUpdatedKclPkg(outside), WithOffline(true))
+	assert.NoError(t, err)
+	dep, ok = outside.Dependencies.Deps.Get("helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, "0.1.0", dep.Version)
+}
+
+func testWorkspaceGraph(t *testing.T, kpmcli *KpmClient) {
+	wsPath := prepareWorkspace(t, kpmcli)
+
+	base, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "base"))
+	assert.NoError(t, err)
+
+	dGraph, err := kpmcli.Graph(WithGraphMod(base))
+	assert.NoError(t, err)
+
+	res, err := dGraph.Display(module.Version{Path: "base", Version: "0.0.1"}, &TextGraphFormatter{})
+	assert.NoError(t, err)
+	assert.Equal(t, "base@0.0.1 helloworld@0.1.1\n", res)
+
+	res, err = dGraph.Display(module.Version{Path: "app", Version: "0.0.1"}, &TextGraphFormatter{})
+	assert.NoError(t, err)
+	assert.Equal(t, "app@0.0.1 base@0.0.1\napp@0.0.1 helloworld@0.1.1\nbase@0.0.1 helloworld@0.1.1\n", res)
+}
+
+func testFindWorkspace(t *testing.T, kpmcli *KpmClient) {
+	wsPath := prepareWorkspace(t, kpmcli)
+
+	ws, err := kpmcli.FindWorkspace(filepath.Join(wsPath, "app"))
+	assert.NoError(t, err)
+	assert.NotNil(t, ws)
+	assert.Equal(t, wsPath, ws.WorkFile.HomePath)
+	assert.Equal(t, []string{"base", "app"}, ws.WorkFile.Members)
+	assert.Equal(t, 2, len(ws.Members))
+
+	ws, err = kpmcli.FindWorkspace(filepath.Join(wsPath, "outside"))
+	assert.NoError(t, err)
+	assert.Nil(t, ws)
+
+	t.Setenv("KCL_WORK", "off")
+	ws, err = kpmcli.FindWorkspace(filepath.Join(wsPath, "app"))
+	assert.NoError(t, err)
+	assert.Nil(t, ws)
+}
+
+func testVendorWorkspace(t *testing.T, kpmcli *KpmClient) {
+	wsPath := prepareWorkspace(t, kpmcli)
+	t.Setenv("KPM_NO_SUM", "helloworld")
+	kpmcli.SetOffline(true)
+	defer kpmcli.SetOffline(false)
+
+	base, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "base"))
+	assert.NoError(t, err)
+
+	// The dependencies selected for the workspace are vendored offline from the cache.
+	_, err = kpmcli.Vendor(WithVendorKclPkg(base))
+	assert.NoError(t, err)
+	dep, ok := base.Dependencies.Deps.Get("helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, "0.1.1", dep.Version)
+	assert.True(t, fileExists(filepath.Join(wsPath, "base", "vendor", "helloworld_0.1.1", pkg.MOD_FILE)))
+
+	// Vendoring does not write the shared lock file.
+	assert.False(t, fileExists(filepath.Join(wsPath, pkg.WORK_LOCK_FILE)))
+}
+
+func testUpdateOfflineWithoutSum(t *testing.T, kpmcli *KpmClient) {
+	wsPath := prepareWorkspace(t, kpmcli)
+
+	// The checksums not locked can not be acquired from the registry in offline mode.
+	for _, name := range []string{"base", "outside"} {
+		kMod, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, name))
+		assert.NoError(t, err)
+		_, err = kpmcli.Update(WithUpdatedKclPkg(kMod), WithOffline(true))
+		assert.ErrorIs(t, err, kpmerrors.NotFoundOffline)
+		assert.ErrorContains(t, err, "the checksum of 'helloworld' version '0.1.")
+	}
+
+	// The checksum locked is kept in offline mode.
+	sum, err := utils.HashDir(filepath.Join(kpmcli.homePath, "helloworld_0.1.0"))
+	assert.NoError(t, err)
+	lockContent := fmt.Sprintf(`[dependencies]
+  [dependencies.helloworld]
+    name = "helloworld"
+    full_name = "helloworld_0.1.0"
+    version = "0.1.0"
+    sum = "%s"
+    reg = "ghcr.io"
+    repo = "kcl-lang/helloworld"
+    oci_tag = "0.1.0"
+`, sum)
+	assert.NoError(t, os.WriteFile(filepath.Join(wsPath, "outside", pkg.MOD_LOCK_FILE), []byte(lockContent), 0644))
+	outside, err := kpmcli.LoadPkgFromPath(filepath.Join(wsPath, "outside"))
+	assert.NoError(t, err)
+	_, err = kpmcli.Update(WithUpdatedKclPkg(outside), WithOffline(true))
+	assert.NoError(t, err)
+	dep, ok := outside.Dependencies.Deps.Get("helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, sum, dep.Sum)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
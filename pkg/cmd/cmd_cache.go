@@ -0,0 +1,194 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/cache"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewCacheCmd new a Command for `kpm cache`.
+func NewCacheCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden: false,
+		Name:   "cache",
+		Usage:  "manage the content-addressed cache of the kcl modules",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list the kcl modules in the cache",
+				Action: func(c *cli.Context) error {
+					return KpmCacheList(c, kpmcli)
+				},
+			},
+			{
+				Name:  "verify",
+				Usage: "rehash the kcl modules in the cache and report the corrupted ones",
+				Action: func(c *cli.Context) error {
+					return KpmCacheVerify(c, kpmcli)
+				},
+			},
+			{
+				Name:  "clean",
+				Usage: "remove the kcl modules from the cache",
+				Flags: []cli.Flag{
+					// '--older-than' only removes the modules not used within the duration.
+					&cli.StringFlag{
+						Name:  FLAG_OLDER_THAN,
+						Usage: "only remove the modules not used within the duration, e.g. '72h' or '30d'",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return KpmCacheClean(c, kpmcli)
+				},
+			},
+			{
+				Name:  "du",
+				Usage: "show the disk usage of the cache",
+				Action: func(c *cli.Context) error {
+					return KpmCacheDu(c, kpmcli)
+				},
+			},
+		},
+	}
+}
+
+func KpmCacheList(c *cli.Context, kpmcli *client.KpmClient) error {
+	entries, err := kpmcli.Cache().List()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedAccessCache, err, "failed to list the cache")
+	}
+	if len(entries) == 0 {
+		reporter.ReportMsgTo("the cache is empty", kpmcli.GetLogWriter())
+		return nil
+	}
+	reporter.ReportMsgTo(formatCacheTable(entries), kpmcli.GetLogWriter())
+	return nil
+}
+
+func KpmCacheVerify(c *cli.Context, kpmcli *client.KpmClient) error {
+	corrupted, err := kpmcli.Cache().Verify()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedAccessCache, err, "failed to verify the cache")
+	}
+	if len(corrupted) == 0 {
+		reporter.ReportMsgTo("all modules in the cache verified", kpmcli.GetLogWriter())
+		return nil
+	}
+
+	reporter.ReportMsgTo(formatCacheTable(corrupted), kpmcli.GetLogWriter())
+	return reporter.NewErrorEvent(
+		reporter.CheckSumMismatch,
+		fmt.Errorf("%d module(s) in the cache do not match their checksums", len(corrupted)),
+		"the corrupted modules are removed from the cache when they are used next time, or run 'kpm cache clean'",
+	)
+}
+
+func KpmCacheClean(c *cli.Context, kpmcli *client.KpmClient) (err error) {
+	olderThan, err := parseOlderThan(c.String(FLAG_OLDER_THAN))
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.InvalidFlag, err)
+	}
+
+	// acquire the lock of the package cache.
+	err = kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	removed, err := kpmcli.Cache().Clean(olderThan)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedAccessCache, err, "failed to clean the cache")
+	}
+
+	var size int64
+	for _, entry := range removed {
+		size += entry.Size
+	}
+	reporter.ReportMsgTo(fmt.Sprintf("removed %d module(s), %s freed", len(removed), formatSize(size)), kpmcli.GetLogWriter())
+	return nil
+}
+
+func KpmCacheDu(c *cli.Context, kpmcli *client.KpmClient) error {
+	size, count, err := kpmcli.Cache().DiskUsage()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedAccessCache, err, "failed to compute the disk usage of the cache")
+	}
+	reporter.ReportMsgTo(fmt.Sprintf("%s\t%d module(s)\t%s", formatSize(size), count, kpmcli.Cache().Root()), kpmcli.GetLogWriter())
+	return nil
+}
+
+// parseOlderThan parses the duration of '--older-than', which supports the days, e.g. '30d',
+// besides the units of 'time.ParseDuration'. The empty duration is 0.
+func parseOlderThan(olderThan string) (time.Duration, error) {
+	if olderThan == "" {
+		return 0, nil
+	}
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(olderThan, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s' for '--%s'", olderThan, FLAG_OLDER_THAN)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		duration, err = time.ParseDuration(olderThan)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s' for '--%s'", olderThan, FLAG_OLDER_THAN)
+		}
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s' for '--%s', expected a positive duration", olderThan, FLAG_OLDER_THAN)
+	}
+	return duration, nil
+}
+
+// formatCacheTable formats the modules in the cache to a table.
+func formatCacheTable(entries []cache.Entry) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tSIZE\tLAST USED\tSUM")
+	for _, entry := range entries {
+		name, version := entry.Name, entry.Version
+		if name == "" {
+			name = "-"
+		}
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, version, formatSize(entry.Size), entry.LastUsed.Format(time.DateTime), entry.Sum)
+	}
+	w.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatSize formats the size in bytes to the human-readable size, e.g. '1.5 MiB'.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
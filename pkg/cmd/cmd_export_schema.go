@@ -0,0 +1,111 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/api"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewExportSchemaCmd new a Command for `kpm export-schema`.
+func NewExportSchemaCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "export-schema",
+		Usage:     "export the schema types of a kcl package into OpenAPI 3.1 or JSON Schema",
+		ArgsUsage: "[<local path> | <tar path> | oci://<reg>/<repo>?tag=<tag> | git://<url>?tag=<tag>]",
+		Flags: []cli.Flag{
+			// '--format' sets the format of the exported schemas.
+			&cli.StringFlag{
+				Name:  FLAG_FORMAT,
+				Value: api.SchemaFormatOpenAPIV3,
+				Usage: "format of the exported schemas, 'openapi3' for an OpenAPI 3.1 document or 'jsonschema' for a JSON Schema of each schema type",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_OUT,
+				Value: ".",
+				Usage: "the directory to write the exported schemas to",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmExportSchema(c, kpmcli)
+		},
+	}
+}
+
+func KpmExportSchema(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() > 1 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("only allows one package at a time"),
+		)
+	}
+	format := c.String(FLAG_FORMAT)
+	if format != api.SchemaFormatOpenAPIV3 && format != api.SchemaFormatJSONSchema {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("unsupported schema format '%s', supported formats are: %s, %s", format, api.SchemaFormatOpenAPIV3, api.SchemaFormatJSONSchema),
+		)
+	}
+
+	// acquire the lock of the package cache.
+	err := kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	// 'kpm export-schema' exports the schema types of the package under '$pwd'.
+	source := c.Args().First()
+	if source == "" {
+		source, err = os.Getwd()
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+		}
+	}
+
+	schemas, err := kpmcli.Schema(client.WithSchemaSourceUrl(source))
+	if err != nil {
+		return err
+	}
+
+	files, err := api.NewSchemaFiles(schemas, format)
+	if err != nil {
+		return err
+	}
+
+	outDir := c.String(FLAG_OUT)
+	err = os.MkdirAll(outDir, 0755)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedCreateFile, err, fmt.Sprintf("failed to create the directory '%s'", outDir))
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(outDir, name)
+		err = os.WriteFile(path, files[name], 0644)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedCreateFile, err, fmt.Sprintf("failed to create '%s'", path))
+		}
+		reporter.ReportMsgTo(fmt.Sprintf("exported '%s'", path), kpmcli.GetLogWriter())
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/api"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+func TestExportSchema(t *testing.T) {
+	kpmcli, err := client.NewKpmClient()
+	assert.NoError(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	app := &cli.App{
+		Name:     "kpm",
+		Commands: []*cli.Command{NewExportSchemaCmd(kpmcli)},
+	}
+	pkgPath := filepath.Join("..", "client", "test_data", "test_schema", "pkg")
+
+	outDir := t.TempDir()
+	err = app.Run([]string{"kpm", "export-schema", "--out", outDir, pkgPath})
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(outDir, api.OPENAPI_V3_FILE))
+	assert.NoError(t, err)
+	var spec api.OpenAPIV3Spec
+	assert.NoError(t, json.Unmarshal(content, &spec))
+	assert.Contains(t, spec.Components.Schemas, "test_schema.App")
+	assert.Contains(t, spec.Components.Schemas, "base.Base")
+
+	outDir = t.TempDir()
+	err = app.Run([]string{"kpm", "export-schema", "--format", api.SchemaFormatJSONSchema, "--out", outDir, pkgPath})
+	assert.NoError(t, err)
+	entries, err := os.ReadDir(outDir)
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{
+		"test_schema.App.schema.json",
+		"test_schema.models.Service.schema.json",
+		"test_schema.models.Volume.schema.json",
+	}, names)
+
+	err = app.Run([]string{"kpm", "export-schema", "--format", "swagger", "--out", outDir, pkgPath})
+	assert.ErrorContains(t, err, "unsupported schema format 'swagger'")
+}
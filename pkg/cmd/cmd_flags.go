@@ -20,3 +20,30 @@ const FLAG_SORT_KEYS = "sort_keys"
 
 const FLAG_QUIET = "quiet"
 const FLAG_NO_SUM_CHECK = "no_sum_check"
+
+const FLAG_FORMAT = "format"
+const FLAG_TREE = "tree"
+const FLAG_DEPTH = "depth"
+const FLAG_INVERT = "invert"
+const FLAG_UPGRADE = "upgrade"
+const FLAG_UPGRADE_ALL = "upgrade-all"
+const FLAG_COMPATIBLE = "compatible"
+const FLAG_LATEST = "latest"
+const FLAG_VERIFY_REPRODUCIBLE = "verify-reproducible"
+const FLAG_LIST = "list"
+const FLAG_VERIFY = "verify"
+const FLAG_PRUNE = "prune"
+const FLAG_LOG_FORMAT = "log-format"
+
+const (
+	LogFormatText = "text"
+	LogFormatJson = "json"
+)
+const FLAG_JOBS = "jobs"
+const FLAG_OLDER_THAN = "older-than"
+const FLAG_OFFLINE = "offline"
+const FLAG_SIGN_KEY = "sign-key"
+const FLAG_TEMPLATE = "template"
+const FLAG_PROFILE = "profile"
+const FLAG_SCHEMA_NAME = "name"
+const FLAG_OUT = "out"
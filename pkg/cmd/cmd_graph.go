@@ -5,8 +5,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
-	"github.com/dominikbraun/graph"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/mod/module"
 	"kcl-lang.io/kpm/pkg/client"
@@ -21,6 +21,28 @@ func NewGraphCmd(kpmcli *client.KpmClient) *cli.Command {
 		Hidden: false,
 		Name:   "graph",
 		Usage:  "prints the module dependency graph",
+		Flags: []cli.Flag{
+			// '--format' sets the output format of the dependency graph.
+			&cli.StringFlag{
+				Name:  FLAG_FORMAT,
+				Value: client.GraphFormatText,
+				Usage: "output format of the dependency graph, one of text, dot, json and mermaid",
+			},
+			// '--tree' prints the dependency graph as a tree.
+			&cli.BoolFlag{
+				Name:  FLAG_TREE,
+				Usage: "print the dependency graph as a tree",
+			},
+			&cli.IntFlag{
+				Name:  FLAG_DEPTH,
+				Value: -1,
+				Usage: "max depth of the dependency tree, negative means no limit",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_INVERT,
+				Usage: "print the reverse dependencies of the module in the dependency tree",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			return KpmGraph(c, kpmcli)
 		},
@@ -28,8 +50,13 @@ func NewGraphCmd(kpmcli *client.KpmClient) *cli.Command {
 }
 
 func KpmGraph(c *cli.Context, kpmcli *client.KpmClient) error {
+	formatter, err := newGraphFormatter(c)
+	if err != nil {
+		return err
+	}
+
 	// acquire the lock of the package cache.
-	err := kpmcli.AcquirePackageCacheLock()
+	err = kpmcli.AcquirePackageCacheLock()
 	if err != nil {
 		return err
 	}
@@ -63,37 +90,56 @@ func KpmGraph(c *cli.Context, kpmcli *client.KpmClient) error {
 		return err
 	}
 
-	_, depGraph, err := kpmcli.InitGraphAndDownloadDeps(kclPkg)
+	depGraph, err := kpmcli.Graph(client.WithGraphMod(kclPkg))
 	if err != nil {
 		return err
 	}
 
-	adjMap, err := depGraph.AdjacencyMap()
+	root := module.Version{Path: kclPkg.GetPkgName(), Version: kclPkg.GetPkgVersion()}
+	res, err := depGraph.Display(root, formatter)
 	if err != nil {
 		return err
 	}
 
-	format := func(m module.Version) string {
-		formattedMsg := m.Path
-		if m.Version != "" {
-			formattedMsg += "@" + m.Version
+	// The text and tree formats are the messages of kpm and the others are the machine-readable output,
+	// which is always printed to stdout.
+	switch formatter.(type) {
+	case *client.TextGraphFormatter, *client.TreeGraphFormatter:
+		if res == "" {
+			return nil
 		}
-		return formattedMsg
+		reporter.ReportMsgTo(strings.TrimSuffix(res, "\n"), kpmcli.GetLogWriter())
+	default:
+		fmt.Print(res)
 	}
+	return nil
+}
 
-	// print the dependency graph to stdout.
-	root := module.Version{Path: kclPkg.GetPkgName(), Version: kclPkg.GetPkgVersion()}
-	err = graph.BFS(depGraph, root, func(source module.Version) bool {
-		for target := range adjMap[source] {
-			reporter.ReportMsgTo(
-				fmt.Sprint(format(source), " ", format(target)),
-				kpmcli.GetLogWriter(),
+// newGraphFormatter returns the formatter of the dependency graph by the flags.
+func newGraphFormatter(c *cli.Context) (client.GraphFormatter, error) {
+	if !c.Bool(FLAG_TREE) {
+		if c.IsSet(FLAG_DEPTH) || c.IsSet(FLAG_INVERT) {
+			return nil, reporter.NewErrorEvent(
+				reporter.InvalidCmd,
+				fmt.Errorf("'--%s' and '--%s' are only available with '--%s'", FLAG_DEPTH, FLAG_INVERT, FLAG_TREE),
 			)
 		}
-		return false
-	})
-	if err != nil {
-		return err
+		formatter, err := client.NewGraphFormatter(c.String(FLAG_FORMAT))
+		if err != nil {
+			return nil, reporter.NewErrorEvent(reporter.InvalidCmd, err)
+		}
+		return formatter, nil
 	}
-	return nil
+
+	if c.IsSet(FLAG_FORMAT) && c.String(FLAG_FORMAT) != client.GraphFormatText {
+		return nil, reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("'--%s' cannot be used with '--%s %s'", FLAG_TREE, FLAG_FORMAT, c.String(FLAG_FORMAT)),
+		)
+	}
+
+	return &client.TreeGraphFormatter{
+		Depth:  c.Int(FLAG_DEPTH),
+		Invert: c.String(FLAG_INVERT),
+	}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	"kcl-lang.io/kpm/pkg/client"
@@ -15,6 +16,7 @@ import (
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	reporter "kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/templates"
 )
 
 // NewInitCmd new a Command for `kpm init`.
@@ -23,6 +25,13 @@ func NewInitCmd(kpmcli *client.KpmClient) *cli.Command {
 		Hidden: false,
 		Name:   "init",
 		Usage:  "initialize new module in current directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: FLAG_TEMPLATE,
+				Usage: fmt.Sprintf("initialize the module from a template, the name of a built-in template ('%s'), "+
+					"the path of a local directory, an OCI url 'oci://...' or a git url", strings.Join(templates.Builtins(), "', '")),
+			},
+		},
 		Action: func(c *cli.Context) error {
 			pwd, err := os.Getwd()
 
@@ -70,7 +79,15 @@ func NewInitCmd(kpmcli *client.KpmClient) *cli.Command {
 				return err
 			}
 
-			err = kpmcli.InitEmptyPkg(&kclPkg)
+			if template := c.String(FLAG_TEMPLATE); template != "" {
+				err = kpmcli.Init(
+					client.WithInitWorkDir(pwd),
+					client.WithInitModPath(pkgRootPath),
+					client.WithInitTemplate(template),
+				)
+			} else {
+				err = kpmcli.InitEmptyPkg(&kclPkg)
+			}
 			if err != nil {
 				return err
 			}
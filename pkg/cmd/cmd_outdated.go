@@ -0,0 +1,138 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/env"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+const (
+	OutdatedFormatTable = "table"
+	OutdatedFormatJson  = "json"
+)
+
+// NewOutdatedCmd new a Command for `kpm outdated`.
+func NewOutdatedCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden: false,
+		Name:   "outdated",
+		Usage:  "list dependencies with newer versions available",
+		Flags: []cli.Flag{
+			// '--format' sets the output format of the outdated dependencies.
+			&cli.StringFlag{
+				Name:  FLAG_FORMAT,
+				Value: OutdatedFormatTable,
+				Usage: "output format of the outdated dependencies, one of table and json",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmOutdated(c, kpmcli)
+		},
+	}
+}
+
+func KpmOutdated(c *cli.Context, kpmcli *client.KpmClient) error {
+	format := c.String(FLAG_FORMAT)
+	if format != OutdatedFormatTable && format != OutdatedFormatJson {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("unsupported format '%s', supported formats are: %s, %s", format, OutdatedFormatTable, OutdatedFormatJson),
+		)
+	}
+
+	// acquire the lock of the package cache.
+	err := kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+	}
+
+	globalPkgPath, err := env.GetAbsPkgPath()
+	if err != nil {
+		return err
+	}
+
+	kclPkg, err := kpmcli.LoadPkgFromPath(pwd)
+	if err != nil {
+		return err
+	}
+
+	err = kclPkg.ValidateKpmHome(globalPkgPath)
+	if err != (*reporter.KpmEvent)(nil) {
+		return err
+	}
+
+	outdatedDeps, err := kpmcli.Outdated(client.WithOutdatedKclPkg(kclPkg))
+	if err != nil {
+		return err
+	}
+
+	if format == OutdatedFormatJson {
+		if outdatedDeps == nil {
+			outdatedDeps = []client.OutdatedDep{}
+		}
+		res, err := json.MarshalIndent(outdatedDeps, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(res))
+		return nil
+	}
+
+	// The skipped dependencies are listed after the table with the reasons.
+	var checkedDeps []client.OutdatedDep
+	for _, dep := range outdatedDeps {
+		if dep.Skipped == "" {
+			checkedDeps = append(checkedDeps, dep)
+		}
+	}
+
+	if len(checkedDeps) == 0 {
+		reporter.ReportMsgTo("all dependencies are up to date", kpmcli.GetLogWriter())
+	} else {
+		reporter.ReportMsgTo(formatOutdatedTable(checkedDeps), kpmcli.GetLogWriter())
+	}
+	for _, dep := range outdatedDeps {
+		if dep.Skipped != "" {
+			reporter.ReportMsgTo(fmt.Sprintf("skipped '%s': %s", dep.Name, dep.Skipped), kpmcli.GetLogWriter())
+		}
+	}
+	return nil
+}
+
+// formatOutdatedTable formats the outdated dependencies to a table.
+func formatOutdatedTable(outdatedDeps []client.OutdatedDep) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tCURRENT\tCOMPATIBLE\tLATEST")
+	for _, dep := range outdatedDeps {
+		compatible := dep.LatestCompatible
+		if compatible == "" {
+			compatible = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", dep.Name, dep.Source, dep.Current, compatible, dep.Latest)
+	}
+	w.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -34,11 +34,23 @@ func NewPkgCmd(kpmcli *client.KpmClient) *cli.Command {
 				Name:  FLAG_VENDOR,
 				Usage: "push in vendor mode",
 			},
+			// '--verify-reproducible' will package twice and compare the digests of the tars.
+			&cli.BoolFlag{
+				Name:  FLAG_VERIFY_REPRODUCIBLE,
+				Usage: "package twice and check that the digests of the tars are the same",
+			},
+			// '--list' will print the files to be packaged without packaging.
+			&cli.BoolFlag{
+				Name:  FLAG_LIST,
+				Usage: "print the files to be packaged without packaging",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			tarPath := c.String("target")
+			verifyReproducible := c.Bool(FLAG_VERIFY_REPRODUCIBLE)
+			list := c.Bool(FLAG_LIST)
 
-			if len(tarPath) == 0 {
+			if len(tarPath) == 0 && !verifyReproducible && !list {
 				return reporter.NewErrorEvent(
 					reporter.InvalidCmd,
 					fmt.Errorf("the directory where the tar is generated is required"),
@@ -58,6 +70,36 @@ func NewPkgCmd(kpmcli *client.KpmClient) *cli.Command {
 				return err
 			}
 
+			if list {
+				listTarPath := kclPkg.DefaultTarPath()
+				if len(tarPath) != 0 {
+					listTarPath = filepath.Join(tarPath, kclPkg.GetPkgTarName())
+				}
+				files, err := kpmcli.PackageFiles(kclPkg, listTarPath)
+				if err != nil {
+					return err
+				}
+				for _, file := range files {
+					fmt.Println(file)
+				}
+				return nil
+			}
+
+			if verifyReproducible {
+				digest, err := kpmcli.VerifyReproducible(kclPkg, c.Bool(FLAG_VENDOR))
+				if err != nil {
+					return err
+				}
+				reporter.ReportMsgTo(
+					fmt.Sprintf("the package '%s' is reproducible, digest: %s", kclPkg.GetPkgName(), digest),
+					kpmcli.GetLogWriter(),
+				)
+				// Only verify the package if the target path is not specified.
+				if len(tarPath) == 0 {
+					return nil
+				}
+			}
+
 			// If the file path used to save the package tar file does not exist, create this file path.
 			if !utils.DirExists(tarPath) {
 				err := os.MkdirAll(tarPath, os.ModePerm)
@@ -38,6 +38,11 @@ func NewPushCmd(kpmcli *client.KpmClient) *cli.Command {
 				Name:  FLAG_VENDOR,
 				Usage: "push in vendor mode",
 			},
+			// '--sign-key' signs the pushed package and pushes the signature as a referrer of the package.
+			&cli.StringFlag{
+				Name:  FLAG_SIGN_KEY,
+				Usage: "the PEM encoded ed25519 private key to sign the pushed package",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			return KpmPush(c, kpmcli)
@@ -54,10 +59,10 @@ func KpmPush(c *cli.Context, kpmcli *client.KpmClient) error {
 	if len(localTarPath) == 0 {
 		// If the tar package to be pushed is not specified,
 		// the current kcl package is packaged into tar and pushed.
-		err = pushCurrentPackage(ociUrl, c.Bool(FLAG_VENDOR), kpmcli)
+		err = pushCurrentPackage(ociUrl, c.Bool(FLAG_VENDOR), c.String(FLAG_SIGN_KEY), kpmcli)
 	} else {
 		// Else push the tar package specified.
-		err = pushTarPackage(ociUrl, localTarPath, c.Bool(FLAG_VENDOR), kpmcli)
+		err = pushTarPackage(ociUrl, localTarPath, c.Bool(FLAG_VENDOR), c.String(FLAG_SIGN_KEY), kpmcli)
 	}
 
 	if err != nil {
@@ -82,7 +87,7 @@ func genDefaultOciUrlForKclPkg(pkg *pkg.KclPkg, kpmcli *client.KpmClient) (strin
 }
 
 // pushCurrentPackage will push the current package to the oci registry.
-func pushCurrentPackage(ociUrl string, vendorMode bool, kpmcli *client.KpmClient) error {
+func pushCurrentPackage(ociUrl string, vendorMode bool, signingKey string, kpmcli *client.KpmClient) error {
 	pwd, err := os.Getwd()
 
 	if err != nil {
@@ -103,12 +108,12 @@ func pushCurrentPackage(ociUrl string, vendorMode bool, kpmcli *client.KpmClient
 	}
 
 	// 2. push the package
-	return pushPackage(ociUrl, kclPkg, vendorMode, kpmcli)
+	return pushPackage(ociUrl, kclPkg, vendorMode, signingKey, kpmcli)
 }
 
 // pushTarPackage will push the kcl package in tarPath to the oci registry.
 // If the tar in 'tarPath' is not a kcl package tar, pushTarPackage will return an error.
-func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, kpmcli *client.KpmClient) error {
+func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, signingKey string, kpmcli *client.KpmClient) error {
 	var kclPkg *pkg.KclPkg
 	var err error
 
@@ -134,15 +139,15 @@ func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, kpmcli *client
 	}
 
 	// 2. push the package
-	return pushPackage(ociUrl, kclPkg, vendorMode, kpmcli)
+	return pushPackage(ociUrl, kclPkg, vendorMode, signingKey, kpmcli)
 }
 
 // pushPackage will push the kcl package to the oci registry.
 // 1. pushPackage will package the current kcl package into default tar path.
 // 2. If the oci url is not specified, generate the default oci url from the current package.
 // 3. Generate the OCI options from oci url and the version of current kcl package.
-// 4. Push the package to the oci registry.
-func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, kpmcli *client.KpmClient) error {
+// 4. Push the package to the oci registry, and sign it if the signing key is specified.
+func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, signingKey string, kpmcli *client.KpmClient) error {
 	// If the oci url is not specified, generate the default oci url from the current package.
 	var err error
 	if len(ociUrl) == 0 {
@@ -179,6 +184,7 @@ func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, kpmcli *cli
 			},
 		),
 		client.WithPushVendorMode(vendorMode),
+		client.WithPushSigningKey(signingKey),
 	)
 	if err != (*reporter.KpmEvent)(nil) {
 		return err
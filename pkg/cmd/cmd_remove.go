@@ -0,0 +1,91 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/env"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewRemoveCmd new a Command for `kpm remove`.
+func NewRemoveCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "remove",
+		Usage:     "remove dependencies from kcl.mod and kcl.mod.lock",
+		ArgsUsage: "<name>...",
+		Flags: []cli.Flag{
+			// '--vendor' will trigger the vendor mode
+			// In the vendor mode, the removed dependencies will also be cleaned from the subdirectory 'vendor'.
+			&cli.BoolFlag{
+				Name:  FLAG_VENDOR,
+				Usage: "remove in vendor mode",
+			},
+			&cli.BoolFlag{
+				Name:  FLAG_NO_SUM_CHECK,
+				Usage: "do not check the checksum of the package and update kcl.mod.lock",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmRemove(c, kpmcli)
+		},
+	}
+}
+
+func KpmRemove(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() == 0 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("the name of the dependency to be removed is required"),
+		)
+	}
+
+	kpmcli.SetNoSumCheck(c.Bool(FLAG_NO_SUM_CHECK))
+
+	// acquire the lock of the package cache.
+	err := kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+	}
+
+	kclPkg, err := kpmcli.LoadPkgFromPath(pwd)
+	if err != nil {
+		return err
+	}
+
+	globalPkgPath, err := env.GetAbsPkgPath()
+	if err != nil {
+		return err
+	}
+
+	err = kclPkg.ValidateKpmHome(globalPkgPath)
+	if err != (*reporter.KpmEvent)(nil) {
+		return err
+	}
+
+	kclPkg.SetVendorMode(c.Bool(FLAG_VENDOR))
+	kclPkg.NoSumCheck = kpmcli.GetNoSumCheck()
+
+	return kpmcli.Remove(
+		client.WithRemoveKclPkg(kclPkg),
+		client.WithRemoveDepNames(c.Args().Slice()...),
+	)
+}
@@ -46,6 +46,11 @@ func NewRunCmd(kpmcli *client.KpmClient) *cli.Command {
 				Name:  FLAG_NO_SUM_CHECK,
 				Usage: "do not check the checksum of the package and update kcl.mod.lock",
 			},
+			// '--profile prod' compiles with the options in '[profile.prod]' of kcl.mod.
+			&cli.StringFlag{
+				Name:  FLAG_PROFILE,
+				Usage: "the profile in kcl.mod to compile with, e.g. 'prod' for '[profile.prod]'",
+			},
 
 			// KCL arg: --setting, -Y
 			&cli.StringSliceFlag{
@@ -169,6 +174,9 @@ func CompileOptionFromCli(c *cli.Context) *opt.CompileOptions {
 	// --vendor
 	opts.SetVendor(c.Bool(FLAG_VENDOR))
 
+	// --profile
+	opts.SetProfile(c.String(FLAG_PROFILE))
+
 	// --setting, -Y
 	settingsOpt := c.StringSlice(FLAG_SETTING)
 	if len(settingsOpt) != 0 {
@@ -0,0 +1,74 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewSchemaCmd new a Command for `kpm schema`.
+func NewSchemaCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "schema",
+		Usage:     "print the schema types of a kcl package in json",
+		ArgsUsage: "[<local path> | <tar path> | oci://<reg>/<repo>?tag=<tag> | git://<url>?tag=<tag>]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_SCHEMA_NAME,
+				Usage: "print only the schema types of the name",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmSchema(c, kpmcli)
+		},
+	}
+}
+
+func KpmSchema(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() > 1 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("only allows one package at a time"),
+		)
+	}
+
+	// acquire the lock of the package cache.
+	err := kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	// 'kpm schema' prints the schema types of the package under '$pwd'.
+	source := c.Args().First()
+	if source == "" {
+		source, err = os.Getwd()
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+		}
+	}
+
+	jsonStr, err := kpmcli.SchemaInJsonStr(
+		client.WithSchemaSourceUrl(source),
+		client.WithSchemaName(c.String(FLAG_SCHEMA_NAME)),
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(jsonStr)
+	return nil
+}
@@ -24,14 +24,33 @@ import (
 // NewUpdateCmd new a Command for `kpm update`.
 func NewUpdateCmd(kpmcli *client.KpmClient) *cli.Command {
 	return &cli.Command{
-		Hidden: false,
-		Name:   "update",
-		Usage:  "Update dependencies listed in kcl.mod.lock based on kcl.mod",
+		Hidden:    false,
+		Name:      "update",
+		Usage:     "Update dependencies listed in kcl.mod.lock based on kcl.mod",
+		ArgsUsage: "[name...]",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  FLAG_NO_SUM_CHECK,
 				Usage: "do not check the checksum of the package and update kcl.mod.lock",
 			},
+			// '--upgrade' upgrades the dependencies given by the arguments.
+			&cli.BoolFlag{
+				Name:  FLAG_UPGRADE,
+				Usage: "upgrade the dependencies given by the arguments",
+			},
+			// '--upgrade-all' upgrades all the dependencies in the build list.
+			&cli.BoolFlag{
+				Name:  FLAG_UPGRADE_ALL,
+				Usage: "upgrade all the dependencies",
+			},
+			&cli.BoolFlag{
+				Name:  FLAG_COMPATIBLE,
+				Usage: "upgrade to the latest version with the same major version (default)",
+			},
+			&cli.BoolFlag{
+				Name:  FLAG_LATEST,
+				Usage: "upgrade to the latest version",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			return KpmUpdate(c, kpmcli)
@@ -40,6 +59,38 @@ func NewUpdateCmd(kpmcli *client.KpmClient) *cli.Command {
 }
 
 func KpmUpdate(c *cli.Context, kpmcli *client.KpmClient) error {
+	upgrade := c.Bool(FLAG_UPGRADE) || c.Bool(FLAG_UPGRADE_ALL)
+	if c.Bool(FLAG_UPGRADE) && c.Bool(FLAG_UPGRADE_ALL) {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("'--%s' and '--%s' cannot be used together", FLAG_UPGRADE, FLAG_UPGRADE_ALL),
+		)
+	}
+	if c.Bool(FLAG_UPGRADE) && c.NArg() == 0 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("the names of the dependencies to be upgraded are required, use '--%s' to upgrade all the dependencies", FLAG_UPGRADE_ALL),
+		)
+	}
+	if !c.Bool(FLAG_UPGRADE) && c.NArg() != 0 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("the dependencies can only be given with '--%s'", FLAG_UPGRADE),
+		)
+	}
+	if c.Bool(FLAG_COMPATIBLE) && c.Bool(FLAG_LATEST) {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("'--%s' and '--%s' cannot be used together", FLAG_COMPATIBLE, FLAG_LATEST),
+		)
+	}
+	if !upgrade && (c.Bool(FLAG_COMPATIBLE) || c.Bool(FLAG_LATEST)) {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("'--%s' and '--%s' are only available with '--%s' or '--%s'", FLAG_COMPATIBLE, FLAG_LATEST, FLAG_UPGRADE, FLAG_UPGRADE_ALL),
+		)
+	}
+
 	kpmcli.SetNoSumCheck(c.Bool(FLAG_NO_SUM_CHECK))
 
 	// acquire the lock of the package cache.
@@ -76,6 +127,10 @@ func KpmUpdate(c *cli.Context, kpmcli *client.KpmClient) error {
 		return err
 	}
 
+	if upgrade {
+		return upgradeDeps(kpmcli, kclPkg, c.Args().Slice(), c.Bool(FLAG_LATEST))
+	}
+
 	err = kpmcli.UpdateDeps(kclPkg)
 	if err != nil {
 		return err
@@ -83,6 +138,74 @@ func KpmUpdate(c *cli.Context, kpmcli *client.KpmClient) error {
 	return nil
 }
 
+// upgradeDeps upgrades the dependencies named 'depNames' or all the dependencies if 'depNames' is empty,
+// to the latest compatible version or to the latest version if 'latest' is true.
+// The versions are selected by MVS and the dependencies are upgraded by the client.
+func upgradeDeps(kpmcli *client.KpmClient, kclPkg *pkg.KclPkg, depNames []string, latest bool) error {
+	modDeps := kclPkg.ModFile.Dependencies.Deps
+	lockDeps := kclPkg.Dependencies.Deps
+	for _, depName := range depNames {
+		if _, ok := modDeps.Get(depName); !ok {
+			return reporter.NewErrorEvent(
+				reporter.DependencyNotFound,
+				fmt.Errorf("dependency '%s' not found in '%s'", depName, kclPkg.ModFile.GetModFilePath()),
+			)
+		}
+	}
+
+	// Build the requirement graph from a copy of the package,
+	// for downloading the dependencies into the graph changes the dependencies of the package.
+	graphPkg, err := kpmcli.LoadPkgFromPath(kclPkg.HomePath)
+	if err != nil {
+		return err
+	}
+	_, depGraph, err := kpmcli.InitGraphAndDownloadDeps(graphPkg)
+	if err != nil {
+		return err
+	}
+
+	reqsGraph := mvs.ReqsGraph{Graph: depGraph, KpmClient: kpmcli, KpmPkg: graphPkg}
+	var reqs mvs.UpdateReqs = reqsGraph
+	if latest {
+		reqs = mvs.LatestReqsGraph{ReqsGraph: reqsGraph}
+	}
+	target := module.Version{Path: kclPkg.GetPkgName(), Version: kclPkg.GetPkgVersion()}
+
+	var modulesToUpgrade []module.Version
+	for _, depName := range depNames {
+		dep, ok := lockDeps.Get(depName)
+		if !ok {
+			dep, _ = modDeps.Get(depName)
+		}
+		upgraded, err := reqs.Upgrade(module.Version{Path: depName, Version: dep.Version})
+		if err != nil {
+			return reporter.NewErrorEvent(
+				reporter.FailedSelectLatestCompatibleVersion,
+				err,
+				fmt.Sprintf("failed to select the version to upgrade '%s' to", depName),
+			)
+		}
+		if upgraded.Version != dep.Version {
+			modulesToUpgrade = append(modulesToUpgrade, upgraded)
+		}
+	}
+	if len(depNames) != 0 && len(modulesToUpgrade) == 0 {
+		reporter.ReportMsgTo("all dependencies are up to date", kpmcli.GetLogWriter())
+		return nil
+	}
+
+	buildList, err := mvs.UpdateBuildList(target, modulesToUpgrade, nil, reqs)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedUpdatingBuildList, err, "failed to update the build list")
+	}
+
+	_, err = kpmcli.Upgrade(
+		client.WithUpgradedKclPkg(kclPkg),
+		client.WithUpgradedVersions(buildList),
+	)
+	return err
+}
+
 // GetModulesToUpdate validates if the packages is present in kcl.mod file and
 // find the latest version if version is not specified. Depending on the value of pkgVersion,
 // modulesToUpgrade or modulesToDowngrade will be updated.
@@ -0,0 +1,36 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// SetUsageErrorHandler reports the errors of parsing the flags of the app and its commands as invalid flags,
+// so that kpm exits with the code of the invalid arguments instead of the general failure.
+func SetUsageErrorHandler(app *cli.App) {
+	app.OnUsageError = onUsageError
+	setCommandsUsageErrorHandler(app.Commands)
+}
+
+// setCommandsUsageErrorHandler sets the handler of the usage errors for the commands and their subcommands.
+func setCommandsUsageErrorHandler(commands []*cli.Command) {
+	for _, command := range commands {
+		if command.OnUsageError == nil {
+			command.OnUsageError = onUsageError
+		}
+		setCommandsUsageErrorHandler(command.Subcommands)
+	}
+}
+
+// onUsageError returns the error of parsing the flags as an 'InvalidFlag' event.
+func onUsageError(c *cli.Context, err error, isSubcommand bool) error {
+	return reporter.NewErrorEvent(
+		reporter.InvalidFlag,
+		err,
+		fmt.Sprintf("run '%s --help' for the usage", c.Command.HelpName),
+	)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+)
+
+func TestUsageErrorIsInvalidArguments(t *testing.T) {
+	kpmcli, err := client.NewKpmClient()
+	assert.NoError(t, err)
+
+	app := &cli.App{
+		Name: "kpm",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: FLAG_JOBS},
+		},
+		Commands: []*cli.Command{
+			NewCacheCmd(kpmcli),
+		},
+	}
+	SetUsageErrorHandler(app)
+
+	for _, args := range [][]string{
+		{"kpm", "--not-exist"},
+		{"kpm", "--jobs", "many"},
+		{"kpm", "cache", "clean", "--not-exist"},
+	} {
+		err = app.Run(args)
+		if assert.Error(t, err, args) {
+			assert.ErrorIs(t, err, kpmerrors.InvalidArguments, args)
+			assert.Equal(t, kpmerrors.ExitInvalidArguments, kpmerrors.ExitCode(err), args)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/env"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewVendorCmd new a Command for `kpm vendor`.
+func NewVendorCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden: false,
+		Name:   "vendor",
+		Usage:  "vendor the dependencies listed in kcl.mod.lock into the vendor directory",
+		Flags: []cli.Flag{
+			// '--verify' checks the vendored dependencies against the checksums in kcl.mod.lock.
+			&cli.BoolFlag{
+				Name:  FLAG_VERIFY,
+				Usage: "verify the vendored dependencies against the checksums in kcl.mod.lock without changing them",
+			},
+			// '--prune' deletes the vendored dependencies no longer in kcl.mod.lock.
+			&cli.BoolFlag{
+				Name:  FLAG_PRUNE,
+				Usage: "delete the vendored dependencies which are not in kcl.mod.lock",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmVendor(c, kpmcli)
+		},
+	}
+}
+
+func KpmVendor(c *cli.Context, kpmcli *client.KpmClient) (err error) {
+	if c.Bool(FLAG_VERIFY) && c.Bool(FLAG_PRUNE) {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("'--%s' and '--%s' cannot be used together", FLAG_VERIFY, FLAG_PRUNE),
+		)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+	}
+
+	kclPkg, err := kpmcli.LoadPkgFromPath(pwd)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool(FLAG_VERIFY) {
+		err = kpmcli.VerifyVendor(kclPkg)
+		if err != nil {
+			return err
+		}
+		reporter.ReportMsgTo("all vendored dependencies verified", kpmcli.GetLogWriter())
+		return nil
+	}
+
+	// acquire the lock of the package cache.
+	err = kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	globalPkgPath, err := env.GetAbsPkgPath()
+	if err != nil {
+		return err
+	}
+
+	err = kclPkg.ValidateKpmHome(globalPkgPath)
+	if err != (*reporter.KpmEvent)(nil) {
+		return err
+	}
+
+	pruned, err := kpmcli.Vendor(
+		client.WithVendorKclPkg(kclPkg),
+		client.WithVendorPrune(c.Bool(FLAG_PRUNE)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(pruned) != 0 {
+		reporter.ReportMsgTo(fmt.Sprintf("pruned 'vendor/%s'", strings.Join(pruned, "', 'vendor/")), kpmcli.GetLogWriter())
+	}
+	reporter.ReportMsgTo(fmt.Sprintf("dependencies vendored, see 'vendor/%s'", constants.VENDOR_MODULES_TXT), kpmcli.GetLogWriter())
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/env"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewWhyCmd new a Command for `kpm why`.
+func NewWhyCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "why",
+		Usage:     "explain why a module is in the build list",
+		ArgsUsage: "<name>",
+		Action: func(c *cli.Context) error {
+			return KpmWhy(c, kpmcli)
+		},
+	}
+}
+
+func KpmWhy(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() != 1 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("the name of exactly one module is required"),
+		)
+	}
+	name := c.Args().First()
+
+	// acquire the lock of the package cache.
+	err := kpmcli.AcquirePackageCacheLock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// release the lock of the package cache after the function returns.
+		releaseErr := kpmcli.ReleasePackageCacheLock()
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, please contact us to fix it.")
+	}
+
+	globalPkgPath, err := env.GetAbsPkgPath()
+	if err != nil {
+		return err
+	}
+
+	kclPkg, err := kpmcli.LoadPkgFromPath(pwd)
+	if err != nil {
+		return err
+	}
+
+	err = kclPkg.ValidateKpmHome(globalPkgPath)
+	if err != (*reporter.KpmEvent)(nil) {
+		return err
+	}
+
+	paths, err := kpmcli.Why(name, client.WithGraphMod(kclPkg))
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return reporter.NewErrorEvent(
+			reporter.DependencyNotFound,
+			fmt.Errorf("module '%s' is not in the build list of '%s'", name, kclPkg.GetPkgName()),
+		)
+	}
+
+	reporter.ReportMsgTo(fmt.Sprintf("# %s", name), kpmcli.GetLogWriter())
+	for _, path := range paths {
+		reporter.ReportMsgTo(formatDepChain(path), kpmcli.GetLogWriter())
+	}
+
+	return nil
+}
+
+// formatDepChain formats the dependency chain to string,
+// e.g. 'pkg@0.0.1 -> dep@0.0.1 (local) -> helloworld@0.1.4 (oci)'.
+func formatDepChain(chain []pkg.Dependency) string {
+	nodes := make([]string, 0, len(chain))
+	for _, dep := range chain {
+		node := dep.Name
+		if dep.Version != "" {
+			node += "@" + dep.Version
+		}
+		if sourceType := dep.GetSourceType(); sourceType != "" {
+			node += fmt.Sprintf(" (%s)", sourceType)
+		}
+		nodes = append(nodes, node)
+	}
+	return strings.Join(nodes, " -> ")
+}
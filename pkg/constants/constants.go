@@ -28,6 +28,8 @@ const (
 	KCL_MOD                              = "kcl.mod"
 	KCL_MOD_LOCK                         = "kcl.mod.lock"
 	KCL_YAML                             = "kcl.yaml"
+	KCL_IGNORE                           = ".kclignore"
+	VENDOR_MODULES_TXT                   = "modules.txt"
 	OCI_SEPARATOR                        = ":"
 	KCL_PKG_TAR                          = "*.tar"
 	DEFAULT_KCL_FILE_NAME                = "main.k"
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/otiai10/copy"
 	"kcl-lang.io/kpm/pkg/constants"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/features"
 	"kcl-lang.io/kpm/pkg/git"
 	"kcl-lang.io/kpm/pkg/oci"
@@ -45,6 +49,64 @@ type DownloadOptions struct {
 
 type Option func(*DownloadOptions)
 
+// newEvent returns an event about the package being downloaded, with the module name and the source of the package.
+func (opts *DownloadOptions) newEvent(eventType reporter.EventType, msg string) *reporter.KpmEvent {
+	return withSource(reporter.NewEvent(eventType, msg), opts.Source)
+}
+
+// withSource sets the module name and the source of the package to the event.
+func withSource(event *reporter.KpmEvent, source Source) *reporter.KpmEvent {
+	if sourceStr, err := source.ToString(); err == nil {
+		event.WithSource(sourceStr)
+	}
+	if module := sourceModule(source); module != "" {
+		event.WithModule(module)
+	}
+	return event
+}
+
+// sourceModule returns the name of the module from the source.
+func sourceModule(source Source) string {
+	switch {
+	case source.ModSpec != nil && source.ModSpec.Name != "":
+		return source.ModSpec.Name
+	case source.Oci != nil:
+		return path.Base(source.Oci.Repo)
+	case source.Git != nil:
+		return strings.TrimSuffix(path.Base(source.Git.Url), ".git")
+	}
+	return ""
+}
+
+// sourceVersion returns the version of the module from the source, empty if the latest version is required.
+func sourceVersion(source Source) string {
+	switch {
+	case source.ModSpec != nil && source.ModSpec.Version != "":
+		return source.ModSpec.Version
+	case source.Oci != nil:
+		return source.Oci.GetRef()
+	case source.Git != nil:
+		return source.Git.GetRef()
+	}
+	return ""
+}
+
+// NewNotFoundOfflineError returns the error that the module from the source is not found in the cache in offline mode,
+// the error is an event with the name, the version and the source of the missing module.
+func NewNotFoundOfflineError(source Source) error {
+	module := sourceModule(source)
+	var msg string
+	if version := sourceVersion(source); version != "" {
+		msg = fmt.Sprintf("module '%s' version '%s' is not found in the cache", module, version)
+	} else {
+		msg = fmt.Sprintf("the latest version of module '%s' is unknown", module)
+	}
+	if sourceStr, err := source.ToString(); err == nil && sourceStr != "" {
+		msg = fmt.Sprintf("%s, required from '%s'", msg, sourceStr)
+	}
+	return withSource(reporter.NewErrorEvent(reporter.NotFoundOffline, ErrNotFoundAndOffline, msg), source)
+}
+
 func WithOffline(offline bool) Option {
 	return func(do *DownloadOptions) {
 		do.Offline = offline
@@ -118,40 +180,85 @@ type Downloader interface {
 
 func (d *DepDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 	if opts.Source.Oci != nil {
-		if d.OciDownloader == nil {
-			d.OciDownloader = &OciDownloader{}
-		}
-		return d.OciDownloader.LatestVersion(opts)
+		return d.ociDownloader().LatestVersion(opts)
 	}
 
 	if opts.Source.Git != nil {
-		if d.GitDownloader == nil {
-			d.GitDownloader = &GitDownloader{}
-		}
-		return d.GitDownloader.LatestVersion(opts)
+		return d.gitDownloader().LatestVersion(opts)
 	}
 
 	return "", errors.New("source is nil")
 }
 
+// VersionLister is the interface for listing all the versions of a remote source.
+// It is optionally implemented by the Downloader.
+type VersionLister interface {
+	// Get all the versions of the remote source
+	// For the git source, it will return all the tags
+	// For the OCI source, it will return all the tags
+	Versions(opts *DownloadOptions) ([]string, error)
+}
+
+func (d *DepDownloader) Versions(opts *DownloadOptions) ([]string, error) {
+	if opts.Source.Oci != nil {
+		return d.ociDownloader().Versions(opts)
+	}
+
+	if opts.Source.Git != nil {
+		return d.gitDownloader().Versions(opts)
+	}
+
+	return nil, errors.New("source is nil")
+}
+
 // DepDownloader is the downloader for the package.
 // Only support the OCI and git source.
+// It is safe for concurrent use.
 type DepDownloader struct {
 	*OciDownloader
 	*GitDownloader
 }
 
+// ociDownloader returns the downloader for the OCI source, or the default one if it is not set.
+func (d *DepDownloader) ociDownloader() *OciDownloader {
+	if d.OciDownloader == nil {
+		return &OciDownloader{}
+	}
+	return d.OciDownloader
+}
+
+// gitDownloader returns the downloader for the git source, or the default one if it is not set.
+func (d *DepDownloader) gitDownloader() *GitDownloader {
+	if d.GitDownloader == nil {
+		return &GitDownloader{}
+	}
+	return d.GitDownloader
+}
+
 // GitDownloader is the downloader for the git source.
 type GitDownloader struct{}
 
+// Versions returns all the tags of the git source.
+func (d *GitDownloader) Versions(opts *DownloadOptions) ([]string, error) {
+	if opts.Offline {
+		return nil, errors.New("offline mode is enabled, the versions of the remote source are not supported")
+	}
+	gitUrl, err := opts.Source.Git.GetCanonicalizedUrl()
+	if err != nil {
+		return nil, err
+	}
+	return git.ListRemoteTags(opts.Settings.Mirror(gitUrl))
+}
+
 func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 	if opts.Offline {
-		return "", errors.New("offline mode is enabled, the latest version is not supported")
+		return "", NewNotFoundOfflineError(opts.Source)
 	}
 	gitUrl, err := opts.Source.Git.GetCanonicalizedUrl()
 	if err != nil {
 		return "", err
 	}
+	gitUrl = opts.Settings.Mirror(gitUrl)
 	// TODO：supports fetch the latest commit from the git bare repo,
 	// after totally transfer to the new storage.
 	// refer to cargo: https://github.com/rust-lang/cargo/blob/3dedb85a25604bdbbb8d3bf4b03162961a4facd0/crates/cargo-util-schemas/src/core/source_kind.rs#L133
@@ -232,13 +339,36 @@ type OciDownloader struct {
 
 func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 	if opts.Offline {
-		return "", errors.New("offline mode is enabled, the latest version is not supported")
+		return "", NewNotFoundOfflineError(opts.Source)
 	}
 
-	// download the package from the OCI registry
+	ociCli, err := d.newOciClient(opts)
+	if err != nil {
+		return "", err
+	}
+
+	return ociCli.TheLatestTag()
+}
+
+// Versions returns all the tags of the OCI source.
+func (d *OciDownloader) Versions(opts *DownloadOptions) ([]string, error) {
+	if opts.Offline {
+		return nil, errors.New("offline mode is enabled, the versions of the remote source are not supported")
+	}
+
+	ociCli, err := d.newOciClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ociCli.AllTags()
+}
+
+// newOciClient creates the OCI client for the OCI source in the download options.
+func (d *OciDownloader) newOciClient(opts *DownloadOptions) (*oci.OciClient, error) {
 	ociSource := opts.Source.Oci
 	if ociSource == nil {
-		return "", errors.New("oci source is nil")
+		return nil, errors.New("oci source is nil")
 	}
 
 	// Host-less OCI dependency: resolve the registry host from
@@ -247,14 +377,16 @@ func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 		ociSource.Reg = opts.Settings.DefaultOciRegistry()
 	}
 
-	repoPath := utils.JoinPath(ociSource.Reg, ociSource.Repo)
+	// The package is downloaded from the mirror of the OCI source if any.
+	reg, repo := mirrorOci(&opts.Settings, ociSource)
+	repoPath := utils.JoinPath(reg, repo)
 
 	var cred *remoteauth.Credential
 	var err error
 	if opts.credsStore != nil {
-		cred, err = opts.credsStore.Credential(ociSource.Reg)
+		cred, err = opts.credsStore.Credential(reg)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	} else {
 		cred = &remoteauth.Credential{}
@@ -268,12 +400,20 @@ func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 	)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	ociCli.PullOciOptions.Platform = d.Platform
 
-	return ociCli.TheLatestTag()
+	return ociCli, nil
+}
+
+// mirrorOci returns the registry and repo of the mirror of the OCI source by the mirror rules in the settings,
+// or the registry and repo of the OCI source itself if no rule matches.
+func mirrorOci(settings *settings.Settings, ociSource *Oci) (string, string) {
+	ref := settings.Mirror(utils.JoinPath(ociSource.Reg, ociSource.Repo))
+	reg, repo, _ := strings.Cut(ref, "/")
+	return reg, repo
 }
 
 func NewOciDownloader(platform string) *DepDownloader {
@@ -310,6 +450,7 @@ func (d *DepDownloader) Download(opts *DownloadOptions) error {
 					return err
 				}
 			}
+			d.ociDownloader().resolveCachedDigest(opts)
 			return nil
 		} else {
 			err := os.MkdirAll(cacheFullPath, 0755)
@@ -322,29 +463,26 @@ func (d *DepDownloader) Download(opts *DownloadOptions) error {
 	// If the dependency package is already exist,
 	// Skip the download process.
 	if dependencyPackageExists(localPath) {
+		d.ociDownloader().resolveCachedDigest(opts)
 		return nil
 	} else {
 		opts.LocalPath = tmpDir
+		start := time.Now()
 		// Dispatch the download to the specific downloader by package source.
 		if opts.Source.Oci != nil {
-			if d.OciDownloader == nil {
-				d.OciDownloader = &OciDownloader{}
-			}
-			err := d.OciDownloader.Download(opts)
+			err := d.ociDownloader().Download(opts)
 			if err != nil {
-				return err
+				return kpmerrors.Wrap(kpmerrors.FailedDownloadError, err)
 			}
 		}
 
 		if opts.Source.Git != nil {
-			if d.GitDownloader == nil {
-				d.GitDownloader = &GitDownloader{}
-			}
-			err := d.GitDownloader.Download(opts)
+			err := d.gitDownloader().Download(opts)
 			if err != nil {
-				return err
+				return kpmerrors.Wrap(kpmerrors.FailedDownloadError, err)
 			}
 		}
+		reporter.EmitTo(opts.newEvent(reporter.DownloadFinished, "").WithDuration(time.Since(start)), opts.LogWriter)
 
 		// rename the tmp dir to the local path.
 		if utils.DirExists(localPath) {
@@ -395,39 +533,14 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 
 	localPath := opts.LocalPath
 
-	// Host-less OCI dependency: resolve the registry host from
-	// KPM_REG / DefaultOciRegistry when it was not declared in the source.
-	if len(ociSource.Reg) == 0 {
-		ociSource.Reg = opts.Settings.DefaultOciRegistry()
-	}
-
-	repoPath := utils.JoinPath(ociSource.Reg, ociSource.Repo)
-
-	var cred *remoteauth.Credential
-	var err error
-	if opts.credsStore != nil {
-		cred, err = opts.credsStore.Credential(ociSource.Reg)
-		if err != nil {
-			return err
-		}
-	} else {
-		cred = &remoteauth.Credential{}
-	}
-
-	ociCli, err := oci.NewOciClientWithOpts(
-		oci.WithCredential(cred),
-		oci.WithRepoPath(repoPath),
-		oci.WithSettings(&opts.Settings),
-		oci.WithInsecureSkipTLSverify(opts.InsecureSkipTLSverify),
-	)
-
+	ociCli, err := d.newOciClient(opts)
 	if err != nil {
 		return err
 	}
+	// The source is kept canonical and the mirror is only used to download the package.
+	mirrorReg, mirrorRepo := mirrorOci(&opts.Settings, ociSource)
 
-	ociCli.PullOciOptions.Platform = d.Platform
-
-	if len(ociSource.Tag) == 0 {
+	if ociSource.NoRef() {
 		tagSelected, err := ociCli.TheLatestTag()
 		if err != nil {
 			return err
@@ -448,19 +561,20 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 
 			if utils.DirExists(localFullPath) &&
 				utils.DirExists(filepath.Join(localFullPath, constants.KCL_MOD)) {
+				d.resolveCachedDigest(opts)
 				return nil
 			} else {
 				cacheTarPath, err := utils.FindPkgArchive(cacheFullPath)
 				if err != nil && errors.Is(err, utils.PkgArchiveNotFound) {
-					reporter.ReportMsgTo(
-						fmt.Sprintf(
+					reporter.ReportEventTo(
+						opts.newEvent(reporter.DownloadingFromOCI, fmt.Sprintf(
 							"downloading '%s:%s' from '%s/%s:%s'",
-							ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
-						),
+							ociSource.Repo, ociSource.GetRef(), mirrorReg, mirrorRepo, ociSource.GetRef(),
+						)),
 						opts.LogWriter,
 					)
 
-					err = ociCli.Pull(cacheFullPath, ociSource.Tag)
+					err = d.pull(ociCli, opts, cacheFullPath)
 					if err != nil {
 						return err
 					}
@@ -470,6 +584,8 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 					}
 				} else if err != nil {
 					return err
+				} else {
+					d.resolveCachedDigest(opts)
 				}
 
 				if utils.IsTar(cacheTarPath) {
@@ -482,15 +598,15 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 				}
 			}
 		} else if !opts.Offline {
-			reporter.ReportMsgTo(
-				fmt.Sprintf(
+			reporter.ReportEventTo(
+				opts.newEvent(reporter.DownloadingFromOCI, fmt.Sprintf(
 					"downloading '%s:%s' from '%s/%s:%s'",
-					ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
-				),
+					ociSource.Repo, ociSource.GetRef(), mirrorReg, mirrorRepo, ociSource.GetRef(),
+				)),
 				opts.LogWriter,
 			)
 
-			err = ociCli.Pull(localPath, ociSource.Tag)
+			err = d.pull(ociCli, opts, localPath)
 			if err != nil {
 				return err
 			}
@@ -516,15 +632,15 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 			}
 		}
 	} else if !opts.Offline {
-		reporter.ReportMsgTo(
-			fmt.Sprintf(
+		reporter.ReportEventTo(
+			opts.newEvent(reporter.DownloadingFromOCI, fmt.Sprintf(
 				"downloading '%s:%s' from '%s/%s:%s'",
-				ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
-			),
+				ociSource.Repo, ociSource.GetRef(), mirrorReg, mirrorRepo, ociSource.GetRef(),
+			)),
 			opts.LogWriter,
 		)
 
-		err = ociCli.Pull(localPath, ociSource.Tag)
+		err = d.pull(ociCli, opts, localPath)
 		if err != nil {
 			return err
 		}
@@ -552,12 +668,67 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 	}
 
 	if opts.Offline && !utils.DirExists(filepath.Join(opts.LocalPath, constants.KCL_MOD)) {
-		return ErrNotFoundAndOffline
+		return NewNotFoundOfflineError(opts.Source)
 	}
 
 	return err
 }
 
+// resolveCachedDigest pins the OCI source of the package found in the cache to the digest of the manifest of its tag
+// resolved from the registry, for the digest is only recorded when the package is pulled, so that the digest
+// of the package cached is also recorded in kcl.mod.lock. It does nothing in offline mode or if the source is pinned.
+// The errors are ignored, the package cached is used and its digest is resolved again next time.
+func (d *OciDownloader) resolveCachedDigest(opts *DownloadOptions) {
+	ociSource := opts.Source.Oci
+	if ociSource == nil || ociSource.Digest != "" || ociSource.Tag == "" || opts.Offline {
+		return
+	}
+	ociCli, err := d.newOciClient(opts)
+	if err != nil {
+		return
+	}
+	digest, err := ociCli.ResolveDigest(ociSource.Tag)
+	if err != nil {
+		return
+	}
+	ociSource.Digest = digest
+	ociSource.DigestResolved = true
+}
+
+// pull pulls the package of the OCI source into the local path by the tag, or by the digest if only the digest is specified.
+// The digest of the manifest pulled is checked against the digest of the source, so that a retagged package is refused,
+// and recorded in the source if the source is not pinned to a digest.
+func (d *OciDownloader) pull(ociCli *oci.OciClient, opts *DownloadOptions, localPath string) error {
+	ociSource := opts.Source.Oci
+	digest, err := ociCli.PullWithDigest(localPath, ociSource.GetRef())
+	if err != nil {
+		return err
+	}
+
+	if ociSource.Digest == "" {
+		ociSource.Digest = digest
+		ociSource.DigestResolved = true
+		return nil
+	}
+	if digest != ociSource.Digest {
+		// The package retagged is not kept in the local path.
+		_ = os.RemoveAll(localPath)
+		return withSource(reporter.NewErrorEvent(
+			reporter.DigestMismatch,
+			&kpmerrors.DigestMismatch{
+				Ref:      fmt.Sprintf("%s:%s", ociSource.Repo, ociSource.Tag),
+				Expected: ociSource.Digest,
+				Actual:   digest,
+			},
+			fmt.Sprintf(
+				"the tag '%s' of '%s' resolves to another manifest than the locked one, remove the digest from kcl.mod or kcl.mod.lock to accept it",
+				ociSource.Tag, ociSource.Repo,
+			),
+		), opts.Source)
+	}
+	return nil
+}
+
 func (d *GitDownloader) Download(opts *DownloadOptions) error {
 	gitSource := opts.Source.Git
 	if gitSource == nil {
@@ -568,6 +739,8 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 	if err != nil {
 		return err
 	}
+	// The repository is cloned from the mirror of the git source if any.
+	gitUrl = opts.Settings.Mirror(gitUrl)
 	cloneOpts := []git.CloneOption{
 		git.WithCommit(gitSource.Commit),
 		git.WithBranch(gitSource.Branch),
@@ -614,8 +787,8 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 							return err
 						}
 					} else if !opts.Offline {
-						reporter.ReportMsgTo(
-							fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg),
+						reporter.ReportEventTo(
+							opts.newEvent(reporter.DownloadingFromGit, fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg)),
 							opts.LogWriter,
 						)
 						// If not, clone the bare repository from the remote git repository, update the cache.
@@ -652,8 +825,8 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 				}
 			}
 		} else if !opts.Offline {
-			reporter.ReportMsgTo(
-				fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg),
+			reporter.ReportEventTo(
+				opts.newEvent(reporter.DownloadingFromGit, fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg)),
 				opts.LogWriter,
 			)
 			// If the cache is disabled, clone the repository from the remote git repository.
@@ -669,8 +842,8 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 			}
 		}
 	} else if !opts.Offline {
-		reporter.ReportMsgTo(
-			fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg),
+		reporter.ReportEventTo(
+			opts.newEvent(reporter.DownloadingFromGit, fmt.Sprintf("cloning '%s' %s", opts.Source.Git.Url, msg)),
 			opts.LogWriter,
 		)
 		// download the package from the git repo
@@ -693,10 +866,11 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 	}
 
 	if opts.Offline && !utils.DirExists(filepath.Join(opts.LocalPath, constants.KCL_MOD)) {
-		return ErrNotFoundAndOffline
+		return NewNotFoundOfflineError(opts.Source)
 	}
 
 	return nil
 }
 
-var ErrNotFoundAndOffline = errors.New("not found and offline")
+// ErrNotFoundAndOffline is the error that the module is not found in the cache in offline mode.
+var ErrNotFoundAndOffline = kpmerrors.NotFoundOffline
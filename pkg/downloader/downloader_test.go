@@ -1,14 +1,21 @@
 package downloader
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
 	"kcl-lang.io/kpm/pkg/features"
 	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/test"
 	"kcl-lang.io/kpm/pkg/utils"
 )
@@ -131,3 +138,101 @@ func TestWithGlobalLock(t *testing.T) {
 	test.RunTestWithGlobalLock(t, "TestDepDownloaderWhenPackageCacheFolderExistsButEmpty",
 		testDepDownloaderWhenPackageCacheFolderExistsButEmpty)
 }
+
+func TestDownloadFromGitMirror(t *testing.T) {
+	features.Disable(features.SupportNewStorage)
+	repoDir := t.TempDir()
+	gitRun := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=kpm", "-c", "user.email=kpm@kcl-lang.io"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NilError(t, err, string(out))
+	}
+	gitRun("init", "-q")
+	gitRun("commit", "-q", "--allow-empty", "-m", "init")
+	gitRun("tag", "v0.1.0")
+	assert.NilError(t, os.WriteFile(filepath.Join(repoDir, "kcl.mod"), []byte("[package]\nname = \"mirrored\"\n"), 0644))
+	gitRun("add", "kcl.mod")
+	gitRun("commit", "-q", "-m", "add kcl.mod")
+	gitRun("tag", "v0.2.0")
+
+	source := Source{
+		Git: &Git{
+			Url: "https://github.com/kcl-lang/not-exist-mirrored.git",
+			Tag: "v0.2.0",
+		},
+	}
+	localPath := filepath.Join(t.TempDir(), "mirrored")
+	opts := NewDownloadOptions(
+		WithSource(source),
+		WithLocalPath(localPath),
+		WithSettings(settings.Settings{
+			Conf: settings.KpmConf{
+				Mirrors: []settings.MirrorRule{
+					{Source: "https://github.com/kcl-lang/*.git", Mirror: repoDir},
+				},
+			},
+		}),
+	)
+
+	versions, err := (&GitDownloader{}).Versions(opts)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, versions, []string{"v0.1.0", "v0.2.0"})
+
+	err = (&DepDownloader{}).Download(opts)
+	assert.NilError(t, err)
+	assert.Equal(t, utils.DirExists(filepath.Join(localPath, "kcl.mod")), true)
+	// The source is kept canonical.
+	assert.Equal(t, source.Git.Url, "https://github.com/kcl-lang/not-exist-mirrored.git")
+}
+
+func TestResolveDigestOfCachedPackage(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	var resolved int
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/test/a/manifests/0.0.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resolved++
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	}))
+	defer registry.Close()
+	// The registry on localhost is requested in plain http.
+	_, port, err := net.SplitHostPort(registry.Listener.Addr().String())
+	assert.NilError(t, err)
+
+	// The package is cached in the local path.
+	localPath := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(localPath, "kcl.mod"), []byte("[package]\nname = \"a\"\nversion = \"0.0.1\"\n"), 0644))
+	download := func(offline bool, digest string) *Oci {
+		source := &Oci{Reg: "localhost:" + port, Repo: "test/a", Tag: "0.0.1", Digest: digest}
+		err := NewOciDownloader("").Download(NewDownloadOptions(
+			WithSource(Source{Oci: source}),
+			WithLocalPath(localPath),
+			WithSettings(*settings.GetSettings()),
+			WithOffline(offline),
+		))
+		assert.NilError(t, err)
+		return source
+	}
+
+	// The digest of the package cached is resolved from the registry without pulling it.
+	source := download(false, "")
+	assert.Equal(t, source.Digest, digest)
+	assert.Equal(t, source.DigestResolved, true)
+	assert.Equal(t, resolved, 1)
+
+	// The registry is not requested in offline mode or if the source is pinned.
+	source = download(true, "")
+	assert.Equal(t, source.Digest, "")
+	pinned := "sha256:" + strings.Repeat("0", 64)
+	source = download(false, pinned)
+	assert.Equal(t, source.Digest, pinned)
+	assert.Equal(t, resolved, 1)
+}
@@ -52,6 +52,14 @@ type Oci struct {
 	Reg  string `toml:"reg,omitempty"`
 	Repo string `toml:"repo,omitempty"`
 	Tag  string `toml:"oci_tag,omitempty"`
+	// Digest is the digest of the manifest, e.g. 'sha256:...', the package is pinned to.
+	// It is declared in kcl.mod by 'oci://<reg>/<repo>@<digest>' or 'digest = "<digest>"',
+	// and recorded in kcl.mod.lock for the tag once the package is downloaded.
+	// The package is refused if its tag resolves to another digest.
+	Digest string `toml:"oci_digest,omitempty"`
+	// DigestResolved is true when the digest was absent in the source declaration and is filled
+	// from kcl.mod.lock or by the download, it is recorded in kcl.mod.lock but not persisted back to kcl.mod.
+	DigestResolved bool `toml:"-"`
 	// RegFromEnv is true when the registry host was absent in the source declaration
 	// (e.g. `repo = "org/path/pkg"` in kcl.mod).  The host is resolved at runtime
 	// from KPM_REG / DefaultOciRegistry and must NOT be persisted back to kcl.mod or
@@ -61,13 +69,43 @@ type Oci struct {
 }
 
 // If the OCI source has no reference, return true.
-// TODO: add digest support.
 func (o *Oci) NoRef() bool {
-	return o.Tag == ""
+	return o.Tag == "" && o.Digest == ""
 }
 
+// GetRef returns the tag of the OCI source, or the digest if the source is only pinned to the digest.
 func (o *Oci) GetRef() string {
-	return o.Tag
+	if o.Tag != "" {
+		return o.Tag
+	}
+	return o.Digest
+}
+
+// refPath returns the reference of the OCI source used in the file paths,
+// the ':' in the digest is replaced for it is invalid in the file paths on windows.
+func (o *Oci) refPath() string {
+	return strings.ReplaceAll(o.GetRef(), ":", "_")
+}
+
+// digestLengths is the length of the hex encoded digests by the algorithms.
+var digestLengths = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// ValidateDigest checks the digest of the manifest is in the form of '<algorithm>:<hex>', e.g. 'sha256:...'.
+func ValidateDigest(digest string) error {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	length, supported := digestLengths[algorithm]
+	if !ok || !supported || len(encoded) != length {
+		return fmt.Errorf("invalid digest '%s', expected 'sha256:<hex>' or 'sha512:<hex>'", digest)
+	}
+	for _, c := range encoded {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Errorf("invalid digest '%s', expected the lowercase hex encoded digest", digest)
+		}
+	}
+	return nil
 }
 
 // Git is the package source from git registry.
@@ -319,7 +357,7 @@ func (oci *Oci) ToFilePath() (string, error) {
 		Path: oci.Repo,
 	}
 
-	return filepath.Join(constants.OciScheme, ociUrl.Host, ociUrl.Path, oci.Tag), nil
+	return filepath.Join(constants.OciScheme, ociUrl.Host, ociUrl.Path, oci.refPath()), nil
 }
 
 func (local *Local) ToFilePath() (string, error) {
@@ -394,6 +432,9 @@ func (oci *Oci) ToString() (string, error) {
 		Host:   oci.Reg,
 		Path:   oci.Repo,
 	}
+	if oci.Digest != "" {
+		ociUrl.Path = oci.Repo + "@" + oci.Digest
+	}
 	q := ociUrl.Query()
 	if oci.Tag != "" {
 		q.Set(constants.Tag, oci.Tag)
@@ -450,7 +491,9 @@ func (source *Source) FromString(sourceStr string) error {
 		source.Git.FromString(sourceUrl.String())
 	} else if sourceUrl.Scheme == constants.OciScheme {
 		source.Oci = &Oci{}
-		source.Oci.FromString(sourceUrl.String())
+		if err := source.Oci.FromString(sourceUrl.String()); err != nil {
+			return err
+		}
 	} else if sourceUrl.Scheme == constants.DefaultOciScheme {
 		source.ModSpec = &ModSpec{}
 		source.ModSpec.FromString(sourceUrl.String())
@@ -508,6 +551,14 @@ func (oci *Oci) FromString(ociStr string) error {
 	oci.Reg = u.Host
 	oci.Repo = strings.TrimPrefix(u.Path, "/")
 	oci.Tag = u.Query().Get(constants.Tag)
+	// The package pinned to the digest, e.g. 'oci://ghcr.io/org/mod@sha256:...'.
+	if repo, digest, ok := strings.Cut(oci.Repo, "@"); ok {
+		if err := ValidateDigest(digest); err != nil {
+			return err
+		}
+		oci.Repo = repo
+		oci.Digest = digest
+	}
 	// Mark host-less sources so the marshal path keeps them host-less after
 	// Reg has been temporarily filled from KPM_REG / DefaultOciRegistry.
 	if oci.Reg == "" {
@@ -671,7 +722,7 @@ func (o *Oci) Hash() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(hash, filepath.Base(o.Repo), o.GetRef()), nil
+	return filepath.Join(hash, filepath.Base(o.Repo), o.refPath()), nil
 }
 
 func (l *Local) Hash() (string, error) {
@@ -685,8 +736,8 @@ func (s *Source) LocalPath(root string) string {
 
 	var path string
 	if ok, err := features.Enabled(features.SupportNewStorage); err == nil && !ok {
-		if s.Oci != nil && len(s.Oci.Tag) != 0 {
-			path = fmt.Sprintf("%s_%s", filepath.Base(s.Oci.Repo), s.Oci.Tag)
+		if s.Oci != nil && !s.Oci.NoRef() {
+			path = fmt.Sprintf("%s_%s", filepath.Base(s.Oci.Repo), s.Oci.refPath())
 		}
 
 		if s.Git != nil && len(s.Git.Tag) != 0 {
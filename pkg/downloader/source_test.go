@@ -1,6 +1,8 @@
 package downloader
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -39,3 +41,18 @@ func TestLocalArchiveSourceDetection(t *testing.T) {
 	assert.Equal(t, tgzSource.IsLocalTgzPath(), true)
 	assert.Equal(t, tgzSource.IsPackaged(), true)
 }
+
+func TestOciDigestLocalPath(t *testing.T) {
+	digest := "sha256:5d6d7b8f3d1a4a4b2c0f8c2a5e6b7d9c1f3e2a4b6c8d0e2f4a6b8c0d2e4f6a8b"
+
+	// The tag is used in the local path of the package pinned to the tag and the digest.
+	source := &Source{Oci: &Oci{Reg: "ghcr.io", Repo: "kcl-lang/helloworld", Tag: "0.1.0", Digest: digest}}
+	assert.Equal(t, source.LocalPath("/kpm"), filepath.Join("/kpm", "helloworld_0.1.0"))
+
+	source = &Source{Oci: &Oci{Reg: "ghcr.io", Repo: "kcl-lang/helloworld", Digest: digest}}
+	assert.Equal(t, source.LocalPath("/kpm"), filepath.Join("/kpm", "helloworld_sha256_5d6d7b8f3d1a4a4b2c0f8c2a5e6b7d9c1f3e2a4b6c8d0e2f4a6b8c0d2e4f6a8b"))
+
+	assert.NilError(t, ValidateDigest(digest))
+	assert.ErrorContains(t, ValidateDigest("md5:abc"), "invalid digest")
+	assert.ErrorContains(t, ValidateDigest(digest[:7]+strings.ToUpper(digest[7:])), "invalid digest")
+}
@@ -112,6 +112,7 @@ func (git *Git) MarshalTOML() string {
 
 const OCI_URL_PATTERN = "oci = \"%s\""
 const OCI_REPO_PATTERN = "repo = \"%s\""
+const OCI_DIGEST_PATTERN = "digest = \"%s\""
 
 func (oci *Oci) MarshalTOML() string {
 	var sb strings.Builder
@@ -124,12 +125,14 @@ func (oci *Oci) MarshalTOML() string {
 			sb.WriteString(SEPARATOR)
 			sb.WriteString(fmt.Sprintf(TAG_PATTERN, oci.Tag))
 		}
+		oci.marshalDigest(&sb)
 	} else if len(oci.Reg) != 0 && len(oci.Repo) != 0 {
 		sb.WriteString(fmt.Sprintf(OCI_URL_PATTERN, oci.IntoOciUrl()))
 		if len(oci.Tag) != 0 {
 			sb.WriteString(SEPARATOR)
 			sb.WriteString(fmt.Sprintf(TAG_PATTERN, oci.Tag))
 		}
+		oci.marshalDigest(&sb)
 	} else if len(oci.Reg) == 0 && len(oci.Repo) == 0 && len(oci.Tag) != 0 {
 		sb.WriteString(fmt.Sprintf(`"%s"`, oci.Tag))
 	}
@@ -137,6 +140,15 @@ func (oci *Oci) MarshalTOML() string {
 	return sb.String()
 }
 
+// marshalDigest writes the digest the package is pinned to in kcl.mod,
+// the digest only recorded in kcl.mod.lock is not written.
+func (oci *Oci) marshalDigest(sb *strings.Builder) {
+	if len(oci.Digest) != 0 && !oci.DigestResolved {
+		sb.WriteString(SEPARATOR)
+		sb.WriteString(fmt.Sprintf(OCI_DIGEST_PATTERN, oci.Digest))
+	}
+}
+
 const LOCAL_PATH_PATTERN = "path = %s"
 
 func (local *Local) MarshalTOML() string {
@@ -225,6 +237,7 @@ const GIT_BRANCH_FLAG = "branch"
 const GIT_PACKAGE_FLAG = "package"
 const OCI_REPO_FLAG = "repo"
 const OCI_REG_FLAG = "reg"
+const OCI_DIGEST_FLAG = "digest"
 
 func (git *Git) UnmarshalModTOML(data interface{}) error {
 	meta, ok := data.(map[string]interface{})
@@ -277,6 +290,17 @@ func (oci *Oci) UnmarshalModTOML(data interface{}) error {
 			oci.Tag = v
 		}
 
+		// Pinned form: digest = "sha256:...", it must be the same as the digest in the url if any.
+		if v, ok := meta[OCI_DIGEST_FLAG].(string); ok {
+			if err := ValidateDigest(v); err != nil {
+				return err
+			}
+			if oci.Digest != "" && oci.Digest != v {
+				return fmt.Errorf("the digest '%s' is different from the digest '%s' in the oci url", v, oci.Digest)
+			}
+			oci.Digest = v
+		}
+
 		// Mark as host-less if no registry was declared; the host will be
 		// resolved from KPM_REG / DefaultOciRegistry at runtime.
 		if oci.Reg == "" && oci.Repo != "" {
@@ -2,6 +2,7 @@
 package downloader
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -145,3 +146,70 @@ func TestFromStringFullUrlDoesNotMarkRegFromEnv(t *testing.T) {
 	assert.Equal(t, oci.Repo, "kcl-lang/helloworld")
 	assert.Equal(t, oci.RegFromEnv, false)
 }
+
+const testDigest = "sha256:5d6d7b8f3d1a4a4b2c0f8c2a5e6b7d9c1f3e2a4b6c8d0e2f4a6b8c0d2e4f6a8b"
+
+// TestOciDigestUnmarshal verifies that the digest pinned by `digest = "..."` or
+// by `oci = "oci://host/repo@<digest>"` is parsed and the invalid one is rejected.
+func TestOciDigestUnmarshal(t *testing.T) {
+	oci := &Oci{}
+	err := oci.UnmarshalModTOML(map[string]interface{}{
+		"oci":    "oci://ghcr.io/kcl-lang/helloworld",
+		"tag":    "0.1.0",
+		"digest": testDigest,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, oci.Repo, "kcl-lang/helloworld")
+	assert.Equal(t, oci.Tag, "0.1.0")
+	assert.Equal(t, oci.Digest, testDigest)
+	assert.Equal(t, oci.NoRef(), false)
+
+	oci = &Oci{}
+	err = oci.UnmarshalModTOML(map[string]interface{}{
+		"oci": "oci://ghcr.io/kcl-lang/helloworld@" + testDigest,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, oci.Repo, "kcl-lang/helloworld")
+	assert.Equal(t, oci.Tag, "")
+	assert.Equal(t, oci.Digest, testDigest)
+	assert.Equal(t, oci.GetRef(), testDigest)
+
+	oci = &Oci{}
+	err = oci.UnmarshalModTOML(map[string]interface{}{
+		"oci":    "oci://ghcr.io/kcl-lang/helloworld",
+		"digest": "sha256:invalid",
+	})
+	assert.ErrorContains(t, err, "invalid digest 'sha256:invalid'")
+
+	oci = &Oci{}
+	err = oci.UnmarshalModTOML(map[string]interface{}{
+		"oci":    "oci://ghcr.io/kcl-lang/helloworld@" + testDigest,
+		"digest": "sha256:" + strings.Repeat("0", 64),
+	})
+	assert.ErrorContains(t, err, "is different from the digest")
+}
+
+// TestOciDigestMarshal verifies that the digest pinned in kcl.mod is written back to kcl.mod,
+// and the digest only resolved for kcl.mod.lock is not.
+func TestOciDigestMarshal(t *testing.T) {
+	oci := &Oci{
+		Reg:    "ghcr.io",
+		Repo:   "kcl-lang/helloworld",
+		Tag:    "0.1.0",
+		Digest: testDigest,
+	}
+	assert.Equal(t, oci.MarshalTOML(), `oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.0", digest = "`+testDigest+`"`)
+
+	oci.DigestResolved = true
+	assert.Equal(t, oci.MarshalTOML(), `oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.0"`)
+
+	// The source string keeps the digest.
+	source := &Source{Oci: &Oci{Reg: "ghcr.io", Repo: "kcl-lang/helloworld", Tag: "0.1.0", Digest: testDigest}}
+	sourceStr, err := source.ToString()
+	assert.NilError(t, err)
+	assert.Equal(t, sourceStr, "oci://ghcr.io/kcl-lang/helloworld@"+testDigest+"?tag=0.1.0")
+	parsed, err := NewSourceFromStr(sourceStr)
+	assert.NilError(t, err)
+	assert.Equal(t, parsed.Oci.Digest, testDigest)
+	assert.Equal(t, parsed.Oci.Tag, "0.1.0")
+}
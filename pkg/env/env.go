@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"kcl-lang.io/kpm/pkg/reporter"
@@ -15,6 +16,10 @@ const PKG_PATH = "KCL_PKG_PATH"
 const MODULES_SUB_DIR = "modules"
 const KCL_DATA_DIR = "kcl"
 const KPM_NO_SUM = "KPM_NO_SUM"
+const KCL_WORK = "KCL_WORK"
+const KPM_OFFLINE = "KPM_OFFLINE"
+const KPM_SUMDB = "KPM_SUMDB"
+const KPM_NO_SUMDB = "KPM_NO_SUMDB"
 
 // GetEnvPkgPath will return the env $KCL_PKG_PATH.
 func GetEnvPkgPath() string {
@@ -76,3 +81,53 @@ func SkipChecksumCheck(depName string) bool {
 	}
 	return false
 }
+
+// WorkspaceDisabled returns true if the workspace is disabled by '$KCL_WORK=off',
+// then the modules are resolved alone even if they are the members of a workspace.
+func WorkspaceDisabled() bool {
+	return os.Getenv(KCL_WORK) == "off"
+}
+
+// OfflineEnabled returns true if the offline mode is enabled by '$KPM_OFFLINE', e.g. 'KPM_OFFLINE=1' or 'KPM_OFFLINE=true',
+// then the modules are only resolved from the cache.
+func OfflineEnabled() bool {
+	offline, err := strconv.ParseBool(os.Getenv(KPM_OFFLINE))
+	return err == nil && offline
+}
+
+// GetSumDB returns the checksum database by '$KPM_SUMDB', i.e. 'off' to disable the checksum database,
+// the url of a remote checksum database, or empty for the local checksum database under the kpm home path.
+func GetSumDB() string {
+	return os.Getenv(KPM_SUMDB)
+}
+
+// SkipSumDB returns true if the module path matches the comma-separated glob patterns in '$KPM_NO_SUMDB',
+// e.g. 'KPM_NO_SUMDB=github.com/my-org,*.corp.example.com', then the module is private and it is not recorded
+// in or verified against the checksum database. The patterns match the module path or its path prefixes,
+// and the name of the module in the repository after '#' is ignored.
+func SkipSumDB(module string) bool {
+	noSumDBEnv := os.Getenv(KPM_NO_SUMDB)
+	if noSumDBEnv == "" {
+		return false
+	}
+
+	modulePath, _, _ := strings.Cut(module, "#")
+	for _, pattern := range strings.Split(noSumDBEnv, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		// Match the prefix of the module path with the same number of path elements as the pattern.
+		n := strings.Count(pattern, "/") + 1
+		elems := strings.Split(modulePath, "/")
+		if len(elems) < n {
+			continue
+		}
+		matched, err := path.Match(pattern, strings.Join(elems[:n], "/"))
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
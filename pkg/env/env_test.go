@@ -46,3 +46,35 @@ func TestSkipChecksumCheck(t *testing.T) {
 	os.Setenv(KPM_NO_SUM, "")
 	assert.Equal(t, SkipChecksumCheck("crossplane"), false)
 }
+
+func TestOfflineEnabled(t *testing.T) {
+	defer os.Unsetenv(KPM_OFFLINE)
+
+	os.Setenv(KPM_OFFLINE, "true")
+	assert.Equal(t, OfflineEnabled(), true)
+	os.Setenv(KPM_OFFLINE, "1")
+	assert.Equal(t, OfflineEnabled(), true)
+	os.Setenv(KPM_OFFLINE, "false")
+	assert.Equal(t, OfflineEnabled(), false)
+	os.Setenv(KPM_OFFLINE, "invalid")
+	assert.Equal(t, OfflineEnabled(), false)
+	os.Unsetenv(KPM_OFFLINE)
+	assert.Equal(t, OfflineEnabled(), false)
+}
+
+func TestSkipSumDB(t *testing.T) {
+	defer os.Unsetenv(KPM_NO_SUMDB)
+
+	os.Setenv(KPM_NO_SUMDB, "github.com/my-org, *.corp.example.com")
+	assert.Equal(t, SkipSumDB("github.com/my-org"), true)
+	assert.Equal(t, SkipSumDB("github.com/my-org/konfig"), true)
+	assert.Equal(t, SkipSumDB("github.com/my-org/modules#helloworld"), true)
+	assert.Equal(t, SkipSumDB("harbor.corp.example.com/kcl/k8s"), true)
+	// The patterns match on the path boundary.
+	assert.Equal(t, SkipSumDB("github.com/my-org-extra/konfig"), false)
+	assert.Equal(t, SkipSumDB("github.com"), false)
+	assert.Equal(t, SkipSumDB("ghcr.io/kcl-lang/k8s"), false)
+
+	os.Setenv(KPM_NO_SUMDB, "")
+	assert.Equal(t, SkipSumDB("github.com/my-org/konfig"), false)
+}
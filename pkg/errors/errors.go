@@ -6,6 +6,12 @@ import (
 
 var FailedDownloadError = errors.New("failed to download dependency")
 var CheckSumMismatchError = errors.New("checksum mismatch")
+var DigestMismatchError = errors.New("digest mismatch")
+var SignatureVerificationError = errors.New("signature verification failed")
+var RepoNotFound = errors.New("repository not found")
+var AuthFailed = errors.New("authentication failed")
+var InvalidArguments = errors.New("invalid arguments")
+var NotFoundOffline = errors.New("not found in the cache in offline mode")
 var FailedToVendorDependency = errors.New("failed to vendor dependency")
 var FailedToPackage = errors.New("failed to package.")
 var InvalidDependency = errors.New("invalid dependency.")
@@ -50,6 +56,8 @@ var IsOciRef = errors.New("oci ref is not an url.")
 
 // Invalid Version
 var InvalidVersionFormat = errors.New("failed to parse version.")
+var NoSatisfiedVersion = errors.New("no version satisfies the constraints.")
+var InvalidVersionConstraint = errors.New("invalid version constraint.")
 var PathNotFound = errors.New("path not found.")
 var PathIsEmpty = errors.New("path is empty.")
 var InvalidPkg = errors.New("invalid kcl package.")
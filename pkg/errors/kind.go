@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error is an error of a kind, e.g. 'CheckSumMismatchError', wrapping the underlying cause.
+// Its message is the message of the cause, and 'errors.Is' matches both the kind and the cause.
+type Error struct {
+	Kind  error
+	Cause error
+}
+
+// Wrap returns the error of the kind wrapping the cause, or nil if the cause is nil.
+func Wrap(kind, cause error) error {
+	if cause == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// ChecksumMismatch is the error returned when the checksum of a package is not the expected one.
+// It is a 'CheckSumMismatchError'.
+type ChecksumMismatch struct {
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatch) Error() string {
+	return fmt.Sprintf("checksum verification failed for '%s': expected '%s', got '%s'", e.Name, e.Expected, e.Actual)
+}
+
+func (e *ChecksumMismatch) Is(target error) bool {
+	return target == CheckSumMismatchError
+}
+
+// DigestMismatch is the error returned when the tag of an OCI package resolves to another manifest digest than the expected one.
+// It is a 'DigestMismatchError'.
+type DigestMismatch struct {
+	Ref      string
+	Expected string
+	Actual   string
+}
+
+func (e *DigestMismatch) Error() string {
+	return fmt.Sprintf("digest verification failed for '%s': expected '%s', got '%s'", e.Ref, e.Expected, e.Actual)
+}
+
+func (e *DigestMismatch) Is(target error) bool {
+	return target == DigestMismatchError
+}
+
+// SignatureNotVerified is the error returned when no signature of an OCI package is verified by the trusted keys.
+// It is a 'SignatureVerificationError'.
+type SignatureNotVerified struct {
+	Ref string
+	// Keys are the ids of the trusted keys.
+	Keys []string
+}
+
+func (e *SignatureNotVerified) Error() string {
+	return fmt.Sprintf("signature verification failed for '%s': not signed by any of the trusted keys '%s'", e.Ref, strings.Join(e.Keys, "', '"))
+}
+
+func (e *SignatureNotVerified) Is(target error) bool {
+	return target == SignatureVerificationError
+}
+
+// The exit codes of kpm, they are described by 'ExitCodesUsage' in the help of kpm.
+const (
+	ExitOK               = 0
+	ExitFailure          = 1
+	ExitInvalidArguments = 2
+	ExitChecksumMismatch = 3
+	ExitAuthFailed       = 4
+	ExitNotFound         = 5
+	ExitDownloadFailed   = 6
+	ExitCompileFailed    = 7
+)
+
+// exitCodeDescriptions describes the exit codes of kpm in the order of the codes.
+var exitCodeDescriptions = []struct {
+	code        int
+	description string
+}{
+	{ExitOK, "success"},
+	{ExitFailure, "failure not covered by the codes below"},
+	{ExitInvalidArguments, "invalid command line arguments or flags"},
+	{ExitChecksumMismatch, "checksum, digest or signature verification failed"},
+	{ExitAuthFailed, "authentication failed"},
+	{ExitNotFound, "repository or path not found, or not found in the cache in offline mode"},
+	{ExitDownloadFailed, "failed to download a dependency"},
+	{ExitCompileFailed, "failed to compile kcl"},
+}
+
+// ExitCodesUsage returns the table of the exit codes of kpm, one code per line, e.g.
+//
+//	0  success
+//	1  failure not covered by the codes below
+func ExitCodesUsage() string {
+	lines := make([]string, 0, len(exitCodeDescriptions))
+	for _, c := range exitCodeDescriptions {
+		lines = append(lines, fmt.Sprintf("%d  %s", c.code, c.description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exitCodes maps the kinds of errors to the exit codes,
+// an error is mapped to the code of the first kind it matches.
+var exitCodes = []struct {
+	kind error
+	code int
+}{
+	{InvalidArguments, ExitInvalidArguments},
+	{CheckSumMismatchError, ExitChecksumMismatch},
+	{DigestMismatchError, ExitChecksumMismatch},
+	{SignatureVerificationError, ExitChecksumMismatch},
+	{AuthFailed, ExitAuthFailed},
+	{RepoNotFound, ExitNotFound},
+	{PathNotFound, ExitNotFound},
+	{NotFoundOffline, ExitNotFound},
+	{FailedDownloadError, ExitDownloadFailed},
+	{CompileFailed, ExitCompileFailed},
+}
+
+// ExitCode returns the exit code of kpm for the error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.kind) {
+			return c.code
+		}
+	}
+	return ExitFailure
+}
@@ -0,0 +1,67 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+func TestWrap(t *testing.T) {
+	cause := fmt.Errorf("unexpected status code 401")
+	err := kpmerrors.Wrap(kpmerrors.AuthFailed, cause)
+	assert.Equal(t, err.Error(), "unexpected status code 401")
+	assert.True(t, errors.Is(err, kpmerrors.AuthFailed))
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, kpmerrors.RepoNotFound))
+	assert.Nil(t, kpmerrors.Wrap(kpmerrors.AuthFailed, nil))
+
+	var mismatch error = &kpmerrors.ChecksumMismatch{Name: "k8s", Expected: "a", Actual: "b"}
+	assert.Equal(t, mismatch.Error(), "checksum verification failed for 'k8s': expected 'a', got 'b'")
+	assert.True(t, errors.Is(mismatch, kpmerrors.CheckSumMismatchError))
+
+	// The causes are kept by the events.
+	event := reporter.NewErrorEvent(reporter.FailedVendor, mismatch, "failed to vendor dependencies")
+	var target *kpmerrors.ChecksumMismatch
+	assert.True(t, errors.As(event, &target))
+	assert.Equal(t, target.Name, "k8s")
+	assert.True(t, errors.Is(event, kpmerrors.FailedToVendorDependency))
+}
+
+func TestExitCodesUsage(t *testing.T) {
+	usage := kpmerrors.ExitCodesUsage()
+	lines := strings.Split(usage, "\n")
+	assert.Equal(t, 8, len(lines))
+	assert.Equal(t, "0  success", lines[kpmerrors.ExitOK])
+	assert.Equal(t, "3  checksum, digest or signature verification failed", lines[kpmerrors.ExitChecksumMismatch])
+	assert.Equal(t, "7  failed to compile kcl", lines[kpmerrors.ExitCompileFailed])
+}
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"nil", nil, kpmerrors.ExitOK},
+		{"unknown", fmt.Errorf("unknown"), kpmerrors.ExitFailure},
+		{"invalid cmd", reporter.NewErrorEvent(reporter.InvalidCmd, fmt.Errorf("'--verify' and '--prune' cannot be used together")), kpmerrors.ExitInvalidArguments},
+		{"checksum mismatch", reporter.NewErrorEvent(reporter.FailedVendor, &kpmerrors.ChecksumMismatch{Name: "k8s"}), kpmerrors.ExitChecksumMismatch},
+		{"digest mismatch", kpmerrors.Wrap(kpmerrors.FailedDownloadError, reporter.NewErrorEvent(reporter.DigestMismatch, &kpmerrors.DigestMismatch{Ref: "k8s:1.28"})), kpmerrors.ExitChecksumMismatch},
+		{"signature not verified", reporter.NewErrorEvent(reporter.SignatureNotVerified, &kpmerrors.SignatureNotVerified{Ref: "k8s:1.28"}), kpmerrors.ExitChecksumMismatch},
+		{"auth failed", reporter.NewErrorEvent(reporter.FailedGetPkg, kpmerrors.Wrap(kpmerrors.AuthFailed, fmt.Errorf("401"))), kpmerrors.ExitAuthFailed},
+		{"repo not found", kpmerrors.Wrap(kpmerrors.FailedDownloadError, kpmerrors.Wrap(kpmerrors.RepoNotFound, fmt.Errorf("404"))), kpmerrors.ExitNotFound},
+		{"not found offline", kpmerrors.Wrap(kpmerrors.FailedDownloadError, reporter.NewErrorEvent(reporter.NotFoundOffline, kpmerrors.NotFoundOffline)), kpmerrors.ExitNotFound},
+		{"download failed", kpmerrors.Wrap(kpmerrors.FailedDownloadError, fmt.Errorf("connection refused")), kpmerrors.ExitDownloadFailed},
+		{"compile failed", reporter.NewErrorEvent(reporter.CompileFailed, fmt.Errorf("syntax error")), kpmerrors.ExitCompileFailed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, kpmerrors.ExitCode(tc.err), tc.code)
+		})
+	}
+}
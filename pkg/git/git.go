@@ -14,8 +14,11 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/hashicorp/go-getter"
 	giturl "github.com/kubescape/go-git-url"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 )
 
 // CloneOptions is a struct for specifying options for cloning a git repository
@@ -157,7 +160,7 @@ func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone repository: %s, error: %w", string(output), err)
+			return nil, wrapGitError(fmt.Errorf("failed to clone repository: %s, error: %w", string(output), err))
 		}
 
 		repo, err := git.PlainOpen(cloneOpts.LocalPath)
@@ -184,7 +187,7 @@ func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 	}
 
 	if err := client.Get(); err != nil {
-		return nil, err
+		return nil, wrapGitError(err)
 	}
 
 	repo, err := git.PlainOpen(cloneOpts.LocalPath)
@@ -218,7 +221,7 @@ func Clone(repoURL string, tagName string, localPath string, writer io.Writer) (
 		Progress:      writer,
 		ReferenceName: plumbing.ReferenceName(plumbing.NewTagReferenceName(tagName)),
 	})
-	return repo, err
+	return repo, wrapGitError(err)
 }
 
 type GitHubRelease struct {
@@ -323,7 +326,78 @@ func Fetch(dir string, args ...string) error {
 	cmd.Stderr = &out
 	err := cmd.Run()
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest changes: %v, output: %s", err, out.String())
+		return wrapGitError(fmt.Errorf("failed to fetch latest changes: %w, output: %s", err, out.String()))
 	}
 	return nil
 }
+
+// ListRemoteTags lists all the tags of a remote repository
+func ListRemoteTags(repoURL string) ([]string, error) {
+	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", repoURL)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, wrapGitError(fmt.Errorf("failed to list the tags of '%s': %w, output: %s", repoURL, err, stderr.String()))
+	}
+
+	var tags []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if tag, ok := strings.CutPrefix(fields[1], "refs/tags/"); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// authFailedOutputs and repoNotFoundOutputs are the messages of git and the git servers, in lower case,
+// on the refused credentials and the repositories not found.
+var (
+	authFailedOutputs = []string{
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"permission denied (publickey",
+		"http basic: access denied",
+		"the requested url returned error: 401",
+		"the requested url returned error: 403",
+	}
+	repoNotFoundOutputs = []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"the requested url returned error: 404",
+	}
+	repoNotExistOutput = regexp.MustCompile(`repository '[^']*' (not found|does not exist)`)
+)
+
+// wrapGitError wraps the error of cloning or fetching a repository into the kinds of errors in 'kcl-lang.io/kpm/pkg/errors',
+// 'AuthFailed' if the credentials are refused and 'RepoNotFound' if the repository is not found.
+// The errors of the git command are recognized by the output of git in the error message.
+func wrapGitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	containsAny := func(outputs []string) bool {
+		for _, output := range outputs {
+			if strings.Contains(msg, output) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) ||
+		containsAny(authFailedOutputs):
+		return kpmerrors.Wrap(kpmerrors.AuthFailed, err)
+	case errors.Is(err, transport.ErrRepositoryNotFound) || containsAny(repoNotFoundOutputs) || repoNotExistOutput.MatchString(msg):
+		return kpmerrors.Wrap(kpmerrors.RepoNotFound, err)
+	}
+	return err
+}
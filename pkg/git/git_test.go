@@ -2,13 +2,18 @@ package git
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"gotest.tools/v3/assert"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 )
 
 func TestWithGitOptions(t *testing.T) {
@@ -231,3 +236,59 @@ func TestCheckoutFromBare(t *testing.T) {
 	_, err = repo.CommitObject(plumbing.NewHash(commitSHA))
 	assert.NilError(t, err, "Expected commit to exist in the repository")
 }
+
+func TestListRemoteTags(t *testing.T) {
+	repoDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+		{"tag", "v0.1.0"},
+		{"tag", "-a", "v0.2.0", "-m", "v0.2.0"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=kpm", "-c", "user.email=kpm@kcl-lang.io"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NilError(t, err, string(out))
+	}
+
+	tags, err := ListRemoteTags(repoDir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tags, []string{"v0.1.0", "v0.2.0"})
+
+	_, err = ListRemoteTags(filepath.Join(repoDir, "not_exist"))
+	assert.ErrorContains(t, err, "failed to list the tags of")
+	assert.Assert(t, errors.Is(err, kpmerrors.RepoNotFound))
+
+	// The repository not found is not cloned.
+	_, err = CloneWithOpts(
+		WithRepoURL(filepath.Join(repoDir, "not_exist")),
+		WithLocalPath(filepath.Join(t.TempDir(), "bare")),
+		WithBare(true),
+	)
+	assert.Assert(t, errors.Is(err, kpmerrors.RepoNotFound))
+}
+
+func TestWrapGitError(t *testing.T) {
+	testCases := []struct {
+		err  error
+		kind error
+	}{
+		{fmt.Errorf("output: fatal: Authentication failed for 'https://github.com/kcl-lang/private.git/'"), kpmerrors.AuthFailed},
+		{fmt.Errorf("output: fatal: could not read Username for 'https://github.com': terminal prompts disabled"), kpmerrors.AuthFailed},
+		{fmt.Errorf("output: git@github.com: Permission denied (publickey)."), kpmerrors.AuthFailed},
+		{fmt.Errorf("clone: %w", transport.ErrAuthenticationRequired), kpmerrors.AuthFailed},
+		{fmt.Errorf("output: remote: Repository not found."), kpmerrors.RepoNotFound},
+		{fmt.Errorf("output: fatal: repository 'https://github.com/kcl-lang/not_exist.git/' not found"), kpmerrors.RepoNotFound},
+		{fmt.Errorf("output: fatal: '/tmp/not_exist' does not appear to be a git repository"), kpmerrors.RepoNotFound},
+		{fmt.Errorf("clone: %w", transport.ErrRepositoryNotFound), kpmerrors.RepoNotFound},
+	}
+	for _, tc := range testCases {
+		err := wrapGitError(tc.err)
+		assert.Assert(t, errors.Is(err, tc.kind), tc.err.Error())
+		assert.Equal(t, err.Error(), tc.err.Error())
+	}
+
+	// The other errors are not wrapped.
+	err := fmt.Errorf("output: fatal: Remote branch not_exist not found in upstream origin")
+	assert.Equal(t, wrapGitError(err), err)
+	assert.NilError(t, wrapGitError(nil))
+}
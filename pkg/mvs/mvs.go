@@ -57,7 +57,33 @@ func getRepoNameFromURL(gitURL string) string {
 	return parts[len(parts)-1]
 }
 
+// Upgrade upgrades the module to the latest compatible version, which has the same major version.
 func (r ReqsGraph) Upgrade(m module.Version) (module.Version, error) {
+	return r.upgradeWith(m, semver.LatestCompatibleVersion)
+}
+
+// LatestReqsGraph is the ReqsGraph which upgrades the modules to the latest version
+// regardless of the major version.
+type LatestReqsGraph struct {
+	ReqsGraph
+}
+
+// Upgrade upgrades the module to the latest version.
+func (r LatestReqsGraph) Upgrade(m module.Version) (module.Version, error) {
+	return r.upgradeWith(m, func(releases []string, _ string) (string, error) {
+		// Skip the releases that fail to parse and the pre-releases.
+		var validReleases []string
+		for _, release := range releases {
+			if v, err := version.NewVersion(release); err == nil && v.Prerelease() == "" {
+				validReleases = append(validReleases, release)
+			}
+		}
+		return semver.LatestVersion(validReleases)
+	})
+}
+
+// upgradeWith upgrades the module to the version selected from the releases by 'selectVersion'.
+func (r ReqsGraph) upgradeWith(m module.Version, selectVersion func(releases []string, current string) (string, error)) (module.Version, error) {
 	_, properties, err := r.VertexWithProperties(m)
 	if err != nil {
 		return module.Version{}, err
@@ -80,7 +106,7 @@ func (r ReqsGraph) Upgrade(m module.Version) (module.Version, error) {
 		return m, nil
 	}
 
-	m.Version, err = semver.LatestCompatibleVersion(releases, m.Version)
+	m.Version, err = selectVersion(releases, m.Version)
 	if err != nil {
 		return module.Version{}, err
 	}
@@ -202,12 +228,19 @@ func (r ReqsGraph) Required(m module.Version) ([]module.Version, error) {
 	return reqs, nil
 }
 
+// UpdateReqs is the module requirement graph which can be upgraded and downgraded,
+// e.g. ReqsGraph and LatestReqsGraph.
+type UpdateReqs interface {
+	mvs.UpgradeReqs
+	mvs.DowngradeReqs
+}
+
 // UpdateBuildList decides whether to upgrade or downgrade based on modulesToUpgrade and modulesToDowngrade.
 // if modulesToUpgrade is empty, upgrade all dependencies. if modulesToUpgrade is not empty, upgrade the dependencies.
 // if modulesToDowngrade is not empty, downgrade the dependencies.
 // if modulesToUpgrade and modulesToDowngrade are both empty, first apply upgrade operation and
 // then downgrade the build list returned from previous operation.
-func UpdateBuildList(target module.Version, modulesToUpgrade []module.Version, modulesToDowngrade []module.Version, reqs *ReqsGraph) ([]module.Version, error) {
+func UpdateBuildList(target module.Version, modulesToUpgrade []module.Version, modulesToDowngrade []module.Version, reqs UpdateReqs) ([]module.Version, error) {
 	var (
 		UpdBuildLists []module.Version
 		err           error
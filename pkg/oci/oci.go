@@ -3,8 +3,10 @@ package oci
 import (
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -23,10 +26,12 @@ import (
 	"github.com/thoas/go-funk"
 	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
 
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/opt"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/semver"
 	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/signature"
 	"kcl-lang.io/kpm/pkg/utils"
 
 	"oras.land/oras-go/v2"
@@ -213,24 +218,64 @@ const DEFAULT_LIMIT_STORE_SIZE = 64 * 1024 * 1024
 
 // Pull will pull the oci artifacts from oci registry to local path.
 func (ociClient *OciClient) Pull(localPath, tag string) error {
+	_, err := ociClient.PullWithDigest(localPath, tag)
+	return err
+}
+
+// PullWithDigest will pull the oci artifacts with the reference, a tag or a digest, from oci registry to local path,
+// and return the digest of the manifest pulled.
+func (ociClient *OciClient) PullWithDigest(localPath, ref string) (string, error) {
 	// Create a file store
 	fs, err := file.NewWithFallbackLimit(localPath, DEFAULT_LIMIT_STORE_SIZE)
 	if err != nil {
-		return reporter.NewErrorEvent(reporter.FailedCreateStorePath, err, "Failed to create store path ", localPath)
+		return "", reporter.NewErrorEvent(reporter.FailedCreateStorePath, err, "Failed to create store path ", localPath)
 	}
 	defer fs.Close()
 	copyOpts := ociClient.PullOciOptions.CopyOpts
 	copyOpts.FindSuccessors = ociClient.PullOciOptions.Successors
-	_, err = oras.Copy(*ociClient.ctx, ociClient.repo, tag, fs, tag, *copyOpts)
+	desc, err := oras.Copy(*ociClient.ctx, ociClient.repo, ref, fs, ref, *copyOpts)
 	if err != nil {
-		return reporter.NewErrorEvent(
+		return "", reporter.NewErrorEvent(
 			reporter.FailedGetPkg,
-			err,
-			fmt.Sprintf("failed to get package with '%s' from '%s'", tag, ociClient.repo.Reference.String()),
+			wrapRegistryError(err),
+			fmt.Sprintf("failed to get package with '%s' from '%s'", ref, ociClient.repo.Reference.String()),
 		)
 	}
 
-	return nil
+	return desc.Digest.String(), nil
+}
+
+// ResolveDigest returns the digest of the manifest of the ref in the repo without pulling it.
+func (ociClient *OciClient) ResolveDigest(ref string) (string, error) {
+	desc, err := ociClient.repo.Resolve(*ociClient.ctx, ref)
+	if err != nil {
+		return "", reporter.NewErrorEvent(
+			reporter.FailedFetchOciManifest,
+			wrapRegistryError(err),
+			fmt.Sprintf("failed to resolve '%s' in '%s'", ref, ociClient.repo.Reference.String()),
+		)
+	}
+	return desc.Digest.String(), nil
+}
+
+// AllTags will return all the tags of the kcl packages.
+func (ociClient *OciClient) AllTags() ([]string, error) {
+	var allTags []string
+
+	err := ociClient.repo.Tags(*ociClient.ctx, "", func(tags []string) error {
+		allTags = append(allTags, versionTags(tags)...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, reporter.NewErrorEvent(
+			reporter.FailedGetPackageVersions,
+			wrapRegistryError(err),
+			fmt.Sprintf("failed to get the tags of '%s'", ociClient.repo.Reference.String()),
+		)
+	}
+
+	return allTags, nil
 }
 
 // TheLatestTag will return the latest tag of the kcl packages.
@@ -239,7 +284,7 @@ func (ociClient *OciClient) TheLatestTag() (string, error) {
 
 	err := ociClient.repo.Tags(*ociClient.ctx, "", func(tags []string) error {
 		var err error
-		tagSelected, err = semver.LatestVersion(tags)
+		tagSelected, err = semver.LatestVersion(versionTags(tags))
 		if err != nil {
 			return err
 		}
@@ -250,7 +295,7 @@ func (ociClient *OciClient) TheLatestTag() (string, error) {
 	if err != nil {
 		return "", reporter.NewErrorEvent(
 			reporter.FailedSelectLatestVersion,
-			err,
+			wrapRegistryError(err),
 			fmt.Sprintf("failed to select latest version from '%s'", ociClient.repo.Reference.String()),
 		)
 	}
@@ -258,6 +303,21 @@ func (ociClient *OciClient) TheLatestTag() (string, error) {
 	return tagSelected, nil
 }
 
+// referrersTagPattern matches the tags of the referrers tag schema, e.g. 'sha256-<hex>',
+// which the registries without the referrers API use to list the signatures of the packages.
+var referrersTagPattern = regexp.MustCompile(`^(sha256-[a-f0-9]{64}|sha512-[a-f0-9]{128})$`)
+
+// versionTags returns the tags without the tags of the referrers tag schema, which are not the versions of the packages.
+func versionTags(tags []string) []string {
+	var versions []string
+	for _, tag := range tags {
+		if !referrersTagPattern.MatchString(tag) {
+			versions = append(versions, tag)
+		}
+	}
+	return versions
+}
+
 // RepoIsNotExist will check if the error is caused by the repo not found.
 func RepoIsNotExist(err error) bool {
 	errRes, ok := err.(*errcode.ErrorResponse)
@@ -273,6 +333,22 @@ func RepoIsNotExist(err error) bool {
 	return false
 }
 
+// wrapRegistryError wraps the error returned by the registry with the kind of the error,
+// 'AuthFailed' if the request is unauthorized and 'RepoNotFound' if the repo is not found.
+func wrapRegistryError(err error) error {
+	var errRes *errcode.ErrorResponse
+	if !errors.As(err, &errRes) {
+		return err
+	}
+	switch {
+	case errRes.StatusCode == http.StatusUnauthorized || errRes.StatusCode == http.StatusForbidden:
+		return kpmerrors.Wrap(kpmerrors.AuthFailed, err)
+	case RepoIsNotExist(errRes):
+		return kpmerrors.Wrap(kpmerrors.RepoNotFound, err)
+	}
+	return err
+}
+
 // ContainsTag will check if the tag exists in the repo.
 func (ociClient *OciClient) ContainsTag(tag string) (bool, *reporter.KpmEvent) {
 	var exists bool
@@ -290,7 +366,7 @@ func (ociClient *OciClient) ContainsTag(tag string) (bool, *reporter.KpmEvent) {
 		// If the user not login, return error.
 		return false, reporter.NewErrorEvent(
 			reporter.FailedGetPackageVersions,
-			err,
+			wrapRegistryError(err),
 			fmt.Sprintf("failed to access '%s'", ociClient.repo.Reference.String()),
 		)
 	}
@@ -329,8 +405,13 @@ func prepareArtifactLayer(localPath string) (string, func(), error) {
 		}
 	}()
 
+	// The gzip header is stable, the mtime is $SOURCE_DATE_EPOCH or the unix epoch.
+	modTime, err := utils.SourceDateEpoch()
+	if err != nil {
+		return "", func() {}, err
+	}
 	gzipWriter := gzip.NewWriter(dst)
-	gzipWriter.ModTime = time.Unix(0, 0)
+	gzipWriter.ModTime = modTime
 
 	if _, err := io.Copy(gzipWriter, src); err != nil {
 		_ = gzipWriter.Close()
@@ -384,8 +465,21 @@ func (ociClient *OciClient) PushWithOciManifest(localPath, tag string, opts *opt
 	}
 
 	// 2. Pack the files, tag the packed manifest and add metadata as annotations
+	// The creation time of the manifest is $SOURCE_DATE_EPOCH or the unix epoch by default,
+	// so that the digest of the manifest is reproducible.
+	annotations := make(map[string]string, len(opts.Annotations)+1)
+	for k, v := range opts.Annotations {
+		annotations[k] = v
+	}
+	if _, ok := annotations[v1.AnnotationCreated]; !ok {
+		created, err := utils.SourceDateEpoch()
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("failed to pack package in '%s'", localPath))
+		}
+		annotations[v1.AnnotationCreated] = created.Format(time.RFC3339)
+	}
 	packOpts := oras.PackManifestOptions{
-		ManifestAnnotations: opts.Annotations,
+		ManifestAnnotations: annotations,
 		Layers:              fileDescriptors,
 	}
 	manifestDescriptor, err := oras.PackManifest(*ociClient.ctx, fs, oras.PackManifestVersion1_1_RC4, DEFAULT_OCI_ARTIFACT_TYPE, packOpts)
@@ -402,20 +496,49 @@ func (ociClient *OciClient) PushWithOciManifest(localPath, tag string, opts *opt
 	desc, err := oras.Copy(*ociClient.ctx, fs, tag, ociClient.repo, tag, oras.DefaultCopyOptions)
 
 	if err != nil {
-		return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("failed to push '%s'", ociClient.repo.Reference))
+		return reporter.NewErrorEvent(reporter.FailedPush, wrapRegistryError(err), fmt.Sprintf("failed to push '%s'", ociClient.repo.Reference))
 	}
 
 	reporter.ReportMsgTo(fmt.Sprintf("pushed [registry] %s", ociClient.repo.Reference), ociClient.logWriter)
 	reporter.ReportMsgTo(fmt.Sprintf("digest: %s", desc.Digest), ociClient.logWriter)
+
+	// 4. Sign the pushed manifest, the signature is pushed as a referrer of the manifest.
+	if opts.SigningKey != nil {
+		_, err = signature.Sign(*ociClient.ctx, ociClient.repo, desc, opts.SigningKey)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedSign, wrapRegistryError(err), fmt.Sprintf("failed to sign '%s'", ociClient.repo.Reference))
+		}
+		reporter.ReportMsgTo(
+			fmt.Sprintf("signed [registry] %s with key %s", ociClient.repo.Reference, signature.KeyId(opts.SigningKey.Public().(ed25519.PublicKey))),
+			ociClient.logWriter,
+		)
+	}
 	return nil
 }
 
+// VerifySignature verifies the signatures of the manifest of the ref by the keys,
+// and returns the signature verified, whose subject is the digest of the manifest verified.
+// It returns a 'SignatureNotVerified' error if no signature is verified by the keys.
+func (ociClient *OciClient) VerifySignature(ref string, keys []ed25519.PublicKey) (signature.Payload, error) {
+	desc, err := ociClient.repo.Resolve(*ociClient.ctx, ref)
+	if err != nil {
+		return signature.Payload{}, wrapRegistryError(err)
+	}
+	reference := ociClient.repo.Reference
+	reference.Reference = ref
+	payload, err := signature.Verify(*ociClient.ctx, ociClient.repo, reference.String(), desc, keys)
+	if err != nil {
+		return signature.Payload{}, wrapRegistryError(err)
+	}
+	return payload, nil
+}
+
 // FetchManifestIntoJsonStr will fetch the manifest and return it into json string.
 func (ociClient *OciClient) FetchManifestIntoJsonStr(opts opt.OciFetchOptions) (string, error) {
 	fetchOpts := opts.FetchBytesOptions
 	_, manifestContent, err := oras.FetchBytes(*ociClient.ctx, ociClient.repo, opts.Tag, fetchOpts)
 	if err != nil {
-		return "", err
+		return "", wrapRegistryError(err)
 	}
 
 	return string(manifestContent), nil
@@ -505,7 +628,7 @@ func GetAllImageTags(imageName string) ([]string, error) {
 	if err != nil {
 		log.Fatalf("Error getting tags: %v", err)
 	}
-	return tags, nil
+	return versionTags(tags), nil
 }
 
 const (
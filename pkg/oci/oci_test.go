@@ -2,12 +2,17 @@ package oci
 
 import (
 	"archive/tar"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 )
@@ -90,3 +95,34 @@ func TestPrepareArtifactLayer(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, layerContent, string(got))
 }
+
+func TestWrapRegistryError(t *testing.T) {
+	unauthorized := fmt.Errorf("failed to resolve 0.0.1: %w", &errcode.ErrorResponse{
+		Method:     http.MethodGet,
+		StatusCode: http.StatusUnauthorized,
+	})
+	err := wrapRegistryError(unauthorized)
+	assert.True(t, errors.Is(err, kpmerrors.AuthFailed))
+	assert.Equal(t, err.Error(), unauthorized.Error())
+
+	notFound := &errcode.ErrorResponse{
+		Method:     http.MethodGet,
+		StatusCode: http.StatusNotFound,
+		Errors:     errcode.Errors{{Code: OciErrorCodeNameUnknown}},
+	}
+	assert.True(t, errors.Is(wrapRegistryError(notFound), kpmerrors.RepoNotFound))
+
+	other := fmt.Errorf("connection refused")
+	assert.Equal(t, wrapRegistryError(other), other)
+}
+
+func TestVersionTags(t *testing.T) {
+	tags := []string{
+		"0.0.1",
+		"sha256-fe4774c758bc28ba326348ecaf64977f0884cd13d159789a6e80bad4560eee12",
+		"0.0.2",
+		"sha256-not-a-digest",
+	}
+	assert.Equal(t, []string{"0.0.1", "0.0.2", "sha256-not-a-digest"}, versionTags(tags))
+	assert.Empty(t, versionTags(tags[1:2]))
+}
@@ -3,6 +3,7 @@
 package opt
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"io"
 	"net/url"
@@ -28,6 +29,8 @@ type CompileOptions struct {
 	hasSettingsYaml bool
 	entries         []string
 	noSumCheck      bool
+	// The name of the profile in kcl.mod, e.g. 'prod' for '[profile.prod]'.
+	profile string
 	// Add a writer to control the output of the compiler.
 	writer io.Writer
 	*kcl.Option
@@ -63,6 +66,13 @@ func WithNoSumCheck(is bool) Option {
 	}
 }
 
+// WithProfile will select the profile of the name in kcl.mod.
+func WithProfile(profile string) Option {
+	return func(opts *CompileOptions) {
+		opts.profile = profile
+	}
+}
+
 // WithLogWriter will set the log writer of the compiler.
 func WithLogWriter(writer io.Writer) Option {
 	return func(opts *CompileOptions) {
@@ -88,6 +98,16 @@ func (opts *CompileOptions) NoSumCheck() bool {
 	return opts.noSumCheck
 }
 
+// SetProfile will select the profile of the name in kcl.mod.
+func (opts *CompileOptions) SetProfile(profile string) {
+	opts.profile = profile
+}
+
+// Profile will return the name of the profile selected in kcl.mod.
+func (opts *CompileOptions) Profile() string {
+	return opts.profile
+}
+
 // AddEntry will add a compile entry file to the compiler.
 func (opts *CompileOptions) AddEntry(entry string) {
 	opts.entries = append(opts.entries, entry)
@@ -506,6 +526,8 @@ func (oci *OciOptions) SanitizePathWithSuffix(pathPrefix string) string {
 
 type OciManifestOptions struct {
 	Annotations map[string]string
+	// SigningKey is the key to sign the pushed manifest, the manifest is not signed if it is nil.
+	SigningKey ed25519.PrivateKey
 }
 
 // OciFetchOptions is the input options of the api to fetch oci manifest.
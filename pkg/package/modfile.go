@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -39,9 +40,10 @@ type Package struct {
 	Version string `toml:"version,omitempty"`
 	// Description denotes the description of the package.
 	Description string `toml:"description,omitempty"` // kcl package description
-	// Exclude denote the files to include when publishing.
+	// Include denote the files to include when publishing, in the gitignore pattern format.
 	Include []string `toml:"include,omitempty"`
-	// Exclude denote the files to exclude when publishing.
+	// Exclude denote the files to exclude when publishing, in the gitignore pattern format.
+	// The patterns in '.kclignore' in the package root are also excluded.
 	Exclude []string `toml:"exclude,omitempty"`
 }
 
@@ -54,7 +56,12 @@ type ModFile struct {
 	// in the current package directory.
 	VendorMode bool     `toml:"-"`
 	Profiles   *Profile `toml:"profile"`
+	// NamedProfiles are the profiles '[profile.<name>]' of 'kcl.mod', e.g. '[profile.dev]' and '[profile.prod]'.
+	// A named profile inherits the options it does not set from the default profile '[profile]'.
+	NamedProfiles map[string]*Profile `toml:"-"`
 	Dependencies
+	// Replaces overrides the sources of the dependencies for the local development.
+	Replaces Replaces `toml:"-"`
 }
 
 // Profile is the profile section of 'kcl.mod'.
@@ -112,6 +119,37 @@ func (profile *Profile) IntoKclOptions() *kcl.Option {
 	return opts
 }
 
+// Inherit returns the profile with the options not set in it taken from the base profile.
+// The options set in the profile replace the ones of the base profile, the lists are not concatenated.
+func (profile *Profile) Inherit(base *Profile) *Profile {
+	res := NewProfile()
+	if base != nil {
+		res = *base
+	}
+	if profile == nil {
+		return &res
+	}
+	if profile.Entries != nil {
+		res.Entries = profile.Entries
+	}
+	if profile.DisableNone != nil {
+		res.DisableNone = profile.DisableNone
+	}
+	if profile.SortKeys != nil {
+		res.SortKeys = profile.SortKeys
+	}
+	if profile.Selectors != nil {
+		res.Selectors = profile.Selectors
+	}
+	if profile.Overrides != nil {
+		res.Overrides = profile.Overrides
+	}
+	if profile.Options != nil {
+		res.Options = profile.Options
+	}
+	return &res
+}
+
 // GetEntries will get the entry kcl files from profile.
 func (profile *Profile) GetEntries() []string {
 	if profile == nil || profile.Entries == nil {
@@ -128,6 +166,37 @@ func (modFile *ModFile) GetEntries() []string {
 	return modFile.Profiles.GetEntries()
 }
 
+// GetProfile returns the profile of the name inheriting the default profile,
+// or the default profile if the name is empty. The default profile is nil if it is not set in kcl.mod.
+func (modFile *ModFile) GetProfile(name string) (*Profile, error) {
+	if name == "" {
+		return modFile.Profiles, nil
+	}
+	profile, ok := modFile.NamedProfiles[name]
+	if !ok {
+		available := "no profile is defined"
+		if names := modFile.profileNames(); len(names) != 0 {
+			available = fmt.Sprintf("the profiles are '%s'", strings.Join(names, "', '"))
+		}
+		return nil, reporter.NewErrorEvent(
+			reporter.ProfileNotFound,
+			fmt.Errorf("profile '%s' not found in '%s', %s", name, filepath.Join(modFile.HomePath, MOD_FILE), available),
+			fmt.Sprintf("failed to select the profile '%s'", name),
+		)
+	}
+	return profile.Inherit(modFile.Profiles), nil
+}
+
+// profileNames returns the names of the named profiles in the alphabetical order.
+func (modFile *ModFile) profileNames() []string {
+	names := make([]string, 0, len(modFile.NamedProfiles))
+	for name := range modFile.NamedProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // 'Dependencies' is dependencies section of 'kcl.mod'.
 type Dependencies struct {
 	Deps *orderedmap.OrderedMap[string, Dependency] `json:"packages" toml:"dependencies,omitempty"`
@@ -178,6 +247,8 @@ type Dependency struct {
 	FullName string `json:"-" toml:"full_name,omitempty"`
 	Version  string `json:"-" toml:"version,omitempty"`
 	Sum      string `json:"-" toml:"sum,omitempty"`
+	// Replace is the source replacing the dependency by the '[replace]' section in kcl.mod.
+	Replace string `json:"-" toml:"replace,omitempty"`
 	// The actual local path of the package.
 	// In vendor mode is "current_kcl_package/vendor"
 	// In non-vendor mode is "$KCL_PKG_PATH"
@@ -392,20 +463,28 @@ func LoadLockDeps(homePath string) (*Dependencies, error) {
 
 // Write the contents of 'ModFile' to 'kcl.mod' file
 func (mfile *ModFile) StoreModFile() error {
+	modFile, changed, err := mfile.modFileData()
+	if err != nil || !changed {
+		return err
+	}
+	return utils.StoreToFilesAtomically(modFile)
+}
+
+// modFileData returns the contents of 'ModFile' to store into 'kcl.mod' file,
+// and whether they are different from the existing content of the file.
+func (mfile *ModFile) modFileData() (utils.FileData, bool, error) {
 	fullPath := filepath.Join(mfile.HomePath, MOD_FILE)
 
 	//Read the existing content of the file
 	existingModContent, err := os.ReadFile(fullPath)
 	if err != nil && !os.IsNotExist(err) {
-		return err
+		return utils.FileData{}, false, err
 	}
 	newModContent := mfile.MarshalTOML()
 
 	// Compare the existing content with the new content.
-	if string(existingModContent) == string(newModContent) {
-		return nil
-	}
-	return utils.StoreToFile(fullPath, newModContent)
+	modFile := utils.FileData{Path: fullPath, Data: newModContent}
+	return modFile, err != nil || string(existingModContent) != string(newModContent), nil
 }
 
 // Returns the path to the kcl.mod file
@@ -449,11 +528,13 @@ func (mod *ModFile) LoadModFile(name string) error {
 		return err
 	}
 
-	err = toml.Unmarshal(modData, &mod)
+	meta, err := toml.Decode(string(modData), &mod)
 
 	if err != nil {
 		return err
 	}
+	// Keep the replacements in the order of kcl.mod.
+	mod.Replaces.orderByKeys(meta.Keys())
 
 	mod.HomePath = filepath.Dir(name)
 	return nil
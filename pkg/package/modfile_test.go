@@ -201,6 +201,43 @@ func TestLoadModFile(t *testing.T) {
 	assert.Equal(t, err, nil)
 }
 
+func TestGetProfile(t *testing.T) {
+	modFile, err := LoadModFile(getTestDir("test_named_profile"))
+	assert.Equal(t, err, nil)
+
+	profile, err := modFile.GetProfile("")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile, modFile.Profiles)
+
+	// The options set in '[profile.prod]' replace the ones of '[profile]', the others are inherited.
+	profile, err = modFile.GetProfile("prod")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *profile.Entries, []string{"main.k", "prod.yaml"})
+	assert.Equal(t, *profile.Options, []string{"env=prod"})
+	assert.Equal(t, *profile.Overrides, []string{"app.replicas=3"})
+	assert.Equal(t, *profile.SortKeys, true)
+	assert.Nil(t, profile.Selectors)
+	// The profiles in kcl.mod are not changed.
+	assert.Nil(t, modFile.NamedProfiles["prod"].SortKeys)
+	assert.Nil(t, modFile.Profiles.Overrides)
+
+	profile, err = modFile.GetProfile("eu-west.prod")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *profile.Entries, []string{"main.k", "dev.yaml"})
+	assert.Equal(t, *profile.Selectors, []string{"app"})
+
+	_, err = modFile.GetProfile("staging")
+	assert.ErrorContains(t, err, "profile 'staging' not found")
+	assert.ErrorContains(t, err, "the profiles are 'eu-west.prod', 'prod'")
+
+	// The named profile inherits nothing if there is no default profile.
+	modFile.Profiles = nil
+	profile, err = modFile.GetProfile("prod")
+	assert.Equal(t, err, nil)
+	assert.Nil(t, profile.SortKeys)
+	assert.Equal(t, *profile.Entries, []string{"main.k", "prod.yaml"})
+}
+
 func TestLoadLockDeps(t *testing.T) {
 	testPath := getTestDir("load_lock_file")
 	deps, err := LoadLockDeps(testPath)
@@ -128,8 +128,17 @@ func LoadKclPkgWithOpts(options ...LoadOption) (*KclPkg, error) {
 			return nil, fmt.Errorf("could not load 'kcl.mod' in '%s'\n%w", pkgPath, err)
 		}
 		if modDep, ok := modFile.Dependencies.Deps.Get(name); ok {
+			lockedOci := lockDep.Source.Oci
 			lockDep.Source = modDep.Source
 			lockDep.LocalFullPath = modDep.LocalFullPath
+			// The manifest digest resolved for the tag in kcl.mod is kept from kcl.mod.lock.
+			if oci := lockDep.Source.Oci; oci != nil && oci.Digest == "" && lockedOci != nil && lockedOci.Digest != "" &&
+				oci.Reg == lockedOci.Reg && oci.Repo == lockedOci.Repo && oci.Tag == lockedOci.Tag {
+				resolved := *oci
+				resolved.Digest = lockedOci.Digest
+				resolved.DigestResolved = true
+				lockDep.Source.Oci = &resolved
+			}
 		} else {
 			// If there is no source in the lock file, fill the default oci registry.
 			if lockDep.Source.IsNilSource() {
@@ -361,6 +370,34 @@ func (kclPkg *KclPkg) GetKclOpts() *kcl.Option {
 	return kclPkg.ModFile.Profiles.IntoKclOptions()
 }
 
+// GetKclOptsByProfile will return the kcl options from the profile of the name in kcl.mod,
+// the named profile inherits the default profile, and the empty name is the default profile.
+func (kclPkg *KclPkg) GetKclOptsByProfile(name string) (*kcl.Option, error) {
+	profile, err := kclPkg.ModFile.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return kcl.NewOption(), nil
+	}
+	return profile.IntoKclOptions(), nil
+}
+
+// GetKclOptsByProfileWithoutEntries will return the kcl options except the entries from the profile of the name in kcl.mod,
+// e.g. for the entries set by the command line.
+func (kclPkg *KclPkg) GetKclOptsByProfileWithoutEntries(name string) (*kcl.Option, error) {
+	profile, err := kclPkg.ModFile.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return kcl.NewOption(), nil
+	}
+	withoutEntries := *profile
+	withoutEntries.Entries = nil
+	return withoutEntries.IntoKclOptions(), nil
+}
+
 // GetEntryKclFilesFromModFile will return the entry kcl files from kcl.mod.
 func (kclPkg *KclPkg) GetEntryKclFilesFromModFile() []string {
 	return kclPkg.ModFile.GetEntries()
@@ -386,6 +423,22 @@ func (kclPkg *KclPkg) LocalVendorPath() string {
 
 // UpdateModFile will update the kcl.mod file.
 func (kclPkg *KclPkg) UpdateModFile() error {
+	err := kclPkg.syncModDeps()
+	if err != nil {
+		return err
+	}
+
+	// Generate file kcl.mod.
+	err = kclPkg.ModFile.StoreModFile()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// syncModDeps updates the dependencies in kcl.mod by the snapshot of kcl.mod.
+func (kclPkg *KclPkg) syncModDeps() error {
 	// Load kcl.mod SnapShot.
 	depSnapShot := kclPkg.depUI
 
@@ -404,57 +457,75 @@ func (kclPkg *KclPkg) UpdateModFile() error {
 		}
 	}
 
-	// Generate file kcl.mod.
-	err := kclPkg.ModFile.StoreModFile()
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
 // updateModAndLockFile will update kcl.mod and kcl.mod.lock
+//
+// Both files are written before either of them is replaced, and kcl.mod.lock is replaced before kcl.mod,
+// so kcl.mod is never ahead of kcl.mod.lock, see 'utils.StoreToFilesAtomically'.
 func (kclPkg *KclPkg) UpdateModAndLockFile() error {
-	err := kclPkg.UpdateModFile()
+	err := kclPkg.syncModDeps()
 	if err != nil {
 		return err
 	}
 
+	var files []utils.FileData
 	// Generate file kcl.mod.lock.
 	if !kclPkg.NoSumCheck {
-		err := kclPkg.LockDepsVersion()
+		lockFile, changed, err := kclPkg.lockFileData()
 		if err != nil {
 			return err
 		}
+		if changed {
+			files = append(files, lockFile)
+		}
 	}
 
-	return nil
+	// Generate file kcl.mod.
+	modFile, changed, err := kclPkg.ModFile.modFileData()
+	if err != nil {
+		return err
+	}
+	if changed {
+		files = append(files, modFile)
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+	return utils.StoreToFilesAtomically(files...)
 }
 
 // LockDepsVersion locks the dependencies of the current kcl package into kcl.mod.lock.
 func (kclPkg *KclPkg) LockDepsVersion() error {
+	lockFile, changed, err := kclPkg.lockFileData()
+	if err != nil || !changed {
+		return err
+	}
+
+	// Update the kcl.mod.lock file if there are changes
+	return utils.StoreToFilesAtomically(lockFile)
+}
+
+// lockFileData returns the dependencies of the current kcl package to store into kcl.mod.lock,
+// and whether they are different from the existing content of kcl.mod.lock.
+func (kclPkg *KclPkg) lockFileData() (utils.FileData, bool, error) {
 	fullPath := filepath.Join(kclPkg.HomePath, MOD_LOCK_FILE)
 	lockToml, err := kclPkg.Dependencies.MarshalLockTOML()
 	if err != nil {
-		return err
+		return utils.FileData{}, false, err
 	}
 
 	// Read the existing kcl.mod.lock file content
 	existingLockToml, err := os.ReadFile(fullPath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		} else {
-			return utils.StoreToFile(fullPath, lockToml)
-		}
-	}
-	// Compare the existing content with the new content
-	if string(existingLockToml) == string(lockToml) {
-		return nil
+	if err != nil && !os.IsNotExist(err) {
+		return utils.FileData{}, false, err
 	}
 
-	// Update the kcl.mod.lock file if there are changes
-	return utils.StoreToFile(fullPath, lockToml)
+	// Compare the existing content with the new content
+	lockFile := utils.FileData{Path: fullPath, Data: lockToml}
+	return lockFile, err != nil || string(existingLockToml) != string(lockToml), nil
 }
 
 // CreateDefaultMain will create a default main.k file in the current kcl package.
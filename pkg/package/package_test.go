@@ -190,6 +190,32 @@ func TestLoadPkgFromLock(t *testing.T) {
 	assert.Equal(t, kpkg.Dependencies.Deps.GetOrDefault("helloworld", TestPkgDependency).Source.Oci.Tag, "0.1.2")
 }
 
+func TestLoadPkgFromLockWithDigest(t *testing.T) {
+	pkgPath := getTestDir("load_lock_digest")
+	kpkg, err := LoadKclPkgWithOpts(
+		WithPath(pkgPath),
+		WithSettings(settings.GetSettings()),
+	)
+	assert.Equal(t, err, nil)
+
+	// The digest locked for the tag in kcl.mod is kept.
+	helloworld := kpkg.Dependencies.Deps.GetOrDefault("helloworld", TestPkgDependency)
+	assert.Equal(t, helloworld.Source.Oci.Digest, "sha256:2a7e5d5ab8a5e0a8ba5d12bb6f7bd4b9a2b9ecc2fc8a1f8b1c4cfb1e2e5e0d0a")
+	assert.Equal(t, helloworld.Source.Oci.DigestResolved, true)
+	modDep := kpkg.ModFile.Deps.GetOrDefault("helloworld", TestPkgDependency)
+	assert.Equal(t, modDep.Source.Oci.Digest, "")
+
+	// The digest locked for another tag is not.
+	k8s := kpkg.Dependencies.Deps.GetOrDefault("k8s", TestPkgDependency)
+	assert.Equal(t, k8s.Source.Oci.Tag, "1.31")
+	assert.Equal(t, k8s.Source.Oci.Digest, "")
+
+	// The digest locked for another registry is not.
+	konfig := kpkg.Dependencies.Deps.GetOrDefault("konfig", TestPkgDependency)
+	assert.Equal(t, konfig.Source.Oci.Reg, "docker.io")
+	assert.Equal(t, konfig.Source.Oci.Digest, "")
+}
+
 func TestLoadKclPkgWithoutSettings(t *testing.T) {
 	modPath := getTestDir("load_without_settings")
 	kMod, err := LoadKclPkgWithOpts(
@@ -252,3 +278,18 @@ func TestLockDepsVersionNoChange(t *testing.T) {
 	assert.Equal(t, initialModTime, updatedModTime, "kcl.mod.lock should not be modified")
 	assert.Equal(t, string(initialLockContent), string(updatedLockContent), "kcl.mod.lock content should remain the same")
 }
+
+func TestGetKclOptsByProfileWithoutEntries(t *testing.T) {
+	kclPkg, err := LoadKclPkg(getTestDir("test_named_profile"))
+	assert.Nil(t, err)
+
+	// The entries of the profile are not returned, the other options are.
+	opts, err := kclPkg.GetKclOptsByProfileWithoutEntries("prod")
+	assert.Nil(t, err)
+	assert.Empty(t, opts.KFilenameList)
+	assert.Equal(t, kclPkg.ModFile.NamedProfiles["prod"].IntoKclOptions().Overrides, opts.Overrides)
+	assert.Equal(t, []string{"main.k", "prod.yaml"}, *kclPkg.ModFile.NamedProfiles["prod"].Entries)
+
+	_, err = kclPkg.GetKclOptsByProfileWithoutEntries("staging")
+	assert.ErrorContains(t, err, "profile 'staging' not found")
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	orderedmap "github.com/elliotchance/orderedmap/v2"
+	"kcl-lang.io/kpm/pkg/downloader"
+)
+
+const (
+	REPLACE_FLAG    = "replace"
+	REPLACE_PATTERN = "[replace]"
+)
+
+// Replaces is the replace section of 'kcl.mod'.
+// It overrides the source of the dependencies anywhere in the dependency graph, e.g.
+//
+//	[replace]
+//	k8s = { path = "../k8s" }
+//	helloworld = { git = "https://github.com/fork/helloworld", tag = "v0.1.3" }
+//
+// Only the replacements of the module being resolved take effect,
+// the replacements in the kcl.mod of the dependencies are ignored.
+type Replaces struct {
+	Deps *orderedmap.OrderedMap[string, downloader.Source]
+}
+
+// Get returns the replacement of the dependency by its name.
+func (r *Replaces) Get(name string) (*downloader.Source, bool) {
+	if r == nil || r.Deps == nil {
+		return nil, false
+	}
+	source, ok := r.Deps.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return &source, true
+}
+
+// LocalReplaces returns the names of the dependencies replaced by the local paths.
+func (r *Replaces) LocalReplaces() []string {
+	var names []string
+	if r == nil || r.Deps == nil {
+		return names
+	}
+	for _, name := range r.Deps.Keys() {
+		if source, ok := r.Deps.Get(name); ok && source.IsLocalPath() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (r *Replaces) MarshalTOML() string {
+	var sb strings.Builder
+	if r.Deps != nil && r.Deps.Len() != 0 {
+		sb.WriteString(REPLACE_PATTERN)
+		for _, name := range r.Deps.Keys() {
+			source, ok := r.Deps.Get(name)
+			if !ok {
+				break
+			}
+			sb.WriteString(NEWLINE)
+			sb.WriteString(fmt.Sprintf(DEP_PATTERN, name, source.MarshalTOML()))
+		}
+		sb.WriteString(NEWLINE)
+	}
+	return sb.String()
+}
+
+func (r *Replaces) UnmarshalModTOML(data interface{}) error {
+	meta, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}, got %T", data)
+	}
+
+	var keys []string
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		source := downloader.Source{}
+		err := source.UnmarshalModTOML(meta[k])
+		if err != nil {
+			return err
+		}
+		if source.Git == nil && source.Oci == nil && source.Local == nil {
+			return fmt.Errorf("invalid replacement for '%s', expected a local path, git or oci source", k)
+		}
+		r.Deps.Set(k, source)
+	}
+
+	return nil
+}
+
+// orderByKeys orders the replacements as they are in kcl.mod by the keys decoded from it in order,
+// for the keys of the maps decoded by 'UnmarshalModTOML' are not ordered.
+func (r *Replaces) orderByKeys(keys []toml.Key) {
+	if r.Deps == nil || r.Deps.Len() == 0 {
+		return
+	}
+	ordered := orderedmap.NewOrderedMap[string, downloader.Source]()
+	for _, key := range keys {
+		if len(key) != 2 || key[0] != REPLACE_FLAG {
+			continue
+		}
+		if source, ok := r.Deps.Get(key[1]); ok {
+			ordered.Set(key[1], source)
+		}
+	}
+	for _, name := range r.Deps.Keys() {
+		if _, ok := ordered.Get(name); !ok {
+			source, _ := r.Deps.Get(name)
+			ordered.Set(name, source)
+		}
+	}
+	r.Deps = ordered
+}
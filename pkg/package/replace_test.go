@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaces(t *testing.T) {
+	modContent := `[package]
+name = "replace"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+helloworld = "0.1.2"
+k8s = "1.31.2"
+
+[replace]
+k8s = { path = "../k8s" }
+helloworld = { git = "https://github.com/fork/helloworld", tag = "v0.1.3" }
+`
+	modPath := filepath.Join(t.TempDir(), MOD_FILE)
+	assert.NoError(t, os.WriteFile(modPath, []byte(modContent), 0644))
+
+	modFile := ModFile{}
+	assert.NoError(t, modFile.LoadModFile(modPath))
+	// The replacements are in the order of kcl.mod.
+	assert.Equal(t, []string{"k8s", "helloworld"}, modFile.Replaces.Deps.Keys())
+
+	helloworld, ok := modFile.Replaces.Get("helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, "https://github.com/fork/helloworld", helloworld.Git.Url)
+	assert.Equal(t, "v0.1.3", helloworld.Git.Tag)
+	_, ok = modFile.Replaces.Get("not_exist")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"k8s"}, modFile.Replaces.LocalReplaces())
+	assert.Equal(t, modContent, modFile.MarshalTOML())
+
+	err := os.WriteFile(modPath, []byte("[replace]\nk8s = \"1.31.2\"\n"), 0644)
+	assert.NoError(t, err)
+	err = (&ModFile{}).LoadModFile(modPath)
+	assert.ErrorContains(t, err, "invalid replacement for 'k8s'")
+}
@@ -0,0 +1,9 @@
+[package]
+name = "load_lock_digest"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+helloworld = { oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.2" }
+k8s = { oci = "oci://ghcr.io/kcl-lang/k8s", tag = "1.31" }
+konfig = { oci = "oci://docker.io/kcl-lang/konfig", tag = "0.4.0" }
@@ -0,0 +1,17 @@
+[package]
+name = "kpm"
+edition = "0.0.1"
+version = "0.0.1"
+
+[profile]
+entries = ["main.k", "dev.yaml"]
+sort_keys = true
+arguments = ["env=dev"]
+
+[profile.prod]
+entries = ["main.k", "prod.yaml"]
+arguments = ["env=prod"]
+overrides = ["app.replicas=3"]
+
+[profile."eu-west.prod"]
+selectors = ["app"]
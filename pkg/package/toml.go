@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -43,11 +44,20 @@ func (mod *ModFile) MarshalTOML() string {
 		sb.WriteString(NEWLINE)
 		sb.WriteString(dependencies)
 	}
+	replaces := mod.Replaces.MarshalTOML()
+	if replaces != "" {
+		sb.WriteString(NEWLINE)
+		sb.WriteString(replaces)
+	}
 	profiles := mod.Profiles.MarshalTOML()
 	if profiles != "" {
 		sb.WriteString(NEWLINE)
 		sb.WriteString(profiles)
 	}
+	for _, name := range mod.profileNames() {
+		sb.WriteString(NEWLINE)
+		sb.WriteString(mod.NamedProfiles[name].marshalTOML(fmt.Sprintf(NAMED_PROFILE_PATTERN, tomlKey(name))))
+	}
 	return sb.String()
 }
 
@@ -101,11 +111,16 @@ func (dep *Dependency) MarshalTOML() string {
 }
 
 const PROFILE_PATTERN = "[profile]"
+const NAMED_PROFILE_PATTERN = "[profile.%s]"
 
 func (p *Profile) MarshalTOML() string {
+	return p.marshalTOML(PROFILE_PATTERN)
+}
+
+func (p *Profile) marshalTOML(header string) string {
 	var sb strings.Builder
 	if p != nil {
-		sb.WriteString(PROFILE_PATTERN)
+		sb.WriteString(header)
 		sb.WriteString(NEWLINE)
 		var buf bytes.Buffer
 		if err := toml.NewEncoder(&buf).Encode(p); err != nil {
@@ -148,21 +163,77 @@ func (mod *ModFile) UnmarshalTOML(data interface{}) error {
 	}
 	mod.Dependencies = deps
 
-	if v, ok := meta[PROFILES_FLAG]; ok {
-		p := NewProfile()
-		var buf bytes.Buffer
-		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
-			return err
-		}
-		err := toml.Unmarshal(buf.Bytes(), &p)
+	replaces := Replaces{
+		Deps: orderedmap.NewOrderedMap[string, downloader.Source](),
+	}
+	if v, ok := meta[REPLACE_FLAG]; ok {
+		err := replaces.UnmarshalModTOML(v)
 		if err != nil {
 			return err
 		}
-		mod.Profiles = &p
+	}
+	mod.Replaces = replaces
+
+	if v, ok := meta[PROFILES_FLAG]; ok {
+		// The tables in '[profile]' are the named profiles, e.g. '[profile.dev]'.
+		profiles, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected map[string]interface{}, got %T", v)
+		}
+		defaults := map[string]interface{}{}
+		for key, value := range profiles {
+			if _, ok := value.(map[string]interface{}); !ok {
+				defaults[key] = value
+				continue
+			}
+			p, err := unmarshalProfile(value)
+			if err != nil {
+				return err
+			}
+			if mod.NamedProfiles == nil {
+				mod.NamedProfiles = make(map[string]*Profile)
+			}
+			mod.NamedProfiles[key] = p
+		}
+		// The default profile is not set if there are only the named profiles.
+		if len(defaults) != 0 || len(mod.NamedProfiles) == 0 {
+			p, err := unmarshalProfile(defaults)
+			if err != nil {
+				return err
+			}
+			mod.Profiles = p
+		}
 	}
 	return nil
 }
 
+// unmarshalProfile decodes a profile table of 'kcl.mod'.
+func unmarshalProfile(data interface{}) (*Profile, error) {
+	p := NewProfile()
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	err := toml.Unmarshal(buf.Bytes(), &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// tomlKey returns the key quoted if it is not a bare key of toml.
+func tomlKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return strconv.Quote(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
 const (
 	NAME_FLAG        = "name"
 	EDITION_FLAG     = "edition"
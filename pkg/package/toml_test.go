@@ -177,6 +177,36 @@ func TestUnMarshalTOMLWithProfile(t *testing.T) {
 	assert.Equal(t, *modfile.Profiles.Entries, []string{"main.k", "xxx/xxx/dir", "test.yaml"})
 }
 
+func TestUnMarshalTOMLWithNamedProfiles(t *testing.T) {
+	modfile, err := LoadModFile(getTestDir("test_named_profile"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *modfile.Profiles.Entries, []string{"main.k", "dev.yaml"})
+	assert.Equal(t, *modfile.Profiles.SortKeys, true)
+	assert.Equal(t, *modfile.Profiles.Options, []string{"env=dev"})
+	assert.Equal(t, len(modfile.NamedProfiles), 2)
+	assert.Equal(t, *modfile.NamedProfiles["prod"].Entries, []string{"main.k", "prod.yaml"})
+	assert.Equal(t, *modfile.NamedProfiles["prod"].Overrides, []string{"app.replicas=3"})
+	assert.Nil(t, modfile.NamedProfiles["prod"].SortKeys)
+	assert.Equal(t, *modfile.NamedProfiles["eu-west.prod"].Selectors, []string{"app"})
+
+	// The named profiles are kept when kcl.mod is rewritten.
+	content := modfile.MarshalTOML()
+	assert.Contains(t, content, "[profile.prod]")
+	assert.Contains(t, content, `[profile."eu-west.prod"]`)
+	var rewritten ModFile
+	_, err = toml.Decode(content, &rewritten)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, rewritten.Profiles, modfile.Profiles)
+	assert.Equal(t, rewritten.NamedProfiles, modfile.NamedProfiles)
+
+	// The default profile is not set if there are only the named profiles.
+	var onlyNamed ModFile
+	_, err = toml.Decode("[profile.prod]\nentries = [\"main.k\"]\n", &onlyNamed)
+	assert.Equal(t, err, nil)
+	assert.Nil(t, onlyNamed.Profiles)
+	assert.Equal(t, *onlyNamed.NamedProfiles["prod"].Entries, []string{"main.k"})
+}
+
 func TestUnMarshalOciUrl(t *testing.T) {
 	testDataDir := getTestDir("test_oci_url")
 
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	orderedmap "github.com/elliotchance/orderedmap/v2"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+const (
+	WORK_FILE      = "kcl.work"
+	WORK_LOCK_FILE = "kcl.work.lock"
+	// WORK_DIRECTIVE is the directive to add a member module into the workspace, e.g. 'workspace ./pkg'.
+	WORK_DIRECTIVE = "workspace"
+)
+
+// WorkFile is the workspace file 'kcl.work' listing the member modules of the workspace, e.g.
+//
+//	# The member modules of the workspace.
+//	workspace ./base
+//	workspace ./apps/frontend
+//
+// The members are resolved together and share the lock file 'kcl.work.lock' at the workspace root.
+type WorkFile struct {
+	// HomePath is the root path of the workspace, which contains 'kcl.work'.
+	HomePath string
+	// Members is the paths of the member modules relative to the workspace root.
+	Members []string
+}
+
+// LoadWorkFile loads the 'kcl.work' in the workspace root 'homePath'.
+func LoadWorkFile(homePath string) (*WorkFile, error) {
+	workFilePath := filepath.Join(homePath, WORK_FILE)
+	file, err := os.Open(workFilePath)
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedLoadKclWork, err, fmt.Sprintf("failed to load '%s'", workFilePath))
+	}
+	defer file.Close()
+
+	workFile := &WorkFile{HomePath: homePath}
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != WORK_DIRECTIVE {
+			return nil, reporter.NewErrorEvent(
+				reporter.FailedLoadKclWork,
+				fmt.Errorf("%s:%d: invalid directive '%s', expected 'workspace <path>'", workFilePath, lineNo, line),
+			)
+		}
+		workFile.Members = append(workFile.Members, filepath.ToSlash(filepath.Clean(fields[1])))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedLoadKclWork, err, fmt.Sprintf("failed to load '%s'", workFilePath))
+	}
+
+	return workFile, nil
+}
+
+// FindWorkFile finds the 'kcl.work' from the path up to the file system root,
+// and returns the workspace root containing it, or an empty string if not found.
+func FindWorkFile(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if exists, _ := utils.Exists(filepath.Join(absPath, WORK_FILE)); exists {
+			return absPath, nil
+		}
+		parent := filepath.Dir(absPath)
+		if parent == absPath {
+			return "", nil
+		}
+		absPath = parent
+	}
+}
+
+// MemberPaths returns the absolute paths of the member modules.
+func (w *WorkFile) MemberPaths() []string {
+	paths := make([]string, 0, len(w.Members))
+	for _, member := range w.Members {
+		if filepath.IsAbs(member) {
+			paths = append(paths, filepath.Clean(member))
+		} else {
+			paths = append(paths, filepath.Join(w.HomePath, filepath.FromSlash(member)))
+		}
+	}
+	return paths
+}
+
+// GetWorkFilePath returns the path to the kcl.work file.
+func (w *WorkFile) GetWorkFilePath() string {
+	return filepath.Join(w.HomePath, WORK_FILE)
+}
+
+// GetWorkLockFilePath returns the path to the kcl.work.lock file.
+func (w *WorkFile) GetWorkLockFilePath() string {
+	return filepath.Join(w.HomePath, WORK_LOCK_FILE)
+}
+
+// LoadWorkLockDeps loads the dependencies shared by the members from 'kcl.work.lock'.
+func (w *WorkFile) LoadWorkLockDeps() (*Dependencies, error) {
+	deps := new(Dependencies)
+	deps.Deps = orderedmap.NewOrderedMap[string, Dependency]()
+	err := deps.loadLockFile(w.GetWorkLockFilePath())
+
+	if os.IsNotExist(err) {
+		return deps, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// StoreWorkLockDeps writes the dependencies shared by the members into 'kcl.work.lock'.
+func (w *WorkFile) StoreWorkLockDeps(deps *Dependencies) error {
+	lockToml, err := deps.MarshalLockTOML()
+	if err != nil {
+		return err
+	}
+
+	existingLockToml, err := os.ReadFile(w.GetWorkLockFilePath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if string(existingLockToml) == lockToml {
+		return nil
+	}
+
+	return utils.StoreToFile(w.GetWorkLockFilePath(), lockToml)
+}
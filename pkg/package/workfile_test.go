@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadWorkFile(t *testing.T) {
+	wsPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(wsPath, WORK_FILE), []byte("# comment\nworkspace ./base\n\nworkspace apps/frontend/ # frontend\n"), 0644)
+	assert.NoError(t, err)
+
+	workFile, err := LoadWorkFile(wsPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"base", "apps/frontend"}, workFile.Members)
+	assert.Equal(t, []string{
+		filepath.Join(wsPath, "base"),
+		filepath.Join(wsPath, "apps", "frontend"),
+	}, workFile.MemberPaths())
+
+	root, err := FindWorkFile(filepath.Join(wsPath, "apps", "frontend"))
+	assert.NoError(t, err)
+	assert.Equal(t, wsPath, root)
+
+	err = os.WriteFile(filepath.Join(wsPath, WORK_FILE), []byte("members ./base\n"), 0644)
+	assert.NoError(t, err)
+	_, err = LoadWorkFile(wsPath)
+	assert.ErrorContains(t, err, "kcl.work:1: invalid directive 'members ./base', expected 'workspace <path>'")
+}
@@ -3,13 +3,16 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 )
 
 // Init the log.
@@ -42,6 +45,7 @@ type Event interface {
 	Event() string
 }
 
+//go:generate stringer -type=EventType
 type EventType int
 
 const (
@@ -118,13 +122,32 @@ const (
 	CompileFailed
 	FailedParseVersion
 	FailedFetchOciManifest
+	DownloadFinished
+
+	// The event types are appended to keep the values of the existing ones.
+	InvalidVersionConstraint
+	UnsatisfiableVersionConstraints
+	FailedLoadKclWork
+	InvalidWorkspace
+	PackageNotReproducible
+	FailedVerifyVendor
+	FailedAccessCache
+	NotFoundOffline
+	DigestMismatch
+	FailedSign
+	SignatureNotVerified
+	TemplateNotFound
+	ProfileNotFound
 )
 
 // KpmEvent is the event used to show kpm logs to users.
 type KpmEvent struct {
-	errType EventType
-	msg     string
-	err     error
+	errType  EventType
+	msg      string
+	err      error
+	module   string
+	source   string
+	duration time.Duration
 }
 
 // Type returns the event type.
@@ -132,6 +155,67 @@ func (e *KpmEvent) Type() EventType {
 	return e.errType
 }
 
+// Unwrap returns the error wrapped by the event.
+func (e *KpmEvent) Unwrap() error {
+	return e.err
+}
+
+// eventErrors maps the event types to the kinds of errors in 'kcl-lang.io/kpm/pkg/errors'.
+var eventErrors = map[EventType]error{
+	InvalidCmd:               kpmerrors.InvalidArguments,
+	InvalidFlag:              kpmerrors.InvalidArguments,
+	CheckSumMismatch:         kpmerrors.CheckSumMismatchError,
+	RepoNotFound:             kpmerrors.RepoNotFound,
+	FailedLogin:              kpmerrors.AuthFailed,
+	FailedGetPkg:             kpmerrors.FailedDownloadError,
+	FailedVendor:             kpmerrors.FailedToVendorDependency,
+	FailedPackage:            kpmerrors.FailedToPackage,
+	CompileFailed:            kpmerrors.CompileFailed,
+	NotFoundOffline:          kpmerrors.NotFoundOffline,
+	DigestMismatch:           kpmerrors.DigestMismatchError,
+	SignatureNotVerified:     kpmerrors.SignatureVerificationError,
+	TemplateNotFound:         kpmerrors.PathNotFound,
+	LocalPathNotExist:        kpmerrors.PathNotFound,
+	ProfileNotFound:          kpmerrors.InvalidArguments,
+	InvalidVersionConstraint: kpmerrors.InvalidVersionConstraint,
+}
+
+// Is reports whether the event is of the kind of error, e.g. 'errors.Is(err, kpmerrors.CheckSumMismatchError)'.
+func (e *KpmEvent) Is(target error) bool {
+	kind, ok := eventErrors[e.errType]
+	return ok && kind == target
+}
+
+// WithModule sets the name of the module the event is about.
+func (e *KpmEvent) WithModule(module string) *KpmEvent {
+	e.module = module
+	return e
+}
+
+// WithSource sets the source of the module the event is about.
+func (e *KpmEvent) WithSource(source string) *KpmEvent {
+	e.source = source
+	return e
+}
+
+// WithDuration sets the time taken by the operation the event is about.
+func (e *KpmEvent) WithDuration(duration time.Duration) *KpmEvent {
+	e.duration = duration
+	return e
+}
+
+// Record returns the structured record of the event for the sinks.
+func (e *KpmEvent) Record() Record {
+	return Record{
+		Type:     e.errType,
+		Message:  e.msg,
+		Module:   e.module,
+		Source:   e.source,
+		Duration: e.duration,
+		Err:      e.err,
+	}
+}
+
 // Error makes KpmEvent can be used as an error.
 func (e *KpmEvent) Error() string {
 	result := ""
@@ -182,14 +266,48 @@ func ReportEventToStderr(event *KpmEvent) {
 }
 
 // ReportEvent reports the event to users to stdout.
+// If the writer is a Sink, the event is emitted to it as a record.
 func ReportEventTo(event *KpmEvent, w io.Writer) {
-	if w != nil {
+	if sink, ok := w.(Sink); ok {
+		sink.Emit(event.Record())
+	} else if w != nil {
 		fmt.Fprintf(w, "%v", event.Event())
 	}
 }
 
+// ReportMsgTo reports the message to users.
+// If the writer is a Sink, the message is emitted to it as a record of the 'Default' type.
 func ReportMsgTo(msg string, w io.Writer) {
-	if w != nil {
+	if sink, ok := w.(Sink); ok {
+		sink.Emit(Record{Type: Default, Message: msg})
+	} else if w != nil {
 		fmt.Fprintf(w, "%s\n", msg)
 	}
 }
+
+// EmitTo emits the event only if the writer is a Sink,
+// it is used for the events which are only useful for the structured consumers, e.g. the durations of downloads.
+func EmitTo(event *KpmEvent, w io.Writer) {
+	if sink, ok := w.(Sink); ok {
+		sink.Emit(event.Record())
+	}
+}
+
+// ReportErrorTo reports the error to users.
+// If the writer is a Sink, the error is emitted to it as a record with the type of the error event.
+func ReportErrorTo(err error, w io.Writer) {
+	if sink, ok := w.(Sink); ok {
+		var event *KpmEvent
+		if errors.As(err, &event) {
+			sink.Emit(event.Record())
+		} else {
+			sink.Emit(Record{Type: Default, Err: err})
+		}
+	} else if w != nil {
+		msg := err.Error()
+		if !strings.HasSuffix(msg, "\n") {
+			msg += "\n"
+		}
+		fmt.Fprint(w, msg)
+	}
+}
@@ -0,0 +1,127 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is the structured form of an event reported by kpm.
+type Record struct {
+	// Type is the type of the event.
+	Type EventType
+	// Message is the human-readable message of the event.
+	Message string
+	// Module is the name of the module the event is about, e.g. the module being downloaded.
+	Module string
+	// Source is the source of the module the event is about, e.g. 'oci://ghcr.io/kcl-lang/k8s?tag=1.28'.
+	Source string
+	// Duration is the time taken by the operation the event is about.
+	Duration time.Duration
+	// Err is the error of the event, nil if the event is not an error.
+	Err error
+}
+
+// Sink receives the events reported by kpm as typed records instead of the human-readable text.
+// A log writer implementing Sink, e.g. the one set by 'KpmClient.SetLogWriter',
+// receives the events by 'Emit' instead of 'Write'.
+// The sinks must be safe for concurrent use, for the dependencies may be downloaded concurrently.
+type Sink interface {
+	Emit(record Record)
+}
+
+// JSONSink is a Sink writing one JSON object per event into the writer, e.g.
+//
+//	{"time":"2024-01-01T00:00:00Z","level":"info","type":"DownloadingFromOCI","message":"downloading 'kcl-lang/k8s:1.28' from 'ghcr.io/kcl-lang/k8s:1.28'","module":"k8s","source":"oci://ghcr.io/kcl-lang/k8s?tag=1.28"}
+//
+// The text written into it directly by 'Write' is emitted line by line as the records of the 'Default' type.
+type JSONSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewJSONSink returns a JSONSink writing the events into the writer.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+type jsonRecord struct {
+	Time       string   `json:"time"`
+	Level      string   `json:"level"`
+	Type       string   `json:"type"`
+	Message    string   `json:"message,omitempty"`
+	Module     string   `json:"module,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	DurationMs int64    `json:"duration_ms,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// Emit writes the record into the writer as a JSON object in a line.
+func (s *JSONSink) Emit(record Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit(record)
+}
+
+func (s *JSONSink) emit(record Record) {
+	level := "info"
+	if record.Err != nil {
+		level = "error"
+	}
+	data, err := json.Marshal(jsonRecord{
+		Time:       time.Now().UTC().Format(time.RFC3339Nano),
+		Level:      level,
+		Type:       record.Type.String(),
+		Message:    record.Message,
+		Module:     record.Module,
+		Source:     record.Source,
+		DurationMs: record.Duration.Milliseconds(),
+		Errors:     ErrorChain(record.Err),
+	})
+	if err != nil {
+		return
+	}
+	_, _ = s.w.Write(append(data, '\n'))
+}
+
+// Write emits the complete lines written into the sink as the records of the 'Default' type.
+func (s *JSONSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Write(p)
+	for {
+		line, err := s.buf.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write.
+			s.buf.WriteString(line)
+			break
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			s.emit(Record{Type: Default, Message: line})
+		}
+	}
+	return len(p), nil
+}
+
+// ErrorChain returns the messages of the error and the errors wrapped by it, from the outermost to the innermost.
+// The events contribute their messages, and the first error which is not an event contributes its whole message.
+func ErrorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		event, ok := err.(*KpmEvent)
+		if !ok {
+			chain = append(chain, err.Error())
+			break
+		}
+		if event.msg != "" {
+			chain = append(chain, event.msg)
+		}
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
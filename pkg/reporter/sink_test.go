@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf)
+
+	ReportMsgTo("adding dependency 'k8s'", sink)
+	ReportEventTo(
+		NewEvent(DownloadingFromOCI, "downloading 'kcl-lang/k8s:1.28'").
+			WithModule("k8s").
+			WithSource("oci://ghcr.io/kcl-lang/k8s?tag=1.28"),
+		sink,
+	)
+	EmitTo(NewEvent(DownloadFinished).WithModule("k8s").WithDuration(1500*time.Millisecond), sink)
+	ReportErrorTo(NewErrorEvent(FailedVendor, NewErrorEvent(CheckSumMismatch, fmt.Errorf("sum mismatch"), "checksum for 'k8s' changed"), "failed to vendor dependencies"), sink)
+	// The text written directly is emitted line by line.
+	_, err := sink.Write([]byte("waiting for the lock"))
+	assert.Equal(t, err, nil)
+	_, err = sink.Write([]byte("...\n\n"))
+	assert.Equal(t, err, nil)
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]interface{}{}
+		assert.Equal(t, json.Unmarshal([]byte(line), &record), nil)
+		assert.NotEmpty(t, record["time"])
+		delete(record, "time")
+		records = append(records, record)
+	}
+
+	assert.Equal(t, records, []map[string]interface{}{
+		{"level": "info", "type": "Default", "message": "adding dependency 'k8s'"},
+		{"level": "info", "type": "DownloadingFromOCI", "message": "downloading 'kcl-lang/k8s:1.28'", "module": "k8s", "source": "oci://ghcr.io/kcl-lang/k8s?tag=1.28"},
+		{"level": "info", "type": "DownloadFinished", "module": "k8s", "duration_ms": float64(1500)},
+		{"level": "error", "type": "FailedVendor", "message": "failed to vendor dependencies", "errors": []interface{}{"checksum for 'k8s' changed", "sum mismatch"}},
+		{"level": "info", "type": "Default", "message": "waiting for the lock..."},
+	})
+}
+
+func TestReportToText(t *testing.T) {
+	var buf bytes.Buffer
+	ReportMsgTo("adding dependency 'k8s'", &buf)
+	ReportEventTo(NewEvent(DownloadingFromOCI, "downloading 'kcl-lang/k8s:1.28'").WithModule("k8s"), &buf)
+	EmitTo(NewEvent(DownloadFinished).WithModule("k8s"), &buf)
+	assert.Equal(t, buf.String(), "adding dependency 'k8s'\ndownloading 'kcl-lang/k8s:1.28'\n")
+}
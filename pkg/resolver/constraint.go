@@ -0,0 +1,197 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// VersionRequirement is the version constraint of a dependency declared by a package.
+type VersionRequirement struct {
+	// Constraint is the version constraint, e.g. ">=1.28, <1.31" or "^1.28".
+	Constraint string
+	// Requirer is the package declaring the constraint, e.g. "pkg@0.0.1".
+	Requirer string
+}
+
+// VersionRequirements returns the version constraints collected during resolving,
+// the key is the name of the dependency.
+func (dr *DepsResolver) VersionRequirements() map[string][]VersionRequirement {
+	return dr.requirements
+}
+
+// CheckVersionRequirements checks that the selected version of the dependency satisfies
+// all the version constraints on it collected during resolving.
+func (dr *DepsResolver) CheckVersionRequirements(name, selectedVersion string) error {
+	reqs := dr.requirements[name]
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	satisfied, err := semver.Satisfies(selectedVersion, constraintsOf(reqs)...)
+	if err != nil {
+		return err
+	}
+	if !satisfied {
+		return reporter.NewErrorEvent(
+			reporter.UnsatisfiableVersionConstraints,
+			fmt.Errorf("the selected version '%s' of '%s' does not satisfy the version constraints:\n%s",
+				selectedVersion, name, formatRequirements(reqs)),
+		)
+	}
+	return nil
+}
+
+// selectConstrainedVersion selects the latest version of the dependency satisfying
+// its version constraint and the constraints on it from the other packages,
+// and pins the dependency to the selected version.
+// Only the dependencies from OCI registry support version constraints.
+func (dr *DepsResolver) selectConstrainedVersion(dep *pkg.Dependency, kMod *pkg.KclPkg, opts *ResolveOptions) error {
+	constraint := dep.Version
+	if _, err := semver.NewConstraint(constraint); err != nil {
+		return reporter.NewErrorEvent(
+			reporter.InvalidVersionConstraint,
+			err,
+			fmt.Sprintf("invalid version constraint of dependency '%s'", dep.Name),
+		)
+	}
+
+	// Copy the sources to avoid changing the dependency in kcl.mod,
+	// the version constraint is kept in kcl.mod and the selected version is pinned in kcl.mod.lock.
+	if dep.Source.ModSpec != nil {
+		modSpec := *dep.Source.ModSpec
+		dep.Source.ModSpec = &modSpec
+	}
+	if dep.Source.SpecOnly() {
+		dep.Source.Oci = &downloader.Oci{
+			Reg:  dr.Settings.DefaultOciRegistry(),
+			Repo: utils.JoinPath(dr.Settings.DefaultOciRepo(), dep.Source.ModSpec.Name),
+		}
+	} else if dep.Source.Oci != nil {
+		oci := *dep.Source.Oci
+		oci.Tag = ""
+		dep.Source.Oci = &oci
+	} else {
+		return reporter.NewErrorEvent(
+			reporter.InvalidVersionConstraint,
+			fmt.Errorf("the version constraint '%s' of dependency '%s' is only supported for the OCI source", constraint, dep.Name),
+		)
+	}
+
+	if dr.requirements == nil {
+		dr.requirements = make(map[string][]VersionRequirement)
+	}
+	dr.requirements[dep.Name] = append(dr.requirements[dep.Name], VersionRequirement{
+		Constraint: constraint,
+		Requirer:   fmt.Sprintf("%s@%s", kMod.GetPkgName(), kMod.GetPkgVersion()),
+	})
+	reqs := dr.requirements[dep.Name]
+
+	var selected string
+	if opts.Offline {
+		// The versions of the remote source are not available in offline mode,
+		// use the version pinned in kcl.mod.lock of the root module if it satisfies the constraints.
+		lockedDeps := opts.lockedDeps
+		if lockedDeps == nil {
+			lockedDeps = kMod.Dependencies.Deps
+		}
+		if lockedDeps != nil {
+			if lockDep, ok := lockedDeps.Get(dep.Name); ok {
+				if satisfied, err := semver.Satisfies(lockDep.Version, constraintsOf(reqs)...); err == nil && satisfied {
+					selected = lockDep.Version
+				}
+			}
+		}
+		if selected == "" {
+			return reporter.NewErrorEvent(
+				reporter.UnsatisfiableVersionConstraints,
+				fmt.Errorf("no version of '%s' pinned in kcl.mod.lock satisfies the version constraints in offline mode:\n%s",
+					dep.Name, formatRequirements(reqs)),
+			)
+		}
+	} else {
+		versions, err := dr.versionsOf(&dep.Source)
+		if err != nil {
+			return err
+		}
+
+		selected, err = semver.LatestSatisfiedVersion(versions, constraintsOf(reqs)...)
+		if errors.Is(err, kpmerrors.NoSatisfiedVersion) {
+			return reporter.NewErrorEvent(
+				reporter.UnsatisfiableVersionConstraints,
+				fmt.Errorf("no version of '%s' satisfies the version constraints:\n%s", dep.Name, formatRequirements(reqs)),
+			)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	dep.Source.Oci.Tag = selected
+	if dep.Source.ModSpec != nil {
+		dep.Source.ModSpec.Version = selected
+	}
+	dep.Version = selected
+	dep.FullName = dep.GenDepFullName()
+
+	return nil
+}
+
+// versionsOf returns all the versions of the remote source.
+// The versions are cached to avoid requesting the same source repeatedly during resolving.
+func (dr *DepsResolver) versionsOf(source *downloader.Source) ([]string, error) {
+	key := source.Oci.Reg + "/" + source.Oci.Repo
+	if versions, ok := dr.versions[key]; ok {
+		return versions, nil
+	}
+
+	lister, ok := dr.Downloader.(downloader.VersionLister)
+	if !ok {
+		return nil, fmt.Errorf("the downloader does not support listing the versions of '%s'", key)
+	}
+
+	credStore, err := downloader.LoadCredentialFile(dr.Settings.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := lister.Versions(downloader.NewDownloadOptions(
+		downloader.WithSource(*source),
+		downloader.WithLogWriter(dr.LogWriter),
+		downloader.WithSettings(*dr.Settings),
+		downloader.WithCredsStore(credStore),
+		downloader.WithInsecureSkipTLSverify(dr.InsecureSkipTLSverify),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if dr.versions == nil {
+		dr.versions = make(map[string][]string)
+	}
+	dr.versions[key] = versions
+	return versions, nil
+}
+
+func constraintsOf(reqs []VersionRequirement) []string {
+	constraints := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		constraints = append(constraints, req.Constraint)
+	}
+	return constraints
+}
+
+// formatRequirements formats the version constraints with their requirers, one per line.
+func formatRequirements(reqs []VersionRequirement) string {
+	lines := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		lines = append(lines, fmt.Sprintf("  '%s' required by '%s'", req.Constraint, req.Requirer))
+	}
+	return strings.Join(lines, "\n")
+}
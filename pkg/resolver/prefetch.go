@@ -0,0 +1,173 @@
+package resolver
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+
+	"kcl-lang.io/kpm/pkg/3rdparty/par"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/features"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+)
+
+// prefetch downloads the remote dependencies in the dependency graph of the module into the cache concurrently,
+// with at most 'Jobs' dependencies visited at a time, so that resolving the dependencies one by one afterwards
+// finds them in the cache and the resolved dependencies are still in a deterministic order.
+//
+// The dependencies with version constraints or without versions are left to the resolving,
+// for their versions are selected with the other dependencies or by the registry.
+// The errors are ignored, they are reported again by the resolving in the order of the dependencies.
+// The dependencies sharing a cache path, e.g. the refs of a git repository sharing the bare repository,
+// are downloaded one by one.
+func (dr *DepsResolver) prefetch(kMod *pkg.KclPkg, opts *ResolveOptions) {
+	if dr.digests == nil {
+		dr.digests = make(map[string]string)
+	}
+	// The logs of the concurrent downloads are written into the log writer one by one.
+	prefetcher := *dr
+	if _, ok := dr.LogWriter.(reporter.Sink); !ok && dr.LogWriter != nil {
+		prefetcher.LogWriter = &syncWriter{w: dr.LogWriter}
+	}
+
+	var mu sync.Mutex
+	var cacheLocks keyedMutex
+	sources := make(map[string]*downloader.Source)
+	sums := make(map[string]string)
+	digests := make(map[string]string)
+	var work par.Work[string]
+
+	var enqueue func(kMod *pkg.KclPkg)
+	enqueue = func(kMod *pkg.KclPkg) {
+		if kMod.ModFile.Dependencies.Deps == nil {
+			return
+		}
+		for _, depName := range kMod.ModFile.Dependencies.Deps.Keys() {
+			dep, _ := kMod.ModFile.Dependencies.Deps.Get(depName)
+			_, replaced := opts.replaces.Get(dep.Name)
+			if semver.IsConstraint(dep.Version) && !replaced {
+				continue
+			}
+			source, err := prefetcher.depSource(&dep, kMod, opts)
+			if err != nil {
+				continue
+			}
+			// The visitors complete the sources in place, e.g. with the default registry,
+			// so the sources shared with the module are copied.
+			source = cloneSource(source)
+			if (source.Oci != nil && source.Oci.NoRef()) || (source.Git != nil && source.Git.NoRef()) ||
+				(source.SpecOnly() && source.ModSpec.Version == "") {
+				continue
+			}
+			key := sourceKey(source)
+			if key == "" {
+				continue
+			}
+
+			mu.Lock()
+			if _, ok := sources[key]; !ok {
+				sources[key] = source
+				sums[key] = prefetcher.lockedSum(&dep, source, opts)
+				digests[key] = prefetcher.lockedDigest(&dep, source, opts)
+			}
+			mu.Unlock()
+			work.Add(key)
+		}
+	}
+
+	enqueue(kMod)
+	work.Do(dr.Jobs, func(key string) {
+		mu.Lock()
+		source, sum, digest := sources[key], sums[key], digests[key]
+		mu.Unlock()
+
+		depVisitor, err := prefetcher.selectVisitor(source, sum, digest, opts)
+		if err != nil {
+			return
+		}
+		unlock := cacheLocks.Lock(prefetcher.cachePath(source, opts))
+		_ = depVisitor.Visit(source, func(kclMod *pkg.KclPkg) error {
+			enqueue(kclMod)
+			return nil
+		})
+		unlock()
+
+		// The digests of the packages downloaded are recorded for resolving, which finds the packages in the cache.
+		if source.Oci != nil && source.Oci.Digest != "" {
+			mu.Lock()
+			dr.digests[key] = source.Oci.Digest
+			mu.Unlock()
+		}
+	})
+}
+
+// cachePath returns the cache path the remote source is downloaded into as the remote visitor does.
+func (dr *DepsResolver) cachePath(source *downloader.Source, opts *ResolveOptions) string {
+	root := opts.CachePath
+	if root == "" {
+		root = dr.DefaultCachePath
+	}
+	if ok, err := features.Enabled(features.SupportNewStorage); err == nil && ok {
+		return source.CachePath(filepath.Join(root, source.Type(), "cache"))
+	}
+	return source.CachePath(root)
+}
+
+// keyedMutex is the mutexes locked by the keys.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// Lock locks the mutex of the key and returns the function to unlock it.
+func (km *keyedMutex) Lock(key string) func() {
+	km.mu.Lock()
+	if km.locks == nil {
+		km.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := km.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		km.locks[key] = lock
+	}
+	km.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// cloneSource returns a deep copy of the source.
+func cloneSource(source *downloader.Source) *downloader.Source {
+	clone := *source
+	if source.ModSpec != nil {
+		modSpec := *source.ModSpec
+		clone.ModSpec = &modSpec
+	}
+	if source.Git != nil {
+		git := *source.Git
+		clone.Git = &git
+	}
+	if source.Oci != nil {
+		oci := *source.Oci
+		clone.Oci = &oci
+	}
+	if source.Local != nil {
+		local := *source.Local
+		clone.Local = &local
+	}
+	return &clone
+}
+
+// syncWriter is the writer safe for concurrent use.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(p)
+}
@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 
+	orderedmap "github.com/elliotchance/orderedmap/v2"
+
+	"kcl-lang.io/kpm/pkg/cache"
 	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
 	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/semver"
 	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 	"kcl-lang.io/kpm/pkg/visitor"
@@ -29,6 +34,15 @@ type ResolveOptions struct {
 	CachePath string
 	// Offline is the flag to resolve the package offline.
 	Offline bool
+	// SkipMissing is the flag to skip the dependencies not found in the cache in offline mode,
+	// instead of failing on the first one.
+	SkipMissing bool
+	// replaces is the '[replace]' section of the root module, which is applied to the whole dependency graph.
+	replaces *pkg.Replaces
+	// replaceRoot is the home path of the root module, the relative local paths in the replacements are based on it.
+	replaceRoot string
+	// lockedDeps is the kcl.mod.lock of the root module, the checksums in it are used to restore the dependencies from the cache.
+	lockedDeps *orderedmap.OrderedMap[string, pkg.Dependency]
 }
 
 // WithOffline sets the offline option to resolve the package.
@@ -39,6 +53,31 @@ func WithOffline(offline bool) ResolveOption {
 	}
 }
 
+// WithSkipMissing sets the flag to skip the dependencies not found in the cache in offline mode.
+func WithSkipMissing(skipMissing bool) ResolveOption {
+	return func(opts *ResolveOptions) error {
+		opts.SkipMissing = skipMissing
+		return nil
+	}
+}
+
+// withReplaces sets the replacements of the root module to resolve the dependencies of the sub-packages.
+func withReplaces(replaces *pkg.Replaces, replaceRoot string) ResolveOption {
+	return func(opts *ResolveOptions) error {
+		opts.replaces = replaces
+		opts.replaceRoot = replaceRoot
+		return nil
+	}
+}
+
+// withLockedDeps sets the locked dependencies of the root module to resolve the dependencies of the sub-packages.
+func withLockedDeps(lockedDeps *orderedmap.OrderedMap[string, pkg.Dependency]) ResolveOption {
+	return func(opts *ResolveOptions) error {
+		opts.lockedDeps = lockedDeps
+		return nil
+	}
+}
+
 func WithResolveKclMod(kMod *pkg.KclPkg) ResolveOption {
 	return func(opts *ResolveOptions) error {
 		opts.kMod = kMod
@@ -70,60 +109,215 @@ type DepsResolver struct {
 	Settings              *settings.Settings
 	LogWriter             io.Writer
 	ResolveFuncs          []resolveFunc
+	// Jobs is the maximum number of the remote dependencies downloaded concurrently before resolving,
+	// the dependencies are downloaded one by one during resolving if it is less than 2.
+	Jobs int
+	// Cache is the content-addressed store to restore the locked dependencies from before downloading them.
+	Cache *cache.Store
+	// CheckSource checks the source of each remote dependency before it is restored or downloaded,
+	// e.g. verifies its signature, the source is checked in offline mode if the flag is set.
+	CheckSource func(source *downloader.Source, offline bool) error
+
+	// requirements is the version constraints collected during resolving, the key is the name of the dependency.
+	requirements map[string][]VersionRequirement
+	// versions caches the versions of the remote sources listed during resolving.
+	versions map[string][]string
+	// digests records the manifest digests of the OCI sources downloaded before resolving,
+	// the key is the source returned by 'sourceKey'.
+	digests map[string]string
 }
 
-// Resolve resolves the dependencies of the package.
-func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
-	opts := &ResolveOptions{}
-	for _, option := range options {
-		if err := option(opts); err != nil {
-			return err
-		}
+// selectVisitor selects the visitor for the source.
+// For remote source, it will use the RemoteVisitor and enable the cache.
+// For local source, it will use the PkgVisitor.
+// The remote source locked with the checksum 'sum' is restored from the cache if it is there,
+// and the OCI source locked with the manifest digest 'digest' is refused if its tag resolves to another digest.
+func (dr *DepsResolver) selectVisitor(source *downloader.Source, sum, digest string, opts *ResolveOptions) (visitor.Visitor, error) {
+	pkgVisitor := &visitor.PkgVisitor{
+		Settings:  dr.Settings,
+		LogWriter: dr.LogWriter,
 	}
-	// visitorSelectorFunc selects the visitor for the source.
-	// For remote source, it will use the RemoteVisitor and enable the cache.
-	// For local source, it will use the PkgVisitor.
-	visitorSelectorFunc := func(source *downloader.Source) (visitor.Visitor, error) {
-		pkgVisitor := &visitor.PkgVisitor{
-			Settings:  dr.Settings,
-			LogWriter: dr.LogWriter,
+
+	if source.IsRemote() {
+		var cachePath string
+		if opts.CachePath != "" {
+			cachePath = opts.CachePath
+		} else {
+			cachePath = dr.DefaultCachePath
 		}
 
-		if source.IsRemote() {
-			var cachePath string
-			if opts.CachePath != "" {
-				cachePath = opts.CachePath
-			} else {
-				cachePath = dr.DefaultCachePath
-			}
+		return &visitor.RemoteVisitor{
+			PkgVisitor:            pkgVisitor,
+			Downloader:            dr.Downloader,
+			InsecureSkipTLSverify: dr.InsecureSkipTLSverify,
+			EnableCache:           opts.EnableCache,
+			CachePath:             cachePath,
+			VisitedSpace:          cachePath,
+			Offline:               opts.Offline,
+			SkipMissing:           opts.SkipMissing,
+			Store:                 dr.Cache,
+			Sum:                   sum,
+			Digest:                digest,
+			CheckSource:           dr.CheckSource,
+		}, nil
+	} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
+		return visitor.NewArchiveVisitor(pkgVisitor), nil
+	} else if source.IsLocalPath() {
+		rootPath, err := source.FindRootPath()
+		if err != nil {
+			return nil, err
+		}
+		kclmodpath := filepath.Join(rootPath, constants.KCL_MOD)
+		if utils.DirExists(kclmodpath) {
+			return pkgVisitor, nil
+		} else {
+			return visitor.NewVirtualPkgVisitor(pkgVisitor), nil
+		}
+	} else {
+		return nil, fmt.Errorf("unsupported source")
+	}
+}
 
-			return &visitor.RemoteVisitor{
-				PkgVisitor:            pkgVisitor,
-				Downloader:            dr.Downloader,
-				InsecureSkipTLSverify: dr.InsecureSkipTLSverify,
-				EnableCache:           opts.EnableCache,
-				CachePath:             cachePath,
-				VisitedSpace:          cachePath,
-				Offline:               opts.Offline,
+// depSource returns the source to resolve the dependency of the module from,
+// the replacement is recorded in the dependency if the dependency is replaced.
+func (dr *DepsResolver) depSource(dep *pkg.Dependency, kMod *pkg.KclPkg, opts *ResolveOptions) (*downloader.Source, error) {
+	if replace, replaced := opts.replaces.Get(dep.Name); replaced {
+		// The dependency is resolved from the replacement and the replacement is recorded in kcl.mod.lock.
+		replaceStr, err := replace.ToString()
+		if err != nil {
+			return nil, err
+		}
+		dep.Replace = replaceStr
+		if replace.IsLocalPath() && !filepath.IsAbs(replace.Local.Path) {
+			return &downloader.Source{
+				Local: &downloader.Local{
+					Path: filepath.Join(opts.replaceRoot, replace.Local.Path),
+				},
 			}, nil
-		} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
-			return visitor.NewArchiveVisitor(pkgVisitor), nil
-		} else if source.IsLocalPath() {
-			rootPath, err := source.FindRootPath()
-			if err != nil {
-				return nil, err
-			}
-			kclmodpath := filepath.Join(rootPath, constants.KCL_MOD)
-			if utils.DirExists(kclmodpath) {
-				return pkgVisitor, nil
-			} else {
-				return visitor.NewVirtualPkgVisitor(pkgVisitor), nil
-			}
-		} else {
-			return nil, fmt.Errorf("unsupported source")
 		}
+		return replace, nil
+	}
+
+	// Check if the dependency is a local path and it is not an absolute path.
+	// If it is not an absolute path, transform the path to an absolute path.
+	if dep.Source.IsLocalPath() && !filepath.IsAbs(dep.Source.Local.Path) {
+		return &downloader.Source{
+			Local: &downloader.Local{
+				Path: filepath.Join(kMod.HomePath, dep.Source.Local.Path),
+			},
+			ModSpec: dep.Source.ModSpec,
+		}, nil
+	}
+	return &dep.Source, nil
+}
+
+// lockedDep returns the dependency in the kcl.mod.lock of the root module,
+// or false if the dependency is replaced or the locked one is not the same version from the same source.
+func (dr *DepsResolver) lockedDep(dep *pkg.Dependency, source *downloader.Source, opts *ResolveOptions) (pkg.Dependency, bool) {
+	if opts.lockedDeps == nil || dep.Replace != "" {
+		return pkg.Dependency{}, false
+	}
+	locked, ok := opts.lockedDeps.Get(dep.Name)
+	if !ok || locked.Replace != "" {
+		return pkg.Dependency{}, false
+	}
+	// The dependency pinned to another digest in kcl.mod is not the locked one.
+	if source.Oci != nil && source.Oci.Digest != "" && !source.Oci.DigestResolved &&
+		(locked.Source.Oci == nil || locked.Source.Oci.Digest != source.Oci.Digest) {
+		return pkg.Dependency{}, false
+	}
+	// The same version moved to another registry, repository or path is not the locked one.
+	if locked.Version != "" && locked.Version == dep.Version && sameOrigin(&locked.Source, source, opts.replaceRoot) {
+		return locked, true
+	}
+	lockedSource, err := requirement(&locked.Source)
+	if err != nil {
+		return pkg.Dependency{}, false
+	}
+	if depSource, err := requirement(source); err == nil && depSource == lockedSource {
+		return locked, true
+	}
+	return pkg.Dependency{}, false
+}
+
+// sameOrigin returns true if the sources are the same package from the same registry, repository or path,
+// regardless of the references, e.g. the tags, the commits and the digests.
+func sameOrigin(locked, source *downloader.Source, root string) bool {
+	switch {
+	case locked.Oci != nil && source.Oci != nil:
+		return locked.Oci.Reg == source.Oci.Reg && locked.Oci.Repo == source.Oci.Repo
+	case locked.Git != nil && source.Git != nil:
+		return gitRepo(locked.Git.Url) == gitRepo(source.Git.Url) && locked.Git.Package == source.Git.Package
+	case locked.Local != nil && source.Local != nil:
+		lockedPath := locked.Local.Path
+		if !filepath.IsAbs(lockedPath) {
+			lockedPath = filepath.Join(root, lockedPath)
+		}
+		return filepath.Clean(lockedPath) == filepath.Clean(source.Local.Path)
+	default:
+		return false
+	}
+}
+
+// gitRepo returns the git url without the scheme and the '.git' suffix,
+// e.g. 'github.com/kcl-lang/konfig' for 'git://github.com/kcl-lang/konfig.git' and 'git@github.com:kcl-lang/konfig.git'.
+func gitRepo(gitUrl string) string {
+	if _, rest, ok := strings.Cut(gitUrl, "://"); ok {
+		gitUrl = rest
+	} else if rest, ok := strings.CutPrefix(gitUrl, "git@"); ok {
+		gitUrl = strings.Replace(rest, ":", "/", 1)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(gitUrl, "/"), ".git")
+}
+
+// requirement returns the source string without the manifest digest, which is recorded in kcl.mod.lock
+// and not a part of the requirement in kcl.mod unless it is pinned.
+func requirement(source *downloader.Source) (string, error) {
+	if source.Oci != nil && source.Oci.Digest != "" {
+		source = cloneSource(source)
+		source.Oci.Digest = ""
+	}
+	return source.ToString()
+}
+
+// lockedSum returns the checksum of the dependency in the kcl.mod.lock of the root module,
+// or empty if the dependency is not locked.
+func (dr *DepsResolver) lockedSum(dep *pkg.Dependency, source *downloader.Source, opts *ResolveOptions) string {
+	if locked, ok := dr.lockedDep(dep, source, opts); ok {
+		return locked.Sum
+	}
+	return ""
+}
+
+// lockedDigest returns the manifest digest of the OCI dependency in the kcl.mod.lock of the root module,
+// or the digest of the dependency downloaded before resolving if it is not locked.
+func (dr *DepsResolver) lockedDigest(dep *pkg.Dependency, source *downloader.Source, opts *ResolveOptions) string {
+	if locked, ok := dr.lockedDep(dep, source, opts); ok && locked.Source.Oci != nil && locked.Source.Oci.Digest != "" {
+		return locked.Source.Oci.Digest
 	}
+	return dr.digests[sourceKey(source)]
+}
 
+// sourceKey returns the key of the source to identify the remote dependencies in the dependency graph.
+func sourceKey(source *downloader.Source) string {
+	key, err := source.ToString()
+	if err != nil {
+		return ""
+	}
+	if !source.ModSpec.IsNil() {
+		key = fmt.Sprintf("%s#%s", key, source.ModSpec.ToString())
+	}
+	return key
+}
+
+// Resolve resolves the dependencies of the package.
+func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
+	opts := &ResolveOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return err
+		}
+	}
 	kMod := opts.kMod
 	if kMod == nil {
 		return fmt.Errorf("kcl module is nil")
@@ -134,27 +328,45 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 		return fmt.Errorf("kcl.mod dependencies is nil")
 	}
 
+	// Only the replacements of the root module take effect.
+	if opts.replaces == nil {
+		opts.replaces = &kMod.ModFile.Replaces
+		opts.replaceRoot = kMod.HomePath
+		opts.lockedDeps = kMod.Dependencies.Deps
+
+		// Download the remote dependencies of the whole graph concurrently into the cache before resolving.
+		if dr.Jobs > 1 && opts.EnableCache && !opts.Offline {
+			dr.prefetch(kMod, opts)
+		}
+	}
+
 	for _, depName := range modDeps.Keys() {
 		dep, ok := modDeps.Get(depName)
 		if !ok {
 			return fmt.Errorf("failed to get dependency %s", depName)
 		}
 
-		// Check if the dependency is a local path and it is not an absolute path.
-		// If it is not an absolute path, transform the path to an absolute path.
-		var depSource *downloader.Source
-		if dep.Source.IsLocalPath() && !filepath.IsAbs(dep.Source.Local.Path) {
-			depSource = &downloader.Source{
-				Local: &downloader.Local{
-					Path: filepath.Join(kMod.HomePath, dep.Source.Local.Path),
-				},
-				ModSpec: dep.Source.ModSpec,
+		_, replaced := opts.replaces.Get(dep.Name)
+
+		// Select the version for the dependency with the version constraint, e.g. ">=1.28, <1.31".
+		if semver.IsConstraint(dep.Version) && !replaced {
+			err := dr.selectConstrainedVersion(&dep, kMod, opts)
+			if err != nil {
+				return err
 			}
-		} else {
-			depSource = &dep.Source
 		}
 
-		depVisitor, err := visitorSelectorFunc(depSource)
+		depSource, err := dr.depSource(&dep, kMod, opts)
+		if err != nil {
+			return err
+		}
+
+		depVisitor, err := dr.selectVisitor(
+			depSource,
+			dr.lockedSum(&dep, depSource, opts),
+			dr.lockedDigest(&dep, depSource, opts),
+			opts,
+		)
 		if err != nil {
 			return err
 		}
@@ -167,10 +379,14 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 					return err
 				}
 			}
-			err = dr.Resolve(
+			err := dr.Resolve(
 				WithResolveKclMod(kclMod),
 				WithEnableCache(opts.EnableCache),
 				WithCachePath(opts.CachePath),
+				WithOffline(opts.Offline),
+				WithSkipMissing(opts.SkipMissing),
+				withReplaces(opts.replaces, opts.replaceRoot),
+				withLockedDeps(opts.lockedDeps),
 			)
 			if err != nil {
 				return err
@@ -2,17 +2,27 @@ package resolver
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/otiai10/copy"
 	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/cache"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/features"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
 )
 
 const testDataDir = "test_data"
@@ -74,3 +84,630 @@ func TestResolver(t *testing.T) {
 	assert.Equal(t, len(res), 3)
 	assert.Equal(t, res, expected)
 }
+
+// fakeOciDownloader downloads the packages from the local directory 'registry',
+// the package is stored in '<registry>/<name>/<tag>'.
+type fakeOciDownloader struct {
+	registry string
+}
+
+func (d *fakeOciDownloader) Download(opts *downloader.DownloadOptions) error {
+	return copy.Copy(
+		filepath.Join(d.registry, filepath.Base(opts.Source.Oci.Repo), opts.Source.Oci.Tag),
+		opts.LocalPath,
+	)
+}
+
+func (d *fakeOciDownloader) LatestVersion(opts *downloader.DownloadOptions) (string, error) {
+	return "", fmt.Errorf("the latest version is not supported")
+}
+
+func (d *fakeOciDownloader) Versions(opts *downloader.DownloadOptions) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(d.registry, filepath.Base(opts.Source.Oci.Repo)))
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, entry.Name())
+	}
+	return versions, nil
+}
+
+func TestResolveVersionConstraints(t *testing.T) {
+	testDir := getTestDir("test_resolve_constraint")
+
+	resolveWithConstraints := func(pkgName string, offline bool) ([]string, *DepsResolver, *pkg.KclPkg, error) {
+		var res []string
+		var buf bytes.Buffer
+		resolver := DepsResolver{
+			Downloader: &fakeOciDownloader{registry: filepath.Join(testDir, "registry")},
+			Settings:   settings.GetSettings(),
+			LogWriter:  &buf,
+			ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+				res = append(res, fmt.Sprintf("%s -> %s@%s", parentPkg.GetPkgName(), dep.Name, dep.Version))
+				return nil
+			}},
+		}
+
+		kMod, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(filepath.Join(testDir, pkgName)),
+			pkg.WithSettings(settings.GetSettings()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Resolve(
+			WithResolveKclMod(kMod),
+			WithCachePath(t.TempDir()),
+			WithOffline(offline),
+		)
+
+		return res, &resolver, kMod, err
+	}
+
+	res, resolver, kMod, err := resolveWithConstraints("pkg", false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"pkg -> dep@0.0.1",
+		"dep -> k8s@1.31.0",
+		"pkg -> k8s@1.30.0",
+	}, res)
+	// The version constraint in kcl.mod should not be changed.
+	k8s, ok := kMod.ModFile.Dependencies.Deps.Get("k8s")
+	assert.True(t, ok)
+	assert.Equal(t, ">=1.28, <1.31", k8s.Source.ModSpec.Version)
+	assert.Equal(t, ">=1.28, <1.31", k8s.Source.Oci.Tag)
+	assert.Equal(t, map[string][]VersionRequirement{
+		"k8s": {
+			{Constraint: "^1.29", Requirer: "dep@0.0.1"},
+			{Constraint: ">=1.28, <1.31", Requirer: "pkg@0.0.1"},
+		},
+	}, resolver.VersionRequirements())
+	assert.Nil(t, resolver.CheckVersionRequirements("k8s", "1.30.0"))
+	assert.Equal(t, "the selected version '1.31.0' of 'k8s' does not satisfy the version constraints:\n"+
+		"  '^1.29' required by 'dep@0.0.1'\n"+
+		"  '>=1.28, <1.31' required by 'pkg@0.0.1'\n",
+		resolver.CheckVersionRequirements("k8s", "1.31.0").Error())
+
+	_, _, _, err = resolveWithConstraints("conflict", false)
+	assert.Equal(t, "no version of 'k8s' satisfies the version constraints:\n"+
+		"  '^1.29' required by 'dep@0.0.1'\n"+
+		"  '~1.28' required by 'conflict@0.0.1'\n", err.Error())
+
+	_, _, _, err = resolveWithConstraints("invalid", false)
+	assert.ErrorIs(t, err, kpmerrors.InvalidVersionConstraint)
+	assert.ErrorContains(t, err, "invalid version constraint of dependency 'k8s'")
+
+	// In offline mode, the version pinned in kcl.mod.lock is selected.
+	res, _, _, err = resolveWithConstraints("offline", true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"offline -> k8s@1.29.0"}, res)
+}
+
+func TestResolveReplaces(t *testing.T) {
+	testDir := getTestDir("test_resolve_replace")
+
+	var res []string
+	var buf bytes.Buffer
+	resolver := DepsResolver{
+		// The replaced dependencies are not downloaded from the registry.
+		Downloader: &fakeOciDownloader{registry: filepath.Join(testDir, "not_exist")},
+		Settings:   settings.GetSettings(),
+		LogWriter:  &buf,
+		ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+			res = append(res, fmt.Sprintf("%s -> %s@%s replaced by '%s'", parentPkg.GetPkgName(), dep.Name, dep.Version, dep.Replace))
+			return nil
+		}},
+	}
+
+	kMod, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(filepath.Join(testDir, "pkg")),
+		pkg.WithSettings(settings.GetSettings()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = resolver.Resolve(
+		WithResolveKclMod(kMod),
+		WithCachePath(t.TempDir()),
+	)
+	assert.Nil(t, err)
+	// The replacements of the root module are applied to the transitive dependencies,
+	// and the replacements of the dependencies are ignored.
+	assert.Equal(t, []string{
+		"pkg -> dep@0.0.1 replaced by ''",
+		"dep -> k8s@1.31.1 replaced by '../k8s'",
+		"pkg -> k8s@1.31.1 replaced by '../k8s'",
+	}, res)
+}
+
+// countingDownloader counts the downloads of the packages and the maximum number of the concurrent downloads,
+// the packages already downloaded into the local path are not downloaded again.
+type countingDownloader struct {
+	fakeOciDownloader
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	downloads map[string]int
+}
+
+func (d *countingDownloader) Download(opts *downloader.DownloadOptions) error {
+	if _, err := os.Stat(filepath.Join(opts.LocalPath, "kcl.mod")); err == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.active++
+	d.maxActive = max(d.maxActive, d.active)
+	d.downloads[opts.Source.Oci.Repo]++
+	d.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	err := d.fakeOciDownloader.Download(opts)
+
+	d.mu.Lock()
+	d.active--
+	d.mu.Unlock()
+	return err
+}
+
+func TestResolveWithJobs(t *testing.T) {
+	// pkg -> a, b, c, d; a -> e; b -> e; c -> f
+	graph := map[string][]string{
+		"pkg": {"a", "b", "c", "d"},
+		"a":   {"e"},
+		"b":   {"e"},
+		"c":   {"f"},
+	}
+	registry := t.TempDir()
+	writeMod := func(dir, name string) {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("[package]\nname = \"%s\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n", name))
+		if deps := graph[name]; len(deps) != 0 {
+			sb.WriteString("\n[dependencies]\n")
+			for _, dep := range deps {
+				sb.WriteString(fmt.Sprintf("%s = { oci = \"oci://example.com/test/%s\", tag = \"0.0.1\" }\n", dep, dep))
+			}
+		}
+		assert.Nil(t, os.MkdirAll(dir, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "kcl.mod"), []byte(sb.String()), 0644))
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		writeMod(filepath.Join(registry, name, "0.0.1"), name)
+	}
+
+	resolveWithJobs := func(jobs int) ([]string, *countingDownloader) {
+		pkgPath := t.TempDir()
+		writeMod(pkgPath, "pkg")
+
+		var res []string
+		var buf bytes.Buffer
+		d := &countingDownloader{
+			fakeOciDownloader: fakeOciDownloader{registry: registry},
+			downloads:         map[string]int{},
+		}
+		resolver := DepsResolver{
+			Downloader: d,
+			Settings:   settings.GetSettings(),
+			LogWriter:  &buf,
+			Jobs:       jobs,
+			ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+				res = append(res, fmt.Sprintf("%s -> %s", parentPkg.GetPkgName(), dep.Name))
+				return nil
+			}},
+		}
+
+		kMod, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(pkgPath),
+			pkg.WithSettings(settings.GetSettings()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Resolve(
+			WithResolveKclMod(kMod),
+			WithEnableCache(true),
+			WithCachePath(t.TempDir()),
+		)
+		assert.Nil(t, err)
+		return res, d
+	}
+
+	expected := []string{
+		"pkg -> a",
+		"a -> e",
+		"pkg -> b",
+		"b -> e",
+		"pkg -> c",
+		"c -> f",
+		"pkg -> d",
+	}
+	expectedDownloads := map[string]int{
+		"test/a": 1, "test/b": 1, "test/c": 1, "test/d": 1, "test/e": 1, "test/f": 1,
+	}
+
+	res, d := resolveWithJobs(1)
+	assert.Equal(t, expected, res)
+	assert.Equal(t, expectedDownloads, d.downloads)
+	assert.Equal(t, 1, d.maxActive)
+
+	// The dependencies are downloaded concurrently, and resolved in the same order.
+	res, d = resolveWithJobs(4)
+	assert.Equal(t, expected, res)
+	assert.Equal(t, expectedDownloads, d.downloads)
+	assert.Greater(t, d.maxActive, 1)
+}
+
+// gitRefsDownloader downloads the refs of the git repositories as the packages named by the refs,
+// and records the maximum number of the concurrent downloads into the same cache path.
+type gitRefsDownloader struct {
+	mu        sync.Mutex
+	active    map[string]int
+	maxActive int
+}
+
+func (d *gitRefsDownloader) Download(opts *downloader.DownloadOptions) error {
+	d.mu.Lock()
+	d.active[opts.CachePath]++
+	d.maxActive = max(d.maxActive, d.active[opts.CachePath])
+	d.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	err := os.MkdirAll(opts.LocalPath, 0755)
+	if err == nil {
+		name := opts.Source.Git.Tag
+		err = os.WriteFile(filepath.Join(opts.LocalPath, "kcl.mod"), []byte(fmt.Sprintf("[package]\nname = \"%s\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n", name)), 0644)
+	}
+
+	d.mu.Lock()
+	d.active[opts.CachePath]--
+	d.mu.Unlock()
+	return err
+}
+
+func (d *gitRefsDownloader) LatestVersion(opts *downloader.DownloadOptions) (string, error) {
+	return "", errors.New("not supported")
+}
+
+func TestPrefetchSharedCachePath(t *testing.T) {
+	if ok, _ := features.Enabled(features.SupportNewStorage); !ok {
+		features.Enable(features.SupportNewStorage)
+		defer features.Disable(features.SupportNewStorage)
+	}
+
+	pkgPath := t.TempDir()
+	assert.Nil(t, os.WriteFile(
+		filepath.Join(pkgPath, "kcl.mod"),
+		[]byte("[package]\nname = \"pkg\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n\n[dependencies]\n"+
+			"a = { git = \"https://example.com/test/repo.git\", tag = \"a\" }\n"+
+			"b = { git = \"https://example.com/test/repo.git\", tag = \"b\" }\n"+
+			"c = { git = \"https://example.com/test/repo.git\", tag = \"c\" }\n"),
+		0644,
+	))
+	kMod, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(pkgPath),
+		pkg.WithSettings(settings.GetSettings()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &gitRefsDownloader{active: map[string]int{}}
+	resolver := DepsResolver{
+		Downloader: d,
+		Settings:   settings.GetSettings(),
+		LogWriter:  &bytes.Buffer{},
+		Jobs:       4,
+	}
+	err = resolver.Resolve(
+		WithResolveKclMod(kMod),
+		WithEnableCache(true),
+		WithCachePath(t.TempDir()),
+	)
+	assert.Nil(t, err)
+	// The refs of the repository share the bare repository in the cache, so they are not downloaded concurrently.
+	assert.Equal(t, 1, d.maxActive)
+}
+
+func TestResolveFromCache(t *testing.T) {
+	registry := t.TempDir()
+	modDir := filepath.Join(registry, "a", "0.0.1")
+	assert.Nil(t, os.MkdirAll(modDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(modDir, "kcl.mod"), []byte("[package]\nname = \"a\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n"), 0644))
+	sum, err := utils.HashDir(modDir)
+	assert.Nil(t, err)
+
+	store := cache.NewStore(t.TempDir())
+	assert.Nil(t, store.Put(sum, modDir))
+
+	resolveWithLockIn := func(cachePath, lockedVersion, lockedReg string) *countingDownloader {
+		pkgPath := t.TempDir()
+		assert.Nil(t, os.WriteFile(
+			filepath.Join(pkgPath, "kcl.mod"),
+			[]byte("[package]\nname = \"pkg\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n\n[dependencies]\na = { oci = \"oci://example.com/test/a\", tag = \"0.0.1\" }\n"),
+			0644,
+		))
+		kMod, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(pkgPath),
+			pkg.WithSettings(settings.GetSettings()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kMod.Dependencies.Deps.Set("a", pkg.Dependency{
+			Name:    "a",
+			Version: lockedVersion,
+			Sum:     sum,
+			Source: downloader.Source{
+				Oci: &downloader.Oci{Reg: lockedReg, Repo: "test/a", Tag: lockedVersion},
+			},
+		})
+
+		d := &countingDownloader{
+			fakeOciDownloader: fakeOciDownloader{registry: registry},
+			downloads:         map[string]int{},
+		}
+		resolver := DepsResolver{
+			Downloader: d,
+			Settings:   settings.GetSettings(),
+			LogWriter:  &bytes.Buffer{},
+			Cache:      store,
+		}
+		err = resolver.Resolve(
+			WithResolveKclMod(kMod),
+			WithEnableCache(true),
+			WithCachePath(cachePath),
+		)
+		assert.Nil(t, err)
+		return d
+	}
+	resolveWithLock := func(lockedVersion, lockedReg string) *countingDownloader {
+		return resolveWithLockIn(t.TempDir(), lockedVersion, lockedReg)
+	}
+
+	// The locked dependency is restored from the cache without downloading.
+	d := resolveWithLock("0.0.1", "example.com")
+	assert.Empty(t, d.downloads)
+
+	// The dependency locked with the other version is downloaded.
+	d = resolveWithLock("0.0.2", "example.com")
+	assert.Equal(t, map[string]int{"test/a": 1}, d.downloads)
+
+	// The dependency moved to another registry is downloaded.
+	d = resolveWithLock("0.0.1", "ghcr.io")
+	assert.Equal(t, map[string]int{"test/a": 1}, d.downloads)
+
+	// The dependency in the local path is not rehashed if it is in the cache,
+	// and the dependency removed from the local path is restored from the cache.
+	cachePath := t.TempDir()
+	d = resolveWithLockIn(cachePath, "0.0.1", "example.com")
+	assert.Empty(t, d.downloads)
+	var modified string
+	err = filepath.WalkDir(cachePath, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Name() == "kcl.mod" {
+			modified = filepath.Join(filepath.Dir(path), "main.k")
+		}
+		return err
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, modified)
+	assert.Nil(t, os.WriteFile(modified, []byte("a = 1\n"), 0644))
+	d = resolveWithLockIn(cachePath, "0.0.1", "example.com")
+	assert.Empty(t, d.downloads)
+	assert.FileExists(t, modified)
+	assert.Nil(t, os.RemoveAll(filepath.Dir(modified)))
+	d = resolveWithLockIn(cachePath, "0.0.1", "example.com")
+	assert.Empty(t, d.downloads)
+	assert.FileExists(t, filepath.Join(filepath.Dir(modified), "kcl.mod"))
+
+	// The dependency modified in the local path is verified and downloaded again if it is not in the cache,
+	// and the dependency downloaded is stored into the cache.
+	assert.Nil(t, os.WriteFile(modified, []byte("a = 1\n"), 0644))
+	_, err = store.Clean(0)
+	assert.Nil(t, err)
+	d = resolveWithLockIn(cachePath, "0.0.1", "example.com")
+	assert.Equal(t, map[string]int{"test/a": 1}, d.downloads)
+	assert.NoFileExists(t, modified)
+	assert.True(t, store.Has(sum))
+
+	// The intact dependency in the local path is stored into the cache without downloading.
+	_, err = store.Clean(0)
+	assert.Nil(t, err)
+	d = resolveWithLockIn(cachePath, "0.0.1", "example.com")
+	assert.Empty(t, d.downloads)
+	assert.True(t, store.Has(sum))
+}
+
+// offlineDownloader fails the downloads of the packages missing in the registry as the downloaders in offline mode,
+// and records the packages downloaded without offline mode.
+type offlineDownloader struct {
+	fakeOciDownloader
+	mu     sync.Mutex
+	online []string
+}
+
+func (d *offlineDownloader) Download(opts *downloader.DownloadOptions) error {
+	d.mu.Lock()
+	if !opts.Offline {
+		d.online = append(d.online, opts.Source.Oci.Repo)
+	}
+	d.mu.Unlock()
+
+	if !utils.DirExists(filepath.Join(d.registry, filepath.Base(opts.Source.Oci.Repo), opts.Source.Oci.Tag)) {
+		return downloader.NewNotFoundOfflineError(opts.Source)
+	}
+	return d.fakeOciDownloader.Download(opts)
+}
+
+func (d *offlineDownloader) LatestVersion(opts *downloader.DownloadOptions) (string, error) {
+	panic("the latest version must not be fetched in offline mode")
+}
+
+func TestResolveOffline(t *testing.T) {
+	registry := t.TempDir()
+	writeMod := func(dir, name, deps string) {
+		modFile := fmt.Sprintf("[package]\nname = \"%s\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n", name)
+		if deps != "" {
+			modFile += "\n[dependencies]\n" + deps
+		}
+		assert.Nil(t, os.MkdirAll(dir, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "kcl.mod"), []byte(modFile), 0644))
+	}
+	// a -> b, and b is missing in the cache.
+	writeMod(filepath.Join(registry, "a", "0.0.1"), "a", "b = { oci = \"oci://example.com/test/b\", tag = \"0.0.2\" }\n")
+
+	resolveOffline := func(deps string, skipMissing bool) (*offlineDownloader, []string, error) {
+		pkgPath := t.TempDir()
+		writeMod(pkgPath, "pkg", deps)
+		kMod, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(pkgPath),
+			pkg.WithSettings(settings.GetSettings()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res []string
+		d := &offlineDownloader{fakeOciDownloader: fakeOciDownloader{registry: registry}}
+		resolver := DepsResolver{
+			Downloader: d,
+			Settings:   settings.GetSettings(),
+			LogWriter:  &bytes.Buffer{},
+			ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+				res = append(res, fmt.Sprintf("%s -> %s", parentPkg.GetPkgName(), dep.Name))
+				return nil
+			}},
+		}
+		err = resolver.Resolve(
+			WithResolveKclMod(kMod),
+			WithEnableCache(true),
+			WithCachePath(t.TempDir()),
+			WithOffline(true),
+			WithSkipMissing(skipMissing),
+		)
+		return d, res, err
+	}
+
+	// The transitive dependencies are resolved in offline mode and the missing one fails fast.
+	d, _, err := resolveOffline("a = { oci = \"oci://example.com/test/a\", tag = \"0.0.1\" }\n", false)
+	assert.True(t, errors.Is(err, downloader.ErrNotFoundAndOffline))
+	assert.Contains(t, err.Error(), "module 'b' version '0.0.2' is not found in the cache")
+	assert.Empty(t, d.online)
+
+	// The latest version is unknown in offline mode.
+	_, _, err = resolveOffline("c = { oci = \"oci://example.com/test/c\" }\n", false)
+	assert.True(t, errors.Is(err, downloader.ErrNotFoundAndOffline))
+	assert.Contains(t, err.Error(), "the latest version of module 'c' is unknown")
+
+	// The missing dependencies are skipped.
+	d, res, err := resolveOffline("a = { oci = \"oci://example.com/test/a\", tag = \"0.0.1\" }\n", true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"pkg -> a"}, res)
+	assert.Empty(t, d.online)
+}
+
+// digestDownloader downloads the packages as the fakeOciDownloader, and checks and records the manifest digests
+// of the tags in 'digests' as the OCI downloader. The packages already downloaded into the local path are not downloaded again.
+type digestDownloader struct {
+	fakeOciDownloader
+	digests map[string]string
+}
+
+func (d *digestDownloader) Download(opts *downloader.DownloadOptions) error {
+	if _, err := os.Stat(filepath.Join(opts.LocalPath, "kcl.mod")); err == nil {
+		return nil
+	}
+	ociSource := opts.Source.Oci
+	digest := d.digests[ociSource.Tag]
+	if ociSource.Digest == "" {
+		ociSource.Digest = digest
+		ociSource.DigestResolved = true
+	} else if ociSource.Digest != digest {
+		return &kpmerrors.DigestMismatch{Ref: ociSource.Tag, Expected: ociSource.Digest, Actual: digest}
+	}
+	return d.fakeOciDownloader.Download(opts)
+}
+
+func TestResolveDigests(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("1", 64)
+	retagged := "sha256:" + strings.Repeat("2", 64)
+
+	registry := t.TempDir()
+	modDir := filepath.Join(registry, "a", "0.0.1")
+	assert.Nil(t, os.MkdirAll(modDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(modDir, "kcl.mod"), []byte("[package]\nname = \"a\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n"), 0644))
+
+	resolve := func(dep, lockedDigest, registryDigest string, jobs int) (string, error) {
+		pkgPath := t.TempDir()
+		assert.Nil(t, os.WriteFile(
+			filepath.Join(pkgPath, "kcl.mod"),
+			[]byte("[package]\nname = \"pkg\"\nedition = \"v0.9.0\"\nversion = \"0.0.1\"\n\n[dependencies]\na = "+dep+"\n"),
+			0644,
+		))
+		kMod, err := pkg.LoadKclPkgWithOpts(
+			pkg.WithPath(pkgPath),
+			pkg.WithSettings(settings.GetSettings()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lockedDigest != "" {
+			kMod.Dependencies.Deps.Set("a", pkg.Dependency{
+				Name:    "a",
+				Version: "0.0.1",
+				Source: downloader.Source{
+					Oci: &downloader.Oci{Reg: "example.com", Repo: "test/a", Tag: "0.0.1", Digest: lockedDigest},
+				},
+			})
+		}
+
+		var resolved string
+		resolver := DepsResolver{
+			Downloader: &digestDownloader{
+				fakeOciDownloader: fakeOciDownloader{registry: registry},
+				digests:           map[string]string{"0.0.1": registryDigest},
+			},
+			Settings:  settings.GetSettings(),
+			LogWriter: &bytes.Buffer{},
+			Jobs:      jobs,
+			ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
+				resolved = dep.Source.Oci.Digest
+				return nil
+			}},
+		}
+		err = resolver.Resolve(
+			WithResolveKclMod(kMod),
+			WithEnableCache(true),
+			WithCachePath(t.TempDir()),
+		)
+		return resolved, err
+	}
+
+	dep := "{ oci = \"oci://example.com/test/a\", tag = \"0.0.1\" }"
+	// The digest is recorded for the dependency, also when it is downloaded before resolving.
+	for _, jobs := range []int{1, 4} {
+		resolved, err := resolve(dep, "", digest, jobs)
+		assert.Nil(t, err)
+		assert.Equal(t, digest, resolved)
+	}
+
+	// The locked digest is kept.
+	resolved, err := resolve(dep, digest, digest, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, digest, resolved)
+
+	// The tag retagged in the registry is refused.
+	for _, jobs := range []int{1, 4} {
+		_, err = resolve(dep, digest, retagged, jobs)
+		assert.True(t, errors.Is(err, kpmerrors.DigestMismatchError))
+	}
+
+	// The digest pinned in kcl.mod takes precedence over the locked one.
+	resolved, err = resolve("{ oci = \"oci://example.com/test/a\", tag = \"0.0.1\", digest = \""+retagged+"\" }", digest, retagged, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, retagged, resolved)
+}
@@ -0,0 +1,7 @@
+[package]
+name = "dep"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+k8s = "^1.29"
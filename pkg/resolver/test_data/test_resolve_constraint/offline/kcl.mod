@@ -0,0 +1,7 @@
+[package]
+name = "offline"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+k8s = "^1.28"
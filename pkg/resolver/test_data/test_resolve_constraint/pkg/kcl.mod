@@ -0,0 +1,8 @@
+[package]
+name = "pkg"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+dep = { path = "../dep" }
+k8s = ">=1.28, <1.31"
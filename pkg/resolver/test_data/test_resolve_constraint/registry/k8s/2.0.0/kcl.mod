@@ -0,0 +1,4 @@
+[package]
+name = "k8s"
+edition = "v0.12.3"
+version = "2.0.0"
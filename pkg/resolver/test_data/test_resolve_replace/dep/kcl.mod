@@ -0,0 +1,10 @@
+[package]
+name = "dep"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+k8s = "1.31.0"
+
+[replace]
+k8s = { path = "../not_exist" }
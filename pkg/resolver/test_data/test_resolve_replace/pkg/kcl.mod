@@ -0,0 +1,11 @@
+[package]
+name = "pkg"
+edition = "v0.12.3"
+version = "0.0.1"
+
+[dependencies]
+dep = { path = "../dep" }
+k8s = "1.29.0"
+
+[replace]
+k8s = { path = "../k8s" }
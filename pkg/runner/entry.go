@@ -207,8 +207,11 @@ func GetSourceKindFrom(source string) EntryKind {
 // FindModRootFrom will find the kcl.mod path from the start path.
 func FindModRootFrom(startPath string) (string, *reporter.KpmEvent) {
 	info, err := os.Stat(startPath)
+	if os.IsNotExist(err) {
+		return "", reporter.NewErrorEvent(reporter.LocalPathNotExist, err, fmt.Sprintf("path '%s' not found", startPath))
+	}
 	if err != nil {
-		return "", reporter.NewErrorEvent(reporter.CompileFailed, err, fmt.Sprintf("failed to access path '%s'", startPath))
+		return "", reporter.NewErrorEvent(reporter.FailedAccessPkgPath, err, fmt.Sprintf("failed to access path '%s'", startPath))
 	}
 	var start string
 	// If the start path is a kcl file, find from the parent dir of the kcl file.
@@ -218,7 +221,7 @@ func FindModRootFrom(startPath string) (string, *reporter.KpmEvent) {
 		// If the start path is a dir, find from the start path.
 		start = startPath
 	} else {
-		return "", reporter.NewErrorEvent(reporter.CompileFailed, err, fmt.Sprintf("invalid file path '%s'", startPath))
+		return "", reporter.NewErrorEvent(reporter.InvalidCmd, fmt.Errorf("invalid file path '%s', expected a kcl file or a directory", startPath))
 	}
 
 	if _, err := os.Stat(filepath.Join(start, constants.KCL_MOD)); err == nil {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/constants"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/reporter"
 )
 
@@ -38,6 +39,19 @@ func TestFindModRootFrom(t *testing.T) {
 	root, err = FindModRootFrom(absPath)
 	assert.Equal(t, err, (*reporter.KpmEvent)(nil))
 	assert.Equal(t, root, filepath.Dir(absPath))
+
+	// The path not found is not a compile error.
+	_, event := FindModRootFrom(filepath.Join(absPath, "not_exist.k"))
+	assert.Equal(t, event.Type(), reporter.LocalPathNotExist)
+	assert.ErrorIs(t, event, kpmerrors.PathNotFound)
+	assert.Equal(t, kpmerrors.ExitNotFound, kpmerrors.ExitCode(event))
+
+	// The path neither a kcl file nor a directory is an invalid argument.
+	absPath, err = filepath.Abs("./testdata/test_find_mod/kcl.mod")
+	assert.Equal(t, err, nil)
+	_, event = FindModRootFrom(absPath)
+	assert.ErrorIs(t, event, kpmerrors.InvalidArguments)
+	assert.Contains(t, event.Error(), "invalid file path")
 }
 
 func TestGetSourceKindFrom(t *testing.T) {
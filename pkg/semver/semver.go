@@ -2,6 +2,7 @@ package semver
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"kcl-lang.io/kpm/pkg/constants"
@@ -84,3 +85,141 @@ func LeastOldCompatibleVersion(versions []string, baseVersion string) (string, e
 	}
 	return OldestVersion(compatibleVersions)
 }
+
+// IsConstraint returns true if the version is a version constraint rather than an exact version,
+// e.g. ">=1.28, <1.31", "^1.28" or "~1.28".
+func IsConstraint(v string) bool {
+	return strings.ContainsAny(v, "<>=!~^,")
+}
+
+// NewConstraint parses the version constraint.
+// Besides the operators supported by 'github.com/hashicorp/go-version',
+// the caret '^1.28' (>=1.28, <2.0.0) and the tilde '~1.28' (>=1.28, <1.29) are supported.
+// The error of an invalid constraint is an 'errors.InvalidVersionConstraint'.
+func NewConstraint(constraint string) (version.Constraints, error) {
+	var parts []string
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, "^"):
+			lower, upper, err := caretBounds(strings.TrimSpace(strings.TrimPrefix(part, "^")))
+			if err != nil {
+				return nil, errors.Wrap(errors.InvalidVersionConstraint, err)
+			}
+			parts = append(parts, ">="+lower, "<"+upper)
+		case strings.HasPrefix(part, "~") && !strings.HasPrefix(part, "~>"):
+			lower, upper, err := tildeBounds(strings.TrimSpace(strings.TrimPrefix(part, "~")))
+			if err != nil {
+				return nil, errors.Wrap(errors.InvalidVersionConstraint, err)
+			}
+			parts = append(parts, ">="+lower, "<"+upper)
+		default:
+			parts = append(parts, part)
+		}
+	}
+
+	constraints, err := version.NewConstraint(strings.Join(parts, ", "))
+	if err != nil {
+		return nil, errors.Wrap(errors.InvalidVersionConstraint, fmt.Errorf("invalid version constraint '%s': %w", constraint, err))
+	}
+	return constraints, nil
+}
+
+// caretBounds returns the bounds of the caret constraint,
+// which allows the changes that do not modify the left-most non-zero segment.
+func caretBounds(v string) (string, string, error) {
+	ver, n, err := parseBound(v)
+	if err != nil {
+		return "", "", err
+	}
+	segments := ver.Segments()
+	switch {
+	case segments[0] > 0 || n == 1:
+		return v, fmt.Sprintf("%d.0.0", segments[0]+1), nil
+	case segments[1] > 0 || n == 2:
+		return v, fmt.Sprintf("0.%d.0", segments[1]+1), nil
+	default:
+		return v, fmt.Sprintf("0.0.%d", segments[2]+1), nil
+	}
+}
+
+// tildeBounds returns the bounds of the tilde constraint,
+// which allows the patch-level changes if the minor version is specified and the minor-level changes if not.
+func tildeBounds(v string) (string, string, error) {
+	ver, n, err := parseBound(v)
+	if err != nil {
+		return "", "", err
+	}
+	segments := ver.Segments()
+	if n == 1 {
+		return v, fmt.Sprintf("%d.0.0", segments[0]+1), nil
+	}
+	return v, fmt.Sprintf("%d.%d.0", segments[0], segments[1]+1), nil
+}
+
+// parseBound parses the version in the caret or tilde constraint
+// and returns the number of the segments specified in it.
+func parseBound(v string) (*version.Version, int, error) {
+	ver, err := version.NewVersion(v)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid version '%s' in constraint: %w", v, err)
+	}
+	core := strings.SplitN(strings.SplitN(strings.TrimPrefix(v, "v"), "-", 2)[0], "+", 2)[0]
+	return ver, len(strings.Split(core, ".")), nil
+}
+
+// Satisfies returns true if the version satisfies all the constraints.
+func Satisfies(v string, constraints ...string) (bool, error) {
+	ver, err := version.NewVersion(v)
+	if err != nil {
+		return false, reporter.NewErrorEvent(reporter.FailedParseVersion, err, fmt.Sprintf("failed to parse version %s", v))
+	}
+	for _, constraint := range constraints {
+		c, err := NewConstraint(constraint)
+		if err != nil {
+			return false, err
+		}
+		if !c.Check(ver) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// LatestSatisfiedVersion returns the latest version satisfying all the constraints.
+// The versions that fail to parse are skipped.
+// If no version satisfies the constraints, 'errors.NoSatisfiedVersion' is returned.
+func LatestSatisfiedVersion(versions []string, constraints ...string) (string, error) {
+	var parsed []version.Constraints
+	for _, constraint := range constraints {
+		c, err := NewConstraint(constraint)
+		if err != nil {
+			return "", err
+		}
+		parsed = append(parsed, c)
+	}
+
+	var latest *version.Version
+	for _, v := range versions {
+		ver, err := version.NewVersion(v)
+		if err != nil {
+			continue // skip versions that fail to parse
+		}
+		satisfied := true
+		for _, c := range parsed {
+			if !c.Check(ver) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied && (latest == nil || ver.GreaterThan(latest)) {
+			latest = ver
+		}
+	}
+
+	if latest == nil {
+		return "", errors.NoSatisfiedVersion
+	}
+
+	return latest.Original(), nil
+}
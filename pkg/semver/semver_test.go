@@ -68,3 +68,65 @@ func TestFilterCompatibleVersions(t *testing.T) {
 		assert.Equal(t, v, expCompatible[i])
 	}
 }
+
+func TestIsConstraint(t *testing.T) {
+	assert.Equal(t, IsConstraint("1.28.0"), false)
+	assert.Equal(t, IsConstraint("v1.28"), false)
+	assert.Equal(t, IsConstraint(">=1.28, <1.31"), true)
+	assert.Equal(t, IsConstraint("^1.28"), true)
+	assert.Equal(t, IsConstraint("~1.28"), true)
+	assert.Equal(t, IsConstraint("!=1.29"), true)
+}
+
+func TestSatisfies(t *testing.T) {
+	testCases := []struct {
+		version    string
+		constraint string
+		expected   bool
+	}{
+		{"1.28.0", ">=1.28, <1.31", true},
+		{"1.31.0", ">=1.28, <1.31", false},
+		{"1.99.0", "^1.28", true},
+		{"2.0.0", "^1.28", false},
+		{"1.27.0", "^1.28", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.9.0", "^0", true},
+		{"1.28.5", "~1.28", true},
+		{"1.29.0", "~1.28", false},
+		{"1.9.0", "~1", true},
+		{"2.0.0", "~1", false},
+		{"1.28.9", "~1.28.3", true},
+		{"1.29.0", "~>1.28.3", false},
+	}
+
+	for _, tc := range testCases {
+		satisfied, err := Satisfies(tc.version, tc.constraint)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, satisfied, tc.expected, "%s %s", tc.version, tc.constraint)
+	}
+
+	_, err := Satisfies("1.28.0", "^invalid")
+	assert.Equal(t, err.Error(), "invalid version 'invalid' in constraint: malformed version: invalid")
+	assert.ErrorIs(t, err, errors.InvalidVersionConstraint)
+	_, err = Satisfies("1.28.0", ">=1.28, <invalid")
+	assert.ErrorIs(t, err, errors.InvalidVersionConstraint)
+}
+
+func TestLatestSatisfiedVersion(t *testing.T) {
+	versions := []string{"1.27.0", "1.28.0", "1.29.1", "1.30.0", "1.31.0", "2.0.0", "1.30.1-beta", "latest"}
+
+	latest, err := LatestSatisfiedVersion(versions, ">=1.28, <1.31")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, latest, "1.30.0")
+
+	latest, err = LatestSatisfiedVersion(versions, "^1.28", "<1.30")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, latest, "1.29.1")
+
+	latest, err = LatestSatisfiedVersion(versions, "~1.28", "^1.29")
+	assert.Equal(t, err, errors.NoSatisfiedVersion)
+	assert.Equal(t, latest, "")
+}
@@ -0,0 +1,77 @@
+package settings
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MirrorRule redirects the remote sources matching 'Source' to 'Mirror', e.g.
+//
+//	{"Source": "ghcr.io/kcl-lang", "Mirror": "harbor.internal/kcl-lang"}
+//	{"Source": "https://github.com/*", "Mirror": "https://gitea.internal/*"}
+//
+// The OCI sources are matched by '<registry>/<repo>' and the git sources are matched by the git url.
+type MirrorRule struct {
+	// Source is the prefix or the glob pattern of the sources to redirect.
+	// The prefix only matches on the path boundary, e.g. 'ghcr.io/kcl-lang' matches 'ghcr.io/kcl-lang/k8s'
+	// but not 'ghcr.io/kcl-lang-extra/k8s'.
+	// In the glob pattern, '*' matches any characters including '/'.
+	Source string
+	// Mirror is the replacement of the matched source.
+	// For the prefix, the matched prefix is replaced by 'Mirror'.
+	// For the glob pattern, each '*' in 'Mirror' is replaced by the characters matched by the '*' at the same position in 'Source'.
+	Mirror string
+}
+
+// isGlob returns true if the source of the rule is a glob pattern.
+func (r MirrorRule) isGlob() bool {
+	return strings.Contains(r.Source, "*")
+}
+
+// Apply returns the mirror of the ref and true if the rule matches the ref,
+// otherwise it returns the ref itself and false.
+func (r MirrorRule) Apply(ref string) (string, bool) {
+	if r.Source == "" {
+		return ref, false
+	}
+
+	if !r.isGlob() {
+		prefix := strings.TrimSuffix(r.Source, "/")
+		if ref != prefix && !strings.HasPrefix(ref, prefix+"/") {
+			return ref, false
+		}
+		return strings.TrimSuffix(r.Mirror, "/") + strings.TrimPrefix(ref, prefix), true
+	}
+
+	parts := strings.Split(r.Source, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	pattern := regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$")
+	matches := pattern.FindStringSubmatch(ref)
+	if matches == nil {
+		return ref, false
+	}
+
+	var mirror strings.Builder
+	captures := matches[1:]
+	for i, part := range strings.Split(r.Mirror, "*") {
+		if i > 0 && i-1 < len(captures) {
+			mirror.WriteString(captures[i-1])
+		}
+		mirror.WriteString(part)
+	}
+	return mirror.String(), true
+}
+
+// Mirror returns the mirror of the remote source ref by the first matching mirror rule in 'kpm.json',
+// or the ref itself if no rule matches.
+// The ref is '<registry>/<repo>' for the OCI sources and the git url for the git sources.
+func (settings *Settings) Mirror(ref string) string {
+	for _, rule := range settings.Conf.Mirrors {
+		if mirror, ok := rule.Apply(ref); ok {
+			return mirror
+		}
+	}
+	return ref
+}
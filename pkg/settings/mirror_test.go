@@ -0,0 +1,41 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	settings := Settings{
+		Conf: KpmConf{
+			Mirrors: []MirrorRule{
+				{Source: "ghcr.io/kcl-lang/k8s", Mirror: "harbor.internal/k8s"},
+				{Source: "ghcr.io/kcl-lang/", Mirror: "harbor.internal/kcl-lang"},
+				{Source: "https://github.com/*/*.git", Mirror: "https://gitea.internal/*/*.git"},
+				{Source: "https://github.com/*", Mirror: "https://gitea.internal/*"},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		ref    string
+		mirror string
+	}{
+		// The first matching rule is applied.
+		{"ghcr.io/kcl-lang/k8s", "harbor.internal/k8s"},
+		{"ghcr.io/kcl-lang/helloworld", "harbor.internal/kcl-lang/helloworld"},
+		// The prefix only matches on the path boundary.
+		{"ghcr.io/kcl-lang/k8s-extra", "harbor.internal/kcl-lang/k8s-extra"},
+		{"ghcr.io/kcl-lang-extra/helloworld", "ghcr.io/kcl-lang-extra/helloworld"},
+		{"https://github.com/kcl-lang/flask-demo-kcl-manifests.git", "https://gitea.internal/kcl-lang/flask-demo-kcl-manifests.git"},
+		{"https://github.com/kcl-lang/konfig", "https://gitea.internal/kcl-lang/konfig"},
+		{"https://gitlab.com/kcl-lang/konfig", "https://gitlab.com/kcl-lang/konfig"},
+	} {
+		assert.Equal(t, tc.mirror, settings.Mirror(tc.ref), tc.ref)
+	}
+
+	// No mirror rules, the ref is not changed.
+	settings = Settings{Conf: DefaultKpmConf()}
+	assert.Equal(t, "ghcr.io/kcl-lang/k8s", settings.Mirror("ghcr.io/kcl-lang/k8s"))
+}
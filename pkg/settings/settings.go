@@ -32,6 +32,12 @@ type KpmConf struct {
 	DefaultOciRepo      string
 	DefaultOciPlainHttp *bool `json:",omitempty"`
 	ReloadCredsPerUse   *bool `json:",omitempty"`
+	// Mirrors is the ordered rules redirecting the remote sources to the mirrors,
+	// the first matching rule is applied when downloading and the canonical source is still locked in kcl.mod.lock.
+	Mirrors []MirrorRule `json:",omitempty"`
+	// Trust is the ordered rules of the public keys trusted to sign the OCI packages,
+	// the first matching rule is enforced when updating, pulling and running the packages.
+	Trust []TrustRule `json:",omitempty"`
 }
 
 const ON = "on"
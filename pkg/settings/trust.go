@@ -0,0 +1,45 @@
+package settings
+
+import (
+	"path/filepath"
+)
+
+// TrustRule trusts the public keys to sign the OCI packages matching 'Source', e.g.
+//
+//	{"Source": "ghcr.io/kcl-lang", "Keys": ["keys/kcl-lang.pub"]}
+//
+// The OCI packages matching a rule are refused unless they are signed by one of its keys,
+// and the OCI packages matching no rule are not verified.
+type TrustRule struct {
+	// Source is the prefix or the glob pattern of '<registry>/<repo>' of the OCI packages,
+	// it is matched in the same way as the 'Source' of the mirror rules.
+	Source string
+	// Keys are the paths of the PEM encoded ed25519 public keys,
+	// the relative paths are relative to the directory of 'kpm.json'.
+	Keys []string
+}
+
+// Match returns true if the rule matches the OCI ref '<registry>/<repo>'.
+func (r TrustRule) Match(ref string) bool {
+	_, ok := MirrorRule{Source: r.Source}.Apply(ref)
+	return ok
+}
+
+// TrustedKeys returns the paths of the public keys trusted for the OCI ref '<registry>/<repo>'
+// by the first matching trust rule in 'kpm.json', it returns false if no rule matches.
+func (settings *Settings) TrustedKeys(ref string) ([]string, bool) {
+	for _, rule := range settings.Conf.Trust {
+		if !rule.Match(ref) {
+			continue
+		}
+		keys := make([]string, 0, len(rule.Keys))
+		for _, key := range rule.Keys {
+			if !filepath.IsAbs(key) && settings.KpmConfFile != "" {
+				key = filepath.Join(filepath.Dir(settings.KpmConfFile), key)
+			}
+			keys = append(keys, key)
+		}
+		return keys, true
+	}
+	return nil, false
+}
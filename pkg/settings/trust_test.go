@@ -0,0 +1,48 @@
+package settings
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedKeys(t *testing.T) {
+	confDir := t.TempDir()
+	absKey := filepath.Join(t.TempDir(), "k8s.pub")
+	settings := Settings{
+		KpmConfFile: filepath.Join(confDir, "kpm.json"),
+		Conf: KpmConf{
+			Trust: []TrustRule{
+				{Source: "ghcr.io/kcl-lang/k8s", Keys: []string{absKey}},
+				{Source: "ghcr.io/kcl-lang", Keys: []string{"keys/kcl-lang.pub", "keys/backup.pub"}},
+				{Source: "localhost:*/signed/*", Keys: []string{}},
+			},
+		},
+	}
+
+	// The first matching rule is applied.
+	keys, ok := settings.TrustedKeys("ghcr.io/kcl-lang/k8s")
+	assert.True(t, ok)
+	assert.Equal(t, []string{absKey}, keys)
+
+	// The relative paths are relative to the directory of kpm.json.
+	keys, ok = settings.TrustedKeys("ghcr.io/kcl-lang/helloworld")
+	assert.True(t, ok)
+	assert.Equal(t, []string{filepath.Join(confDir, "keys", "kcl-lang.pub"), filepath.Join(confDir, "keys", "backup.pub")}, keys)
+
+	keys, ok = settings.TrustedKeys("localhost:5002/signed/helloworld")
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+
+	// The prefix only matches on the path boundary.
+	_, ok = settings.TrustedKeys("ghcr.io/kcl-lang-extra/helloworld")
+	assert.False(t, ok)
+	_, ok = settings.TrustedKeys("localhost:5002/helloworld")
+	assert.False(t, ok)
+
+	// No trust rules, no packages are verified.
+	settings = Settings{Conf: DefaultKpmConf()}
+	_, ok = settings.TrustedKeys("ghcr.io/kcl-lang/k8s")
+	assert.False(t, ok)
+}
@@ -0,0 +1,318 @@
+// Package signature implements the signatures of the kcl packages pushed to the OCI registries.
+//
+// A package is signed by an ed25519 private key over the digest of its manifest. The signature is
+// pushed as an OCI artifact referring to the manifest by the 'subject' field, so the manifest and the
+// tag of the package are not changed and the signatures are listed by the referrers of the manifest.
+//
+// The keys are PEM encoded, the private keys in PKCS #8 and the public keys in PKIX, e.g. generated by
+//
+//	openssl genpkey -algorithm ed25519 -out kpm.key
+//	openssl pkey -in kpm.key -pubout -out kpm.pub
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// ARTIFACT_TYPE is the artifact type of the signature manifests and the media type of the signature payloads.
+const ARTIFACT_TYPE = "application/vnd.kcl.package.signature.v1+json"
+
+const (
+	privateKeyPemType = "PRIVATE KEY"
+	publicKeyPemType  = "PUBLIC KEY"
+)
+
+// Payload is the content of a signature.
+type Payload struct {
+	// Subject is the digest of the signed manifest.
+	Subject string `json:"subject"`
+	// KeyId is the id of the public key to verify the signature.
+	KeyId string `json:"keyId"`
+	// Signature is the ed25519 signature of the subject.
+	Signature []byte `json:"signature"`
+}
+
+// GenerateKey generates an ed25519 key pair and returns the PEM encoded private key and public key.
+func GenerateKey() ([]byte, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyPemType, Bytes: privateDer}),
+		pem.EncodeToMemory(&pem.Block{Type: publicKeyPemType, Bytes: publicDer}), nil
+}
+
+// LoadPrivateKey loads the PEM encoded ed25519 private key from the file.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := loadPem(path, privateKeyPemType)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key '%s': %w", path, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key '%s': only ed25519 keys are supported", path)
+	}
+	return privateKey, nil
+}
+
+// LoadPublicKey loads the PEM encoded ed25519 public key from the file.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := loadPem(path, publicKeyPemType)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key '%s': %w", path, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key '%s': only ed25519 keys are supported", path)
+	}
+	return publicKey, nil
+}
+
+// loadPem loads the DER bytes of the PEM block of the type from the file.
+func loadPem(path, pemType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, fmt.Errorf("invalid key '%s': expected a PEM encoded '%s'", path, pemType)
+	}
+	return block.Bytes, nil
+}
+
+// KeyId returns the id of the public key, i.e. the sha256 of its PKIX encoding, e.g. 'sha256:5a2c...'.
+func KeyId(key ed25519.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		// The ed25519 public keys are always marshalled.
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Sign signs the manifest 'subject' in the target by the key,
+// and pushes the signature into the target as a referrer of the manifest.
+// It returns the descriptor of the signature manifest.
+func Sign(ctx context.Context, target content.Pusher, subject ocispec.Descriptor, key ed25519.PrivateKey) (ocispec.Descriptor, error) {
+	payload, err := json.Marshal(Payload{
+		Subject:   subject.Digest.String(),
+		KeyId:     KeyId(key.Public().(ed25519.PublicKey)),
+		Signature: ed25519.Sign(key, []byte(subject.Digest.String())),
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// The ed25519 signatures are deterministic, signing the same manifest by the same key pushes the same payload.
+	payloadDesc := content.NewDescriptorFromBytes(ARTIFACT_TYPE, payload)
+	err = target.Push(ctx, payloadDesc, bytes.NewReader(payload))
+	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+
+	// The creation time of the signature is $SOURCE_DATE_EPOCH or the unix epoch by default as the package.
+	created, err := utils.SourceDateEpoch()
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, ARTIFACT_TYPE, oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []ocispec.Descriptor{payloadDesc},
+		ManifestAnnotations: map[string]string{
+			ocispec.AnnotationCreated: created.Format(time.RFC3339),
+		},
+	})
+}
+
+// Verify verifies the signatures of the manifest 'subject' in the target by the keys,
+// and returns the signature verified.
+// It returns a 'SignatureNotVerified' error if no signature is verified by the keys.
+func Verify(ctx context.Context, target content.ReadOnlyGraphStorage, ref string, subject ocispec.Descriptor, keys []ed25519.PublicKey) (Payload, error) {
+	referrers, err := registry.Referrers(ctx, target, subject, ARTIFACT_TYPE)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	var payloads []Payload
+	for _, referrer := range referrers {
+		referrerPayloads, err := fetchPayloads(ctx, target, referrer)
+		if err != nil {
+			return Payload{}, err
+		}
+		payloads = append(payloads, referrerPayloads...)
+	}
+	return verifyPayloads(ref, subject.Digest.String(), payloads, keys)
+}
+
+// verifyPayloads returns the first one of the payloads signing the manifest digest 'subject' by the keys.
+// It returns a 'SignatureNotVerified' error if no payload is verified by the keys.
+func verifyPayloads(ref, subject string, payloads []Payload, keys []ed25519.PublicKey) (Payload, error) {
+	trusted := make(map[string]ed25519.PublicKey, len(keys))
+	keyIds := make([]string, 0, len(keys))
+	for _, key := range keys {
+		keyId := KeyId(key)
+		trusted[keyId] = key
+		keyIds = append(keyIds, keyId)
+	}
+
+	for _, payload := range payloads {
+		key, ok := trusted[payload.KeyId]
+		if ok && payload.Subject == subject && ed25519.Verify(key, []byte(subject), payload.Signature) {
+			return payload, nil
+		}
+	}
+	return Payload{}, &kpmerrors.SignatureNotVerified{Ref: ref, Keys: keyIds}
+}
+
+// STORE_DIR is the directory of the signatures verified under the kpm home path,
+// the packages in the cache are verified by them in offline mode.
+const STORE_DIR = ".signatures"
+
+// recordPath returns the path of the signatures of the manifest digest recorded in the directory,
+// i.e. '<dir>/<algorithm>/<hex>.json'.
+func recordPath(dir, subject string) (string, error) {
+	algorithm, hex, ok := strings.Cut(subject, ":")
+	if !ok || algorithm == "" || hex == "" || strings.ContainsAny(subject, `/\.`) {
+		return "", fmt.Errorf("invalid manifest digest '%s'", subject)
+	}
+	return filepath.Join(dir, algorithm, hex+".json"), nil
+}
+
+// Record records the signature verified in the directory, to verify the manifest signed by it in offline mode.
+func Record(dir string, payload Payload) error {
+	path, err := recordPath(dir, payload.Subject)
+	if err != nil {
+		return err
+	}
+	payloads, err := loadRecords(path)
+	if err != nil {
+		return err
+	}
+	for _, recorded := range payloads {
+		if recorded.KeyId == payload.KeyId && bytes.Equal(recorded.Signature, payload.Signature) {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(append(payloads, payload))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// VerifyRecorded verifies the manifest digest 'subject' by the signatures recorded in the directory,
+// and returns the signature verified.
+// It returns a 'SignatureNotVerified' error if no signature recorded is verified by the keys.
+func VerifyRecorded(dir, ref, subject string, keys []ed25519.PublicKey) (Payload, error) {
+	path, err := recordPath(dir, subject)
+	if err != nil {
+		return Payload{}, err
+	}
+	payloads, err := loadRecords(path)
+	if err != nil {
+		return Payload{}, err
+	}
+	return verifyPayloads(ref, subject, payloads, keys)
+}
+
+// loadRecords loads the signatures recorded in the file, it returns nil if the file does not exist.
+func loadRecords(path string) ([]Payload, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var payloads []Payload
+	if err := json.Unmarshal(data, &payloads); err != nil {
+		return nil, fmt.Errorf("invalid signatures recorded in '%s': %w", path, err)
+	}
+	return payloads, nil
+}
+
+// fetchPayloads fetches the signature payloads in the signature manifest,
+// the payloads not in the json format are ignored.
+func fetchPayloads(ctx context.Context, target content.Fetcher, desc ocispec.Descriptor) ([]Payload, error) {
+	manifestJson, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJson, &manifest); err != nil {
+		return nil, err
+	}
+
+	var payloads []Payload
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != ARTIFACT_TYPE {
+			continue
+		}
+		payloadJson, err := content.FetchAll(ctx, target, layer)
+		if err != nil {
+			return nil, err
+		}
+		var payload Payload
+		if err := json.Unmarshal(payloadJson, &payload); err == nil {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads, nil
+}
@@ -0,0 +1,146 @@
+package signature
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+)
+
+// newTestKey generates a key pair into the temp dir and loads it.
+func newTestKey(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	privatePem, publicPem, err := GenerateKey()
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kpm.key"), privatePem, 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kpm.pub"), publicPem, 0644))
+
+	privateKey, err := LoadPrivateKey(filepath.Join(dir, "kpm.key"))
+	assert.NoError(t, err)
+	publicKey, err := LoadPublicKey(filepath.Join(dir, "kpm.pub"))
+	assert.NoError(t, err)
+	return privateKey, publicKey
+}
+
+// newTestManifest packs a package manifest into the store.
+func newTestManifest(t *testing.T, store *memory.Store, version string) ocispec.Descriptor {
+	ctx := context.Background()
+	layer, err := oras.PushBytes(ctx, store, "application/vnd.oci.image.layer.v1.tar+gzip", []byte("helloworld "+version))
+	assert.NoError(t, err)
+	manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.oci.image.layer.v1.tar+gzip", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+	})
+	assert.NoError(t, err)
+	return manifest
+}
+
+func TestLoadKeys(t *testing.T) {
+	privateKey, publicKey := newTestKey(t)
+	assert.Equal(t, publicKey, privateKey.Public())
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", KeyId(publicKey))
+
+	// The public key is not a private key and vice versa.
+	privatePem, publicPem, err := GenerateKey()
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kpm.key"), privatePem, 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kpm.pub"), publicPem, 0644))
+	_, err = LoadPrivateKey(filepath.Join(dir, "kpm.pub"))
+	assert.Error(t, err)
+	_, err = LoadPublicKey(filepath.Join(dir, "kpm.key"))
+	assert.Error(t, err)
+	_, err = LoadPublicKey(filepath.Join(dir, "not_exist.pub"))
+	assert.Error(t, err)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	privateKey, publicKey := newTestKey(t)
+	_, otherKey := newTestKey(t)
+
+	subject := newTestManifest(t, store, "0.1.0")
+	unsigned := newTestManifest(t, store, "0.2.0")
+
+	// Not signed yet.
+	_, err := Verify(ctx, store, "helloworld:0.1.0", subject, []ed25519.PublicKey{publicKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	sigDesc, err := Sign(ctx, store, subject, privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, ARTIFACT_TYPE, sigDesc.ArtifactType)
+	// Signing again by the same key pushes the same signature.
+	again, err := Sign(ctx, store, subject, privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, sigDesc.Digest, again.Digest)
+
+	payload, err := Verify(ctx, store, "helloworld:0.1.0", subject, []ed25519.PublicKey{otherKey, publicKey})
+	assert.NoError(t, err)
+	assert.Equal(t, KeyId(publicKey), payload.KeyId)
+	assert.Equal(t, subject.Digest.String(), payload.Subject)
+
+	// The signature is not verified by the untrusted keys.
+	_, err = Verify(ctx, store, "helloworld:0.1.0", subject, []ed25519.PublicKey{otherKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+	assert.Contains(t, err.Error(), KeyId(otherKey))
+	_, err = Verify(ctx, store, "helloworld:0.1.0", subject, nil)
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	// The signature of a manifest does not sign the others.
+	_, err = Verify(ctx, store, "helloworld:0.2.0", unsigned, []ed25519.PublicKey{publicKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+}
+
+func TestRecordAndVerifyRecorded(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	privateKey, publicKey := newTestKey(t)
+	_, otherKey := newTestKey(t)
+	subject := newTestManifest(t, store, "0.1.0")
+	unsigned := newTestManifest(t, store, "0.2.0")
+	dir := t.TempDir()
+
+	// Nothing is recorded yet.
+	_, err := VerifyRecorded(dir, "helloworld:0.1.0", subject.Digest.String(), []ed25519.PublicKey{publicKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	_, err = Sign(ctx, store, subject, privateKey)
+	assert.NoError(t, err)
+	payload, err := Verify(ctx, store, "helloworld:0.1.0", subject, []ed25519.PublicKey{publicKey})
+	assert.NoError(t, err)
+	assert.NoError(t, Record(dir, payload))
+	// Recording the same signature again does not duplicate it.
+	assert.NoError(t, Record(dir, payload))
+	payloads, err := loadRecords(filepath.Join(dir, "sha256", subject.Digest.Encoded()+".json"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(payloads))
+
+	recorded, err := VerifyRecorded(dir, "helloworld:0.1.0", subject.Digest.String(), []ed25519.PublicKey{otherKey, publicKey})
+	assert.NoError(t, err)
+	assert.Equal(t, payload, recorded)
+
+	// The signatures recorded are verified by the trusted keys and for the signed manifest only.
+	_, err = VerifyRecorded(dir, "helloworld:0.1.0", subject.Digest.String(), []ed25519.PublicKey{otherKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+	_, err = VerifyRecorded(dir, "helloworld:0.2.0", unsigned.Digest.String(), []ed25519.PublicKey{publicKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	// A forged signature recorded is not verified.
+	forged := payload
+	forged.Subject = unsigned.Digest.String()
+	assert.NoError(t, Record(dir, forged))
+	_, err = VerifyRecorded(dir, "helloworld:0.2.0", unsigned.Digest.String(), []ed25519.PublicKey{publicKey})
+	assert.True(t, errors.Is(err, kpmerrors.SignatureVerificationError))
+
+	_, err = VerifyRecorded(dir, "helloworld", "sha256:../../kpm", []ed25519.PublicKey{publicKey})
+	assert.Error(t, err)
+}
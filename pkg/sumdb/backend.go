@@ -0,0 +1,276 @@
+package sumdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+)
+
+// FileBackend stores the records in a local append-only file, one record per line in the format:
+//
+//	<module> <version> <sum>
+//
+// The first record of a module version is the authoritative one.
+// The file is indexed once on the first access, and the records are looked up in the index.
+type FileBackend struct {
+	path string
+	mu   sync.Mutex
+	// records is the index of the first records of the module versions in the file.
+	records map[recordKey]string
+}
+
+// recordKey is the key of a record in the index.
+type recordKey struct {
+	module  string
+	version string
+}
+
+// NewFileBackend returns the backend storing the records in the file, the file is created on the first record.
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+// Path returns the path of the file.
+func (b *FileBackend) Path() string {
+	return b.path
+}
+
+// Lookup returns the checksum recorded for the module version.
+func (b *FileBackend) Lookup(module, version string) (string, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.index(); err != nil {
+		return "", false, err
+	}
+	sum, ok := b.records[recordKey{module, version}]
+	return sum, ok, nil
+}
+
+// Record appends the record for the module version if it is not recorded.
+func (b *FileBackend) Record(module, version, sum string) error {
+	if err := validateRecord(module, version, sum); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.index(); err != nil {
+		return err
+	}
+	if recorded, ok := b.records[recordKey{module, version}]; ok {
+		if recorded != sum {
+			return &kpmerrors.ChecksumMismatch{Name: module + "@" + version, Expected: recorded, Actual: sum}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(b.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = fmt.Fprintf(f, "%s %s %s\n", module, version, sum); err != nil {
+		return err
+	}
+	b.records[recordKey{module, version}] = sum
+	return nil
+}
+
+// index scans the file into the index of the first records of the module versions if it is not indexed.
+func (b *FileBackend) index() error {
+	if b.records != nil {
+		return nil
+	}
+
+	records := make(map[recordKey]string)
+	f, err := os.Open(b.path)
+	if os.IsNotExist(err) {
+		b.records = records
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// The malformed lines, e.g. a line truncated by a crash, are ignored.
+		if len(fields) != 3 {
+			continue
+		}
+		key := recordKey{fields[0], fields[1]}
+		if _, ok := records[key]; !ok {
+			records[key] = fields[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	b.records = records
+	return nil
+}
+
+// validateRecord checks the record is stored in a line of the file and in the url of the remote checksum database.
+func validateRecord(module, version, sum string) error {
+	for _, field := range []string{module, version, sum} {
+		if field == "" || strings.ContainsAny(field, " \t\r\n") {
+			return fmt.Errorf("invalid checksum database record '%s %s %s'", module, version, sum)
+		}
+	}
+	if strings.Contains(module, "@") {
+		return fmt.Errorf("invalid checksum database record '%s %s %s'", module, version, sum)
+	}
+	return nil
+}
+
+// HTTPBackend stores the records in a remote checksum database served by 'Handler'.
+type HTTPBackend struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPBackend returns the backend of the remote checksum database at the url.
+func NewHTTPBackend(url string) *HTTPBackend {
+	return &HTTPBackend{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// lookupUrl returns the url of the record of the module version, i.e. '<url>/lookup/<module>@<version>'.
+func (b *HTTPBackend) lookupUrl(module, version string) string {
+	segments := strings.Split(module, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return b.url + "/lookup/" + strings.Join(segments, "/") + "@" + url.PathEscape(version)
+}
+
+// Lookup fetches the checksum recorded for the module version from the remote checksum database.
+func (b *HTTPBackend) Lookup(module, version string) (string, bool, error) {
+	resp, err := b.client.Get(b.lookupUrl(module, version))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to access the checksum database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(string(body)), true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("failed to look up '%s@%s' in the checksum database: %s", module, version, resp.Status)
+	}
+}
+
+// Record records the checksum for the module version in the remote checksum database.
+func (b *HTTPBackend) Record(module, version, sum string) error {
+	if err := validateRecord(module, version, sum); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, b.lookupUrl(module, version), strings.NewReader(sum))
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to access the checksum database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusConflict:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return &kpmerrors.ChecksumMismatch{Name: module + "@" + version, Expected: strings.TrimSpace(string(body)), Actual: sum}
+	default:
+		return fmt.Errorf("failed to record '%s@%s' in the checksum database: %s", module, version, resp.Status)
+	}
+}
+
+// Handler serves the checksum database of the backend over http:
+//
+//	GET /lookup/<module>@<version>   200 with the checksum, or 404 if the module version is not recorded.
+//	PUT /lookup/<module>@<version>   records the checksum in the body, 409 with the recorded checksum if it differs.
+//
+// The module and the version are path escaped, and the module contains no '@'.
+func Handler(backend Backend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record, ok := strings.CutPrefix(r.URL.EscapedPath(), "/lookup/")
+		at := strings.Index(record, "@")
+		if !ok || at <= 0 {
+			http.NotFound(w, r)
+			return
+		}
+		module, err := url.PathUnescape(record[:at])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		version, err := url.PathUnescape(record[at+1:])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			sum, ok, err := backend.Lookup(module, version)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintln(w, sum)
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			err = backend.Record(module, version, strings.TrimSpace(string(body)))
+			var mismatch *kpmerrors.ChecksumMismatch
+			switch {
+			case err == nil:
+				w.WriteHeader(http.StatusOK)
+			case errors.As(err, &mismatch):
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprintln(w, mismatch.Expected)
+			default:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
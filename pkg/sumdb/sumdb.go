@@ -0,0 +1,183 @@
+// Package sumdb implements the checksum database of the kcl modules.
+//
+// The checksum database records the checksum of each module version, i.e. 'module@version -> sum',
+// the first time it is used and verifies the later uses of the same module version against the record,
+// so a module version changed in its source, e.g. a git tag moved or an OCI package re-pushed, is detected
+// for the git, OCI and the other remote modules alike. The records are append-only and never updated.
+//
+// The modules are identified by their sources, e.g.
+//
+//	github.com/kcl-lang/konfig v0.4.0             the git module by the git tag
+//	github.com/kcl-lang/konfig 9b5b8a4f           the git module by the commit
+//	ghcr.io/kcl-lang/k8s sha256:3f1b...           the OCI module by the digest of the manifest
+//	github.com/kcl-lang/modules#helloworld main   the module 'helloworld' in the git repository
+//
+// The database is backed by a local file by default, or by a remote server speaking the protocol of 'Handler'.
+package sumdb
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// DEFAULT_DB_FILE is the file of the local checksum database under the kpm home path.
+const DEFAULT_DB_FILE = ".kpm/sumdb/kcl.sum"
+
+// OFF disables the checksum database by '$KPM_SUMDB=off'.
+const OFF = "off"
+
+// Backend stores the records of the checksum database.
+type Backend interface {
+	// Lookup returns the checksum recorded for the module version, it returns false if the module version is not recorded.
+	Lookup(module, version string) (string, bool, error)
+	// Record records the checksum for the module version if it is not recorded,
+	// it returns a 'ChecksumMismatch' error if another checksum is recorded.
+	Record(module, version, sum string) error
+}
+
+// DB is the checksum database.
+type DB struct {
+	backend Backend
+}
+
+// New returns the checksum database backed by the backend.
+func New(backend Backend) *DB {
+	return &DB{backend: backend}
+}
+
+// Open opens the checksum database by the spec in '$KPM_SUMDB':
+//
+//   - empty for the local file '<homePath>/.kpm/sumdb/kcl.sum'.
+//   - 'off' to disable the checksum database, it returns nil.
+//   - the 'http://' or 'https://' url of the remote checksum database.
+//   - the path of the local file.
+func Open(spec, homePath string) (*DB, error) {
+	switch {
+	case spec == "":
+		return New(NewFileBackend(filepath.Join(homePath, DEFAULT_DB_FILE))), nil
+	case spec == OFF:
+		return nil, nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		if _, err := url.Parse(spec); err != nil {
+			return nil, fmt.Errorf("invalid checksum database url '%s': %w", spec, err)
+		}
+		return New(NewHTTPBackend(spec)), nil
+	case filepath.IsAbs(spec):
+		return New(NewFileBackend(spec)), nil
+	default:
+		return nil, fmt.Errorf("invalid checksum database '%s': expected 'off', an http(s) url or an absolute path", spec)
+	}
+}
+
+// IsRemote returns true if the checksum database is served by a remote server.
+func (db *DB) IsRemote() bool {
+	_, ok := db.backend.(*HTTPBackend)
+	return ok
+}
+
+// Lookup returns the checksum recorded for the module version,
+// the private modules matching '$KPM_NO_SUMDB' are never recorded.
+func (db *DB) Lookup(module, version string) (string, bool, error) {
+	if env.SkipSumDB(module) {
+		return "", false, nil
+	}
+	return db.backend.Lookup(module, version)
+}
+
+// Verify verifies the checksum of the module version against the record,
+// and records it if the module version is used for the first time.
+// It returns a 'ChecksumMismatch' error if another checksum is recorded.
+// The private modules matching '$KPM_NO_SUMDB' are not verified.
+func (db *DB) Verify(module, version, sum string) error {
+	if env.SkipSumDB(module) {
+		return nil
+	}
+	recorded, ok, err := db.backend.Lookup(module, version)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if recorded != sum {
+			return &kpmerrors.ChecksumMismatch{Name: module + "@" + version, Expected: recorded, Actual: sum}
+		}
+		return nil
+	}
+	return db.backend.Record(module, version, sum)
+}
+
+// ModuleVersion returns the module path and the version recording the module of the remote source in the checksum database.
+// The OCI sources are recorded by the digests, or the tags if the digests are unknown, the registry and the repo are required.
+// The git sources are recorded by the commits or the tags, the sources by the branches only are not recorded.
+// It returns false if the source is not recorded.
+func ModuleVersion(source downloader.Source) (string, string, bool) {
+	var module, version, subPkg string
+	switch {
+	case source.Oci != nil:
+		if source.Oci.Reg == "" || source.Oci.Repo == "" {
+			return "", "", false
+		}
+		module = utils.JoinPath(source.Oci.Reg, source.Oci.Repo)
+		version = source.Oci.Digest
+		if version == "" {
+			version = source.Oci.Tag
+		}
+	case source.Git != nil:
+		module = gitModulePath(source.Git.Url)
+		version = source.Git.Commit
+		if version == "" {
+			version = source.Git.Tag
+		}
+		subPkg = source.Git.Package
+	default:
+		return "", "", false
+	}
+	if module == "" || version == "" {
+		return "", "", false
+	}
+
+	if !source.ModSpec.IsNil() && source.ModSpec.Name != "" {
+		subPkg = source.ModSpec.Name
+	}
+	if subPkg != "" {
+		module = module + "#" + subPkg
+	}
+	return module, version, true
+}
+
+// gitModulePath returns the module path of the git url without the scheme, the user and the '.git' suffix,
+// e.g. 'github.com/kcl-lang/konfig' for 'https://github.com/kcl-lang/konfig.git' and 'git@github.com:kcl-lang/konfig.git'.
+func gitModulePath(gitUrl string) string {
+	modulePath := gitUrl
+	if u, err := url.Parse(gitUrl); err == nil && u.Host != "" {
+		modulePath = u.Host + u.Path
+	} else if at := strings.Index(gitUrl, "@"); at >= 0 && strings.Contains(gitUrl[at:], ":") {
+		// The scp-like syntax 'user@host:path'.
+		modulePath = strings.Replace(gitUrl[at+1:], ":", "/", 1)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(modulePath, "/"), ".git")
+}
+
+// DepSum returns the checksum of the dependency recorded in the checksum database,
+// i.e. the checksum in kcl.mod.lock or the checksum of the downloaded module if it is not locked with a checksum.
+// It returns false if the checksum is unknown.
+func DepSum(dep pkg.Dependency) (string, bool, error) {
+	if dep.Sum != "" {
+		return dep.Sum, true, nil
+	}
+	if dep.LocalFullPath == "" || !utils.DirExists(dep.LocalFullPath) {
+		return "", false, nil
+	}
+	sum, err := utils.HashDir(dep.LocalFullPath)
+	if err != nil {
+		return "", false, err
+	}
+	return sum, true, nil
+}
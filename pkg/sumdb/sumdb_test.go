@@ -0,0 +1,241 @@
+package sumdb
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/env"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// testBackend tests the records of the backend are append-only.
+func testBackend(t *testing.T, backend Backend) {
+	_, ok, err := backend.Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, backend.Record("github.com/kcl-lang/konfig", "v0.4.0", "sum-a"))
+	assert.NoError(t, backend.Record("ghcr.io/kcl-lang/k8s", "sha256:3f1b", "sum-b"))
+	assert.NoError(t, backend.Record("github.com/kcl-lang/modules#helloworld", "main@2", "sum-c"))
+	// Recording the same checksum again is a no-op.
+	assert.NoError(t, backend.Record("github.com/kcl-lang/konfig", "v0.4.0", "sum-a"))
+
+	sum, ok, err := backend.Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "sum-a", sum)
+	sum, ok, err = backend.Lookup("ghcr.io/kcl-lang/k8s", "sha256:3f1b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "sum-b", sum)
+	sum, ok, err = backend.Lookup("github.com/kcl-lang/modules#helloworld", "main@2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "sum-c", sum)
+
+	// The recorded checksum is never replaced.
+	err = backend.Record("github.com/kcl-lang/konfig", "v0.4.0", "sum-changed")
+	assert.True(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	var mismatch *kpmerrors.ChecksumMismatch
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, "sum-a", mismatch.Expected)
+	assert.Equal(t, "sum-changed", mismatch.Actual)
+	sum, _, err = backend.Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "sum-a", sum)
+
+	// The records not stored in a line are refused.
+	assert.Error(t, backend.Record("github.com/kcl-lang/konfig", "v0.5.0", "sum with spaces"))
+	assert.Error(t, backend.Record("", "v0.5.0", "sum"))
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sumdb", "kcl.sum")
+	testBackend(t, NewFileBackend(path))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/kcl-lang/konfig v0.4.0 sum-a\n"+
+		"ghcr.io/kcl-lang/k8s sha256:3f1b sum-b\n"+
+		"github.com/kcl-lang/modules#helloworld main@2 sum-c\n", string(content))
+
+	// The records are loaded by another backend of the same file, and the malformed lines are ignored.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = f.WriteString("ghcr.io/kcl-lang/truncated\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	backend := NewFileBackend(path)
+	sum, ok, err := backend.Lookup("ghcr.io/kcl-lang/k8s", "sha256:3f1b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "sum-b", sum)
+
+	// The file is indexed once, the lookups do not scan the file again.
+	assert.NoError(t, os.Remove(path))
+	sum, ok, err = backend.Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "sum-a", sum)
+}
+
+func TestHTTPBackend(t *testing.T) {
+	server := httptest.NewServer(Handler(NewFileBackend(filepath.Join(t.TempDir(), "kcl.sum"))))
+	defer server.Close()
+
+	testBackend(t, NewHTTPBackend(server.URL+"/"))
+
+	// The remote checksum database not available is an error.
+	server.Close()
+	_, _, err := NewHTTPBackend(server.URL).Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.Error(t, err)
+}
+
+func TestDBVerify(t *testing.T) {
+	defer os.Unsetenv(env.KPM_NO_SUMDB)
+	db := New(NewFileBackend(filepath.Join(t.TempDir(), "kcl.sum")))
+
+	// The checksum is recorded on the first use and verified on the later uses.
+	assert.NoError(t, db.Verify("github.com/kcl-lang/konfig", "v0.4.0", "sum-a"))
+	assert.NoError(t, db.Verify("github.com/kcl-lang/konfig", "v0.4.0", "sum-a"))
+	err := db.Verify("github.com/kcl-lang/konfig", "v0.4.0", "sum-b")
+	assert.True(t, errors.Is(err, kpmerrors.CheckSumMismatchError))
+	assert.Contains(t, err.Error(), "github.com/kcl-lang/konfig@v0.4.0")
+
+	// The private modules are neither verified nor recorded.
+	os.Setenv(env.KPM_NO_SUMDB, "github.com/kcl-lang")
+	assert.NoError(t, db.Verify("github.com/kcl-lang/konfig", "v0.4.0", "sum-b"))
+	assert.NoError(t, db.Verify("github.com/kcl-lang/private", "v0.1.0", "sum-c"))
+	_, ok, err := db.Lookup("github.com/kcl-lang/konfig", "v0.4.0")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	os.Unsetenv(env.KPM_NO_SUMDB)
+	_, ok, err = db.Lookup("github.com/kcl-lang/private", "v0.1.0")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestOpen(t *testing.T) {
+	home := t.TempDir()
+	db, err := Open("", home)
+	assert.NoError(t, err)
+	assert.False(t, db.IsRemote())
+	assert.NoError(t, db.Verify("github.com/kcl-lang/konfig", "v0.4.0", "sum-a"))
+	assert.FileExists(t, filepath.Join(home, DEFAULT_DB_FILE))
+
+	db, err = Open(OFF, home)
+	assert.NoError(t, err)
+	assert.Nil(t, db)
+
+	db, err = Open("https://sum.example.com", home)
+	assert.NoError(t, err)
+	assert.True(t, db.IsRemote())
+
+	db, err = Open(filepath.Join(home, "other.sum"), home)
+	assert.NoError(t, err)
+	assert.False(t, db.IsRemote())
+
+	_, err = Open("relative/kcl.sum", home)
+	assert.Error(t, err)
+}
+
+func TestModuleVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  downloader.Source
+		module  string
+		version string
+		ok      bool
+	}{
+		{
+			name:    "git tag",
+			source:  downloader.Source{Git: &downloader.Git{Url: "https://github.com/kcl-lang/konfig.git", Tag: "v0.4.0"}},
+			module:  "github.com/kcl-lang/konfig",
+			version: "v0.4.0",
+			ok:      true,
+		},
+		{
+			name:    "git commit",
+			source:  downloader.Source{Git: &downloader.Git{Url: "git@github.com:kcl-lang/konfig.git", Tag: "v0.4.0", Commit: "9b5b8a4f"}},
+			module:  "github.com/kcl-lang/konfig",
+			version: "9b5b8a4f",
+			ok:      true,
+		},
+		{
+			name: "git package",
+			source: downloader.Source{
+				ModSpec: &downloader.ModSpec{Name: "helloworld", Version: "0.1.4"},
+				Git:     &downloader.Git{Url: "https://github.com/kcl-lang/modules", Commit: "ee03122"},
+			},
+			module:  "github.com/kcl-lang/modules#helloworld",
+			version: "ee03122",
+			ok:      true,
+		},
+		{
+			name:   "git branch",
+			source: downloader.Source{Git: &downloader.Git{Url: "https://github.com/kcl-lang/konfig.git", Branch: "main"}},
+		},
+		{
+			name:    "oci digest",
+			source:  downloader.Source{Oci: &downloader.Oci{Reg: "ghcr.io", Repo: "kcl-lang/k8s", Tag: "1.28", Digest: "sha256:3f1b"}},
+			module:  "ghcr.io/kcl-lang/k8s",
+			version: "sha256:3f1b",
+			ok:      true,
+		},
+		{
+			name:    "oci tag",
+			source:  downloader.Source{Oci: &downloader.Oci{Reg: "ghcr.io", Repo: "kcl-lang/k8s", Tag: "1.28"}},
+			module:  "ghcr.io/kcl-lang/k8s",
+			version: "1.28",
+			ok:      true,
+		},
+		{
+			name:   "oci default registry",
+			source: downloader.Source{Oci: &downloader.Oci{Tag: "1.28"}},
+		},
+		{
+			name:   "local",
+			source: downloader.Source{Local: &downloader.Local{Path: "../helloworld"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, version, ok := ModuleVersion(tt.source)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.module, module)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestDepSum(t *testing.T) {
+	// The checksum in kcl.mod.lock is preferred.
+	sum, ok, err := DepSum(pkg.Dependency{Sum: "locked", LocalFullPath: t.TempDir()})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "locked", sum)
+
+	// The checksum of the downloaded module if it is not locked with a checksum.
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.k"), []byte("a = 1"), 0644))
+	expected, err := utils.HashDir(dir)
+	assert.NoError(t, err)
+	sum, ok, err = DepSum(pkg.Dependency{LocalFullPath: dir})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, expected, sum)
+
+	_, ok, err = DepSum(pkg.Dependency{LocalFullPath: filepath.Join(dir, "not_exist")})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
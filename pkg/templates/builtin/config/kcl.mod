@@ -0,0 +1,7 @@
+[package]
+name = "{{ name }}"
+edition = "{{ edition }}"
+version = "{{ version }}"
+
+[profile]
+entries = ["base/base.k", "dev/main.k"]
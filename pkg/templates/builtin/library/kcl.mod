@@ -0,0 +1,4 @@
+[package]
+name = "{{ name }}"
+edition = "{{ edition }}"
+version = "{{ version }}"
@@ -0,0 +1,12 @@
+[package]
+name = "{{ name }}"
+edition = "{{ edition }}"
+version = "{{ version }}"
+
+[profile]
+entries = ["base/base.k", "dev/main.k"]
+arguments = ["env=dev"]
+
+[profile.prod]
+entries = ["base/base.k", "prod/main.k"]
+arguments = ["env=prod"]
@@ -0,0 +1,131 @@
+// Package templates implements the templates to initialize the kcl modules.
+//
+// A template is a directory of files, e.g. a built-in template, a local directory or a kcl package
+// downloaded from the OCI registries or the git repositories. The files are copied into the new module
+// with the placeholders in the contents and the paths substituted:
+//
+//	{{ name }}     the name of the new module
+//	{{ version }}  the version of the new module
+//	{{ edition }}  the kcl edition of the new module
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed builtin
+var builtinFS embed.FS
+
+const builtinDir = "builtin"
+
+// Values are the values of the placeholders in the templates.
+type Values struct {
+	Name    string
+	Version string
+	Edition string
+}
+
+// replacer returns the replacer of the placeholders, with or without the spaces in the braces.
+func (v Values) replacer() *strings.Replacer {
+	var pairs []string
+	for key, value := range map[string]string{"name": v.Name, "version": v.Version, "edition": v.Edition} {
+		pairs = append(pairs, "{{ "+key+" }}", value, "{{"+key+"}}", value)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Builtins returns the names of the built-in templates in the alphabetical order.
+func Builtins() []string {
+	entries, err := builtinFS.ReadDir(builtinDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns the files of the built-in template, it returns false if there is no built-in template of the name.
+func Builtin(name string) (fs.FS, bool) {
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, false
+	}
+	sub, err := fs.Sub(builtinFS, path.Join(builtinDir, name))
+	if err != nil {
+		return nil, false
+	}
+	if _, err := fs.Stat(sub, "."); err != nil {
+		return nil, false
+	}
+	return sub, true
+}
+
+// Render copies the files of the template into the directory 'dst' with the placeholders substituted.
+// The '.git' directories are not copied, and the existing files in 'dst' are not overwritten.
+// It returns the paths of the files created and the paths of the files skipped for they exist.
+func Render(template fs.FS, dst string, values Values) ([]string, []string, error) {
+	replacer := values.replacer()
+	var created, existing []string
+
+	err := fs.WalkDir(template, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(replacer.Replace(p)))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if _, err := os.Stat(target); err == nil {
+			existing = append(existing, target)
+			return nil
+		}
+
+		content, err := fs.ReadFile(template, p)
+		if err != nil {
+			return err
+		}
+		// The binary files are copied as they are.
+		if bytes.IndexByte(content, 0) < 0 {
+			content = []byte(replacer.Replace(string(content)))
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		perm := os.FileMode(0644)
+		if info.Mode().Perm()&0111 != 0 {
+			perm = 0755
+		}
+		if err := os.WriteFile(target, content, perm); err != nil {
+			return fmt.Errorf("failed to create '%s': %w", target, err)
+		}
+		created = append(created, target)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, existing, nil
+}
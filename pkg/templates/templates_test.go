@@ -0,0 +1,73 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltins(t *testing.T) {
+	assert.Equal(t, []string{"config", "k8s-app", "library", "profiles"}, Builtins())
+
+	for _, name := range Builtins() {
+		template, ok := Builtin(name)
+		assert.True(t, ok)
+		_, err := template.Open("kcl.mod")
+		assert.NoError(t, err, name)
+	}
+
+	_, ok := Builtin("not_exist")
+	assert.False(t, ok)
+	_, ok = Builtin("../builtin")
+	assert.False(t, ok)
+	_, ok = Builtin("")
+	assert.False(t, ok)
+}
+
+func TestRender(t *testing.T) {
+	template := fstest.MapFS{
+		"kcl.mod":           {Data: []byte("[package]\nname = \"{{ name }}\"\nedition = \"{{edition}}\"\nversion = \"{{ version }}\"\n")},
+		"{{ name }}/main.k": {Data: []byte("a = \"{{ name }}\"\n")},
+		"run.sh":            {Data: []byte("kcl run\n"), Mode: 0755},
+		"logo.png":          {Data: []byte("\x89PNG\x00{{ name }}")},
+		"main.k":            {Data: []byte("b = 1\n")},
+		".git/HEAD":         {Data: []byte("ref: refs/heads/main\n")},
+	}
+	dst := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dst, "main.k"), []byte("b = 2\n"), 0644))
+
+	created, existing, err := Render(template, dst, Values{Name: "demo", Version: "0.1.0", Edition: "v0.11.0"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dst, "kcl.mod"),
+		filepath.Join(dst, "demo", "main.k"),
+		filepath.Join(dst, "run.sh"),
+		filepath.Join(dst, "logo.png"),
+	}, created)
+	assert.Equal(t, []string{filepath.Join(dst, "main.k")}, existing)
+
+	content, err := os.ReadFile(filepath.Join(dst, "kcl.mod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[package]\nname = \"demo\"\nedition = \"v0.11.0\"\nversion = \"0.1.0\"\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dst, "demo", "main.k"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a = \"demo\"\n", string(content))
+
+	// The binary files are not substituted.
+	content, err = os.ReadFile(filepath.Join(dst, "logo.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\x89PNG\x00{{ name }}", string(content))
+
+	// The existing files are not overwritten and the '.git' directories are not copied.
+	content, err = os.ReadFile(filepath.Join(dst, "main.k"))
+	assert.NoError(t, err)
+	assert.Equal(t, "b = 2\n", string(content))
+	assert.NoDirExists(t, filepath.Join(dst, ".git"))
+
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0100)
+}
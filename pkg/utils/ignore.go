@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// defaultPackageIgnores is the patterns of the files never packaged unless they are re-included by '!<pattern>'.
+var defaultPackageIgnores = []string{".git", "*.tar"}
+
+// PackageFile is a file or a directory to be packaged.
+type PackageFile struct {
+	// Path is the path of the file on the disk.
+	Path string
+	// Name is the slash-separated path of the file relative to the package root.
+	Name string
+	Info os.FileInfo
+}
+
+// LoadKclIgnore loads the patterns in the '.kclignore' in 'srcDir',
+// the blank lines and the comments starting with '#' are skipped.
+// It returns no patterns if '.kclignore' does not exist.
+func LoadKclIgnore(srcDir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(srcDir, constants.KCL_IGNORE))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load '%s': %w", filepath.Join(srcDir, constants.KCL_IGNORE), err)
+	}
+	return patterns, nil
+}
+
+// newPackageMatcher creates the gitignore matcher for the patterns relative to 'srcDir'.
+// For compatibility, the patterns prefixed by 'srcDir' are anchored to the package root.
+func newPackageMatcher(srcDir string, patterns []string) gitignore.Matcher {
+	prefix := filepath.ToSlash(srcDir) + "/"
+	var ps []gitignore.Pattern
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		p = filepath.ToSlash(p)
+		if strings.HasPrefix(p, prefix) {
+			p = "/" + strings.TrimPrefix(p, prefix)
+		}
+		if negated {
+			p = "!" + p
+		}
+		ps = append(ps, gitignore.ParsePattern(p, nil))
+	}
+	return gitignore.NewMatcher(ps)
+}
+
+// ListPackageFiles returns the files and directories in 'srcDir' to be packaged into 'tarPath',
+// sorted by their names.
+//
+// The 'include', 'exclude' patterns and the patterns in '.kclignore' follow the gitignore semantics,
+// e.g. '**' matches any directories, '!<pattern>' negates the pattern, and the pattern starting with '/'
+// is anchored to the package root.
+// A file is packaged if it matches the include patterns (all files are included if there are no include patterns)
+// and it is not ignored by the default ignores, '.kclignore' and the exclude patterns, in increasing priority.
+// The files in the ignored directories are never packaged.
+func ListPackageFiles(srcDir string, tarPath string, include []string, exclude []string) ([]PackageFile, error) {
+	kclIgnores, err := LoadKclIgnore(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	ignores := append(append(append([]string{}, defaultPackageIgnores...), kclIgnores...), exclude...)
+	ignoreMatcher := newPackageMatcher(srcDir, ignores)
+	includeMatcher := newPackageMatcher(srcDir, include)
+
+	// In case the tarPath is within the current working directory, exclude it from the tar itself.
+	targetDir := filepath.Join(srcDir, filepath.Dir(tarPath))
+	// Only ignore the target directory if it is NOT the root source directory.
+	if filepath.Clean(targetDir) == filepath.Clean(srcDir) {
+		targetDir = ""
+	}
+
+	var files []PackageFile
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Ignore the current directory root "."
+		if path == srcDir {
+			return nil
+		}
+
+		if targetDir != "" && filepath.Clean(path) == filepath.Clean(targetDir) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		parts := strings.Split(name, "/")
+
+		if ignoreMatcher.Match(parts, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// If the include list is empty, all files are included by default.
+		if len(include) != 0 && !includeMatcher.Match(parts, info.IsDir()) {
+			return nil
+		}
+
+		files = append(files, PackageFile{Path: path, Name: name, Info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The files are sorted by their names in the tar, which are the same on all platforms.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
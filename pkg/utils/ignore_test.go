@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPackageFiles(t *testing.T) {
+	srcDir := t.TempDir()
+	for name, content := range map[string]string{
+		"kcl.mod":          "",
+		"main.k":           "",
+		"README.md":        "",
+		"old.tar":          "",
+		".git/config":      "",
+		"build/out.k":      "",
+		"docs/index.k":     "",
+		"sub/a.k":          "",
+		"sub/build/out.k":  "",
+		"sub/gen/b.k":      "",
+		"sub/gen/keep.k":   "",
+		"sub/deep/gen/c.k": "",
+		".kclignore":       "# generated files\n/build\ndocs/\n**/gen/*\n!**/gen/keep.k\n",
+	} {
+		path := filepath.Join(srcDir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	listFiles := func(include, exclude []string) []string {
+		files, err := ListPackageFiles(srcDir, filepath.Join(srcDir, "pkg.tar"), include, exclude)
+		assert.NoError(t, err)
+		var names []string
+		for _, file := range files {
+			if !file.Info.IsDir() {
+				names = append(names, file.Name)
+			}
+		}
+		return names
+	}
+
+	// The anchored, directory, '**' and negated patterns in '.kclignore' are applied.
+	assert.Equal(t, []string{
+		".kclignore",
+		"README.md",
+		"kcl.mod",
+		"main.k",
+		"sub/a.k",
+		"sub/build/out.k",
+		"sub/gen/keep.k",
+	}, listFiles(nil, []string{"*.md", "!README.md", "old.tar"}))
+
+	// The exclude patterns have the highest priority and the patterns prefixed by the package root are anchored.
+	assert.Equal(t, []string{
+		".kclignore",
+		"kcl.mod",
+		"main.k",
+		"sub/gen/keep.k",
+	}, listFiles(nil, []string{"*.md", filepath.Join(srcDir, "sub", "*.k"), "sub/build"}))
+
+	// Only the files matching the include patterns are packaged.
+	assert.Equal(t, []string{
+		"kcl.mod",
+		"sub/a.k",
+		"sub/build/out.k",
+		"sub/gen/keep.k",
+	}, listFiles([]string{"sub/**/*.k", "*.mod"}, nil))
+
+	// The default ignores can be re-included.
+	assert.Contains(t, listFiles(nil, []string{"!old.tar"}), "old.tar")
+	assert.NotContains(t, listFiles(nil, nil), "old.tar")
+}
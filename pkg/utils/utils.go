@@ -16,8 +16,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dchest/siphash"
 
@@ -35,6 +37,10 @@ import (
 
 // HashDir computes the checksum of a directory by concatenating all files and
 // hashing them by sha256.
+//
+// The checksums are recorded in kcl.mod.lock and by the registries, so the files hashed must never change:
+// the files whose paths contain '.git' or '.tar' are skipped as they always were,
+// the gitignore matching of the packages, e.g. '.kclignore', is not applied here.
 func HashDir(dir string) (string, error) {
 	hasher := sha256.New()
 
@@ -49,7 +55,7 @@ func HashDir(dir string) (string, error) {
 
 		// files in the ".git "directory will cause the same repository, cloned at different times,
 		// has different checksum.
-		for _, ignore := range defaultIgnores {
+		for _, ignore := range hashIgnores {
 			if strings.Contains(path, ignore) {
 				return nil
 			}
@@ -75,6 +81,22 @@ func HashDir(dir string) (string, error) {
 	return base64.StdEncoding.EncodeToString(hasher.Sum(nil)), nil
 }
 
+// FileDigest computes the digest of a file in the format 'sha256:<hex>'.
+func FileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 // StoreToFile will store 'data' into toml file under 'filePath'.
 func StoreToFile(filePath string, dataStr string) error {
 	err := os.WriteFile(filePath, []byte(dataStr), 0644)
@@ -85,6 +107,76 @@ func StoreToFile(filePath string, dataStr string) error {
 	return nil
 }
 
+// FileData is the data to store into the file under 'Path'.
+type FileData struct {
+	Path string
+	Data string
+}
+
+// StoreToFilesAtomically stores the data into the files, e.g. kcl.mod.lock and kcl.mod.
+// Each data is written into a temporary file in the directory of its file first, and the temporary files
+// are renamed to the files in order only after all of them are written, so the files are never left partially written
+// and none of them are changed if writing any of them fails.
+// The files are not replaced together: if renaming a file fails, the files before it in order are already replaced.
+// The mode of the existing files is kept and the symbolic links to the files are followed.
+func StoreToFilesAtomically(files ...FileData) error {
+	targets := make([]string, len(files))
+	tmpPaths := make([]string, 0, len(files))
+	defer func() {
+		for _, tmpPath := range tmpPaths {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	for i, file := range files {
+		target, tmpPath, err := writeTempFile(file.Path, []byte(file.Data), 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write file '%s': %w", file.Path, err)
+		}
+		targets[i] = target
+		tmpPaths = append(tmpPaths, tmpPath)
+	}
+
+	for i, tmpPath := range tmpPaths {
+		if err := os.Rename(tmpPath, targets[i]); err != nil {
+			return fmt.Errorf("failed to write file '%s': %w", files[i].Path, err)
+		}
+	}
+	return nil
+}
+
+// writeTempFile writes the data into a temporary file in the directory of the file to replace it,
+// it returns the file to replace, i.e. the target of the symbolic link, and the path of the temporary file.
+func writeTempFile(filePath string, data []byte, perm os.FileMode) (string, string, error) {
+	if target, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = target
+	}
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-")
+	if err != nil {
+		return "", "", err
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err = tmpFile.Write(data); err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", "", err
+	}
+	return filePath, tmpPath, nil
+}
+
 // ParseRepoNameFromGitUrl get the repo name from git url,
 // the repo name in 'https://github.com/xxx/kcl1.git' is 'kcl1'.
 func ParseRepoNameFromGitUrl(gitUrl string) string {
@@ -120,10 +212,36 @@ func Exists(path string) (bool, error) {
 	return true, nil
 }
 
-// todo: Consider using the OCI tarball as the standard tar format.
-var defaultIgnores = []string{".git", ".tar"}
+// hashIgnores is the substrings of the paths of the files not hashed by 'HashDir'.
+var hashIgnores = []string{".git", ".tar"}
+
+// SOURCE_DATE_EPOCH is the env to specify the timestamp of the files in the package archives,
+// refer to https://reproducible-builds.org/specs/source-date-epoch/.
+const SOURCE_DATE_EPOCH = "SOURCE_DATE_EPOCH"
 
+// SourceDateEpoch returns the timestamp specified by $SOURCE_DATE_EPOCH,
+// or the unix epoch if $SOURCE_DATE_EPOCH is not set.
+func SourceDateEpoch() (time.Time, error) {
+	epoch := os.Getenv(SOURCE_DATE_EPOCH)
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid $%s '%s', expected the seconds since the unix epoch", SOURCE_DATE_EPOCH, epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// TarDir will tar the files in 'srcDir' listed by 'ListPackageFiles' into 'tarPath'.
+// The tar is reproducible: the entries are sorted by their paths, and the mode, owner and mtime
+// of the entries are normalized, the mtime is $SOURCE_DATE_EPOCH or the unix epoch.
 func TarDir(srcDir string, tarPath string, include []string, exclude []string) error {
+	modTime, err := SourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
 	fw, err := os.Create(tarPath)
 	if err != nil {
 		log.Fatal(err)
@@ -133,89 +251,59 @@ func TarDir(srcDir string, tarPath string, include []string, exclude []string) e
 	tw := tar.NewWriter(fw)
 	defer tw.Close()
 
-	// In case the tarPath is within the current working directory, exclude it from the tar itself.
-	targetDir := filepath.Join(srcDir, filepath.Dir(tarPath))
-	ignores := defaultIgnores
-	// Only ignore the target directory if it is NOT the root source directory.
-	if filepath.Clean(targetDir) != filepath.Clean(srcDir) {
-		ignores = append(ignores, targetDir)
+	files, err := ListPackageFiles(srcDir, tarPath, include, exclude)
+	if err != nil {
+		return err
 	}
 
-	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
+	for _, file := range files {
+		if err := writeTarEntry(tw, file.Path, file.Name, file.Info, modTime); err != nil {
 			return err
 		}
+	}
 
-		// Ignore the current directory root "."
-		if path == srcDir {
-			return nil
-		}
-
-		for _, ignore := range ignores {
-			if strings.Contains(path, ignore) {
-				return nil
-			}
-		}
-
-		getNewPattern := func(ex string) string {
-			newPath := ex
-			prefix := srcDir + string(filepath.Separator)
-			if !strings.HasPrefix(ex, prefix) {
-				newPath = filepath.Join(srcDir, ex)
-			}
-			return newPath
-		}
-
-		for _, ex := range exclude {
-			if matched, _ := filepath.Match(getNewPattern(ex), path); matched {
-				return nil
-			}
-		}
-
-		// If the include list is empty, all files are included by default.
-		matchedInclude := len(include) == 0
-		for _, inc := range include {
-			if matched, _ := filepath.Match(getNewPattern(inc), path); matched {
-				matchedInclude = true
-				break
-			}
-		}
-
-		// As long as _one_ of the include patterns matches, the file will be included.
-		if !matchedInclude {
-			return nil
-		}
-
-		relPath, _ := filepath.Rel(srcDir, path)
-		relPath = filepath.ToSlash(relPath)
-
-		hdr, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
-		}
-		hdr.Name = relPath
-
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
-
-		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
-			return nil
-		}
-
-		fr, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer fr.Close()
+	return nil
+}
 
-		if _, err := io.Copy(tw, fr); err != nil {
-			return err
-		}
+// writeTarEntry writes the file in 'path' into the tar as 'name' with the normalized header.
+func writeTarEntry(tw *tar.Writer, path, name string, info os.FileInfo, modTime time.Time) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.ModTime = modTime
+	hdr.AccessTime = time.Time{}
+	hdr.ChangeTime = time.Time{}
+	hdr.Uid = 0
+	hdr.Gid = 0
+	hdr.Uname = ""
+	hdr.Gname = ""
+	// Only the executable bit of the files is kept.
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		hdr.Mode = 0777
+	case info.IsDir() || info.Mode()&0111 != 0:
+		hdr.Mode = 0755
+	default:
+		hdr.Mode = 0644
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
 
+	if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
 		return nil
-	})
+	}
+
+	fr, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
 
+	_, err = io.Copy(tw, fr)
 	return err
 }
 
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,6 +73,29 @@ func TestHashDir(t *testing.T) {
 	assert.Equal(t, res, "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=")
 }
 
+func TestHashDirIgnores(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "k8s")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.k"), []byte("a = 1"), 0644))
+	sum, err := HashDir(dir)
+	assert.NoError(t, err)
+
+	// The files whose paths contain '.git' or '.tar' are not hashed,
+	// which keeps the checksums already recorded in kcl.mod.lock.
+	for _, name := range []string{filepath.Join(".git", "HEAD"), "k8s.tar", filepath.Join(".github", "ci.yml"), "app.targets.k", ".gitignore"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("b = 1"), 0644))
+		res, err := HashDir(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, sum, res, name)
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.k"), []byte("b = 1"), 0644))
+	res, err := HashDir(dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum, res)
+}
+
 func TestGetUsernamePasswordFromStdin(t *testing.T) {
 	runWithStdin(t, "secret\n", func() {
 		username, password, err := GetUsernamePassword("test-user", "", true)
@@ -166,6 +190,66 @@ func TestTarDir(t *testing.T) {
 	os.Remove(tarPath)
 }
 
+func TestTarDirReproducible(t *testing.T) {
+	srcDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(srcDir, "sub"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "sub", "b.k"), []byte("b = 1\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "a.k"), []byte("a = 1\n"), 0664))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "run.sh"), []byte("echo\n"), 0775))
+
+	tarDir := func() (string, []*tar.Header) {
+		tarPath := filepath.Join(t.TempDir(), "test.tar")
+		assert.NoError(t, TarDir(srcDir, tarPath, []string{}, []string{}))
+		digest, err := FileDigest(tarPath)
+		assert.NoError(t, err)
+
+		file, err := os.Open(tarPath)
+		assert.NoError(t, err)
+		defer file.Close()
+		var headers []*tar.Header
+		reader := tar.NewReader(file)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			headers = append(headers, header)
+		}
+		return digest, headers
+	}
+
+	digest, headers := tarDir()
+	var names []string
+	for _, header := range headers {
+		names = append(names, header.Name)
+		assert.Equal(t, 0, header.Uid)
+		assert.Equal(t, 0, header.Gid)
+		assert.Equal(t, int64(0), header.ModTime.Unix())
+	}
+	assert.Equal(t, []string{"a.k", "run.sh", "sub", "sub/b.k"}, names)
+	assert.Equal(t, int64(0644), headers[0].Mode)
+	assert.Equal(t, int64(0755), headers[1].Mode)
+	assert.Equal(t, int64(0755), headers[2].Mode)
+	assert.Equal(t, int64(0644), headers[3].Mode)
+
+	// The digest does not depend on the mtime of the files.
+	mtime := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(srcDir, "a.k"), mtime, mtime))
+	newDigest, _ := tarDir()
+	assert.Equal(t, digest, newDigest)
+
+	// The mtime of the files is $SOURCE_DATE_EPOCH.
+	t.Setenv(SOURCE_DATE_EPOCH, "1700000000")
+	epochDigest, headers := tarDir()
+	assert.NotEqual(t, digest, epochDigest)
+	assert.Equal(t, int64(1700000000), headers[0].ModTime.Unix())
+
+	t.Setenv(SOURCE_DATE_EPOCH, "yesterday")
+	err := TarDir(srcDir, filepath.Join(t.TempDir(), "test.tar"), []string{}, []string{})
+	assert.ErrorContains(t, err, "invalid $SOURCE_DATE_EPOCH 'yesterday'")
+}
+
 func runWithStdin(t *testing.T, input string, fn func()) {
 	t.Helper()
 
@@ -420,3 +504,43 @@ func TestShortHash(t *testing.T) {
 	}
 	assert.Equal(t, hash, "9ebd0ad063dba405")
 }
+
+func TestStoreToFilesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	modPath := filepath.Join(dir, "kcl.mod")
+	assert.Equal(t, os.WriteFile(modPath, []byte("before"), 0600), nil)
+	linkPath := filepath.Join(dir, "kcl.mod.link")
+	assert.Equal(t, os.Symlink(modPath, linkPath), nil)
+	lockPath := filepath.Join(dir, "kcl.mod.lock")
+
+	assert.Equal(t, StoreToFilesAtomically(FileData{Path: lockPath, Data: "lock"}, FileData{Path: linkPath, Data: "after"}), nil)
+	content, err := os.ReadFile(modPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(content), "after")
+	content, err = os.ReadFile(lockPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(content), "lock")
+	info, err := os.Stat(modPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+	info, err = os.Stat(lockPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0644))
+	// The symbolic link is kept and no temporary files are left.
+	info, err = os.Lstat(linkPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, info.Mode()&os.ModeSymlink != 0, true)
+	entries, err := os.ReadDir(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(entries), 3)
+
+	// None of the files are changed if writing any of them fails.
+	err = StoreToFilesAtomically(FileData{Path: lockPath, Data: "new lock"}, FileData{Path: filepath.Join(dir, "not_exist", "kcl.mod"), Data: "new"})
+	assert.Equal(t, err != nil, true)
+	content, err = os.ReadFile(lockPath)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(content), "lock")
+	entries, err = os.ReadDir(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(entries), 3)
+}
@@ -8,7 +8,10 @@ import (
 	"path/filepath"
 
 	"github.com/google/uuid"
+	"kcl-lang.io/kpm/pkg/cache"
+	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
+	kpmerrors "kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/features"
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
@@ -103,7 +106,22 @@ type RemoteVisitor struct {
 	VisitedSpace          string
 	Downloader            downloader.Downloader
 	InsecureSkipTLSverify bool
-	Offline               bool
+	// Offline is the flag to visit the package from the cache only,
+	// the package not found in the cache is an error unless 'SkipMissing' is set.
+	Offline bool
+	// SkipMissing is the flag to skip the package not found in the cache in offline mode without visiting it.
+	SkipMissing bool
+	// Store is the content-addressed store to restore the package from before downloading it,
+	// the package downloaded is written into it.
+	Store *cache.Store
+	// Sum is the checksum of the package in kcl.mod.lock, the package is restored from the Store by it.
+	Sum string
+	// Digest is the manifest digest of the OCI package in kcl.mod.lock,
+	// the package is refused if its tag resolves to another digest when it is downloaded.
+	Digest string
+	// CheckSource checks the source before the package is restored or downloaded, e.g. verifies its signature,
+	// the package is not visited if it fails. The source is checked in offline mode if the flag is set.
+	CheckSource func(source *downloader.Source, offline bool) error
 }
 
 // NewRemoteVisitor creates a new RemoteVisitor.
@@ -135,6 +153,11 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		s.Oci.Reg = rv.Settings.DefaultOciRegistry()
 	}
 
+	if s.Oci != nil && s.Oci.Digest == "" && rv.Digest != "" {
+		s.Oci.Digest = rv.Digest
+		s.Oci.DigestResolved = true
+	}
+
 	var cacheFullPath string
 	var modFullPath string
 
@@ -170,6 +193,13 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 	// For Oci, the latest tag
 	// For Git, the main branch
 	if (s.Oci != nil && s.Oci.NoRef()) || (s.Git != nil && s.Git.NoRef()) {
+		// The latest version is unknown in offline mode.
+		if rv.Offline {
+			if rv.SkipMissing {
+				return nil
+			}
+			return downloader.NewNotFoundOfflineError(*s)
+		}
 		latest, err := rv.Downloader.LatestVersion(downloader.NewDownloadOptions(
 			downloader.WithSource(*s),
 			downloader.WithLogWriter(rv.LogWriter),
@@ -197,6 +227,12 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		}
 	}
 
+	if rv.CheckSource != nil {
+		if err := rv.CheckSource(s, rv.Offline); err != nil {
+			return err
+		}
+	}
+
 	// Generate the local path for the remote package after the version is specified.
 	if ok, err := features.Enabled(features.SupportNewStorage); err == nil && !ok {
 		// update the local module path with the latest version.
@@ -240,6 +276,36 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		defer os.RemoveAll(tmpDir)
 	}
 
+	// Restore the locked package from the store if it is not in the local path, the download is skipped for it.
+	// The package already in the local path is not rehashed if the store holds the package with the checksum,
+	// which is verified when it is stored. Otherwise it is verified against the checksum by storing it,
+	// and removed to be downloaded again if it does not match.
+	if rv.Store != nil && rv.Sum != "" && len(rv.VisitedSpace) != 0 && s.ModSpec.IsNil() {
+		inLocalPath := utils.DirExists(filepath.Join(modFullPath, constants.KCL_MOD))
+		if rv.Store.Has(rv.Sum) {
+			if !inLocalPath {
+				_, err := rv.Store.Restore(rv.Sum, modFullPath)
+				if errors.Is(err, kpmerrors.CheckSumMismatchError) {
+					reporter.ReportEventTo(
+						reporter.NewEvent(reporter.CheckSumMismatch, fmt.Sprintf("%v, the corrupted package is removed from the store", err)),
+						rv.LogWriter,
+					)
+				}
+			}
+		} else if inLocalPath {
+			err := rv.Store.Put(rv.Sum, modFullPath)
+			if errors.Is(err, kpmerrors.CheckSumMismatchError) {
+				reporter.ReportEventTo(
+					reporter.NewEvent(reporter.CheckSumMismatch, fmt.Sprintf("the package in '%s' does not match the checksum '%s', downloading it again", modFullPath, rv.Sum)),
+					rv.LogWriter,
+				)
+				if err := os.RemoveAll(modFullPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	credStore, err = downloader.LoadCredentialFile(rv.Settings.CredentialsFile)
 	if err != nil {
 		return err
@@ -258,7 +324,7 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 	))
 
 	if err != nil {
-		if errors.Is(err, downloader.ErrNotFoundAndOffline) && rv.Offline {
+		if errors.Is(err, downloader.ErrNotFoundAndOffline) && rv.Offline && rv.SkipMissing {
 			return nil
 		}
 		return err
@@ -275,12 +341,16 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		pkg.WithSettings(rv.Settings),
 	)
 	if err != nil {
-		if rv.Offline {
+		if rv.Offline && rv.SkipMissing {
 			return nil
 		}
 		return err
 	}
 
+	if rv.Store != nil && s.ModSpec.IsNil() {
+		rv.store(modFullPath)
+	}
+
 	if !s.ModSpec.IsNil() {
 		if s.ModSpec.Version == "" {
 			s.ModSpec.Version = kclPkg.ModFile.Pkg.Version
@@ -295,6 +365,23 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 	return v(kclPkg)
 }
 
+// store writes the package downloaded into the local path into the store by the checksum 'Sum',
+// the package not locked is stored by its checksum if it is downloaded into a temporary directory,
+// e.g. the package pulled or run. The errors are ignored for the package is downloaded again without the store.
+func (rv *RemoteVisitor) store(modFullPath string) {
+	sum := rv.Sum
+	if sum == "" {
+		if len(rv.VisitedSpace) != 0 {
+			return
+		}
+		var err error
+		if sum, err = utils.HashDir(modFullPath); err != nil {
+			return
+		}
+	}
+	_ = rv.Store.Put(sum, modFullPath)
+}
+
 // ArchiveVisitor is the visitor for visiting a package which is a local tar/tgz path.
 type ArchiveVisitor struct {
 	*PkgVisitor
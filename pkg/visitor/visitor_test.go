@@ -6,10 +6,13 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/otiai10/copy"
 	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/cache"
 	"kcl-lang.io/kpm/pkg/downloader"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
 )
 
 const testDataDir = "test_data"
@@ -224,3 +227,45 @@ func TestVisitedPkgWithDefaultVersion(t *testing.T) {
 	assert.Equal(t, source.Oci.Tag, "0.1.4")
 	assert.NilError(t, err)
 }
+
+// dirDownloader downloads the packages by copying the directory.
+type dirDownloader struct {
+	dir string
+}
+
+func (d *dirDownloader) Download(opts *downloader.DownloadOptions) error {
+	return copy.Copy(d.dir, opts.LocalPath)
+}
+
+func (d *dirDownloader) LatestVersion(opts *downloader.DownloadOptions) (string, error) {
+	return "0.0.1", nil
+}
+
+func (d *dirDownloader) Versions(opts *downloader.DownloadOptions) ([]string, error) {
+	return []string{"0.0.1"}, nil
+}
+
+func TestVisitPkgRemoteIntoStore(t *testing.T) {
+	pkgDir := getTestDir("test_visit_dir")
+	sum, err := utils.HashDir(pkgDir)
+	assert.NilError(t, err)
+
+	// The package downloaded into a temporary directory, e.g. pulled, is stored by its checksum.
+	store := cache.NewStore(t.TempDir())
+	rVisitor := &RemoteVisitor{
+		PkgVisitor: &PkgVisitor{
+			Settings:  settings.GetSettings(),
+			LogWriter: &bytes.Buffer{},
+		},
+		Downloader: &dirDownloader{dir: pkgDir},
+		Store:      store,
+	}
+	source, err := downloader.NewSourceFromStr("oci://example.com/test/test_visit_dir?tag=0.0.1")
+	assert.NilError(t, err)
+	err = rVisitor.Visit(source, func(pkg *pkg.KclPkg) error {
+		assert.Equal(t, pkg.GetPkgName(), "test_visit_dir")
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, store.Has(sum))
+}